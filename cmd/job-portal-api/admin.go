@@ -0,0 +1,78 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"net/mail"
+	"os"
+	"strings"
+
+	"github.com/afthaab/job-portal/internal/models"
+	"github.com/afthaab/job-portal/internal/service"
+)
+
+// runCreateAdmin is the create-admin subcommand, it hands out the roles signup refuses
+func runCreateAdmin(args []string) error {
+	fs := flag.NewFlagSet("create-admin", flag.ContinueOnError)
+	username := fs.String("username", "", "username of the new user")
+	email := fs.String("email", "", "email of the new user")
+	password := fs.String("password", "", "password of the new user, read from stdin when empty so it stays out of the shell history")
+	role := fs.String("role", models.RoleAdmin, "role of the new user, admin or recruiter")
+	err := fs.Parse(args)
+	if err != nil {
+		return err
+	}
+	if *username == "" || *email == "" {
+		return errors.New("usage: job-portal-api create-admin -username <name> -email <email> [-role admin|recruiter] [-password <password>]")
+	}
+	_, err = mail.ParseAddress(*email)
+	if err != nil {
+		return fmt.Errorf("invalid email %q", *email)
+	}
+	if *role != models.RoleAdmin && *role != models.RoleRecruiter {
+		return fmt.Errorf("role must be admin or recruiter, candidates sign up through the api")
+	}
+	if *password == "" {
+		*password, err = readPassword()
+		if err != nil {
+			return err
+		}
+	}
+
+	cfg, repo, err := openRepository()
+	if err != nil {
+		return err
+	}
+	// no tokens are signed here so the keys are not loaded
+	svc, err := service.NewService(repo, nil, passwords(cfg.Password))
+	if err != nil {
+		return err
+	}
+
+	userDetails, err := svc.CreateUser(context.Background(), models.NewUser{
+		Username: *username,
+		Email:    *email,
+		Password: *password,
+	}, *role)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("created %s %s with id %d\n", userDetails.Role, userDetails.Username, userDetails.ID)
+	return nil
+}
+
+func readPassword() (string, error) {
+	fmt.Fprint(os.Stderr, "password: ")
+	line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && line == "" {
+		return "", fmt.Errorf("error in reading the password : %w", err)
+	}
+	password := strings.TrimRight(line, "\r\n")
+	if password == "" {
+		return "", errors.New("password cannot be empty")
+	}
+	return password, nil
+}
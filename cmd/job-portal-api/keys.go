@@ -0,0 +1,82 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/afthaab/job-portal/internal/auth"
+	"github.com/afthaab/job-portal/internal/config"
+)
+
+// runGenKeys is the gen-keys subcommand, it writes the keys where the config makes the server look for them.
+// With a keys dir a new <kid>.pem is added next to the old keys, which keep verifying the tokens they signed
+func runGenKeys(args []string) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("error in loading the config : %w", err)
+	}
+
+	fs := flag.NewFlagSet("gen-keys", flag.ContinueOnError)
+	bits := fs.Int("bits", auth.MinKeyBits, "size of the rsa key")
+	dir := fs.String("dir", cfg.Auth.KeysDir, "keys dir to add a <kid>.pem to, the key pair files are written when empty")
+	kid := fs.String("kid", time.Now().UTC().Format("2006-01-02"), "id of the key in the keys dir, the newest id by name signs the tokens")
+	privatePath := fs.String("private", cfg.Auth.PrivateKeyPath, "file of the private key")
+	publicPath := fs.String("public", cfg.Auth.PublicKeyPath, "file of the public key")
+	force := fs.Bool("force", false, "overwrite existing key files")
+	err = fs.Parse(args)
+	if err != nil {
+		return err
+	}
+
+	privatePEM, publicPEM, err := auth.GenerateKeyPEM(*bits)
+	if err != nil {
+		return err
+	}
+
+	if *dir != "" {
+		if *kid == "" || filepath.Base(*kid) != *kid {
+			return fmt.Errorf("invalid key id %q", *kid)
+		}
+		err = os.MkdirAll(*dir, 0o700)
+		if err != nil {
+			return fmt.Errorf("error in creating the keys dir : %w", err)
+		}
+		// the private key holds the public one, the keys dir only needs the one file
+		return writeKey(filepath.Join(*dir, *kid+".pem"), privatePEM, 0o600, *force)
+	}
+
+	err = writeKey(*privatePath, privatePEM, 0o600, *force)
+	if err != nil {
+		return err
+	}
+	return writeKey(*publicPath, publicPEM, 0o644, *force)
+}
+
+func writeKey(file string, data []byte, perm os.FileMode, force bool) error {
+	flags := os.O_WRONLY | os.O_CREATE | os.O_EXCL
+	if force {
+		flags = os.O_WRONLY | os.O_CREATE | os.O_TRUNC
+	}
+	f, err := os.OpenFile(file, flags, perm)
+	if errors.Is(err, os.ErrExist) {
+		return fmt.Errorf("%s already exists, pass -force to overwrite it", file)
+	}
+	if err != nil {
+		return fmt.Errorf("error in creating %s : %w", file, err)
+	}
+	_, err = f.Write(data)
+	if err != nil {
+		f.Close()
+		return fmt.Errorf("error in writing %s : %w", file, err)
+	}
+	err = f.Close()
+	if err != nil {
+		return fmt.Errorf("error in writing %s : %w", file, err)
+	}
+	fmt.Println("wrote", file)
+	return nil
+}
@@ -2,23 +2,53 @@ package main
 
 import (
 	"context"
+	"errors"
+	"flag"
 	"fmt"
 	"net/http"
 	"os"
 	"os/signal"
+	"strings"
+	"syscall"
 	"time"
 
 	"github.com/afthaab/job-portal/internal/auth"
+	"github.com/afthaab/job-portal/internal/config"
 	"github.com/afthaab/job-portal/internal/database"
 	"github.com/afthaab/job-portal/internal/handler"
+	"github.com/afthaab/job-portal/internal/health"
+	"github.com/afthaab/job-portal/internal/mail"
+	"github.com/afthaab/job-portal/internal/metrics"
+	"github.com/afthaab/job-portal/internal/password"
+	"github.com/afthaab/job-portal/internal/ratelimit"
 	"github.com/afthaab/job-portal/internal/repository"
 	"github.com/afthaab/job-portal/internal/service"
+	"github.com/afthaab/job-portal/internal/tracing"
 	"github.com/golang-jwt/jwt/v5"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
 	"github.com/rs/zerolog/log"
+	"gorm.io/gorm"
 )
 
+// command is a subcommand of the binary, run gets the arguments after its name
+type command struct {
+	name  string
+	usage string
+	run   func(args []string) error
+}
+
+var commands = []command{
+	{name: "serve", usage: "start the api server, the default without a command", run: runServe},
+	{name: "migrate", usage: "apply, revert or list the database migrations", run: runMigrate},
+	{name: "create-admin", usage: "create a user with an elevated role", run: runCreateAdmin},
+	{name: "gen-keys", usage: "generate the rsa key pair that signs the tokens", run: runGenKeys},
+	{name: "seed", usage: "load the demo companies and jobs", run: runSeed},
+	{name: "token", usage: "mint an access token for a user, for debugging", run: runToken},
+}
+
 func main() {
-	err := StartApp()
+	err := run(os.Args[1:])
 	if err != nil {
 		log.Panic().Err(err).Send()
 	}
@@ -26,29 +56,92 @@ func main() {
 
 }
 
-func StartApp() error {
-	// =========================================================================
-	// initializing the authentication support
-	log.Info().Msg("main started : initializing the authentication support")
+func run(args []string) error {
+	if len(args) == 0 {
+		return StartApp()
+	}
+	for _, cmd := range commands {
+		if cmd.name == args[0] {
+			err := cmd.run(args[1:])
+			// the flag set has already printed the usage of the command
+			if errors.Is(err, flag.ErrHelp) {
+				return nil
+			}
+			return err
+		}
+	}
+	if args[0] == "help" || args[0] == "-h" || args[0] == "--help" {
+		fmt.Println(usage())
+		return nil
+	}
+	return fmt.Errorf("unknown command %q\n%s", args[0], usage())
+}
 
-	//reading the private key file
-	privatePEM, err := os.ReadFile("private.pem")
+func usage() string {
+	var b strings.Builder
+	b.WriteString("usage: job-portal-api <command> [flags]\n\ncommands:")
+	for _, cmd := range commands {
+		fmt.Fprintf(&b, "\n  %-14s %s", cmd.name, cmd.usage)
+	}
+	return b.String()
+}
+
+func runServe(args []string) error {
+	fs := flag.NewFlagSet("serve", flag.ContinueOnError)
+	err := fs.Parse(args)
 	if err != nil {
-		return fmt.Errorf("error in reading auth private key : %w", err) // %w is used for error wraping
+		return err
 	}
-	privateKey, err := jwt.ParseRSAPrivateKeyFromPEM(privatePEM)
+	return StartApp()
+}
+
+func StartApp() error {
+	// =========================================================================
+	// loading the configuration
+	cfg, err := config.Load()
 	if err != nil {
-		return fmt.Errorf("error in parsing auth private key : %w", err) // %w is used for error wraping
+		return fmt.Errorf("error in loading the config : %w", err)
 	}
-	publicPEM, err := os.ReadFile("pubkey.pem")
+	summary := cfg.Redacted()
+	log.Info().
+		Str("addr", summary.App.Addr()).
+		Str("metrics addr", summary.App.MetricsAddr).
+		Dur("read timeout", summary.App.ReadTimeout).
+		Dur("write timeout", summary.App.WriteTimeout).
+		Dur("idle timeout", summary.App.IdleTimeout).
+		Str("db", summary.Database.DSN()).
+		Str("private key", summary.Auth.PrivateKeyPath).
+		Str("public key", summary.Auth.PublicKeyPath).
+		Str("keys dir", summary.Auth.KeysDir).
+		Msg("main started : configuration loaded")
+
+	// =========================================================================
+	// initializing the tracing
+	shutdownTracing, err := tracing.Setup(cfg.Tracing)
 	if err != nil {
-		return fmt.Errorf("error in reading auth public key : %w", err) // %w is used for error wraping
+		return err
 	}
-	publicKey, err := jwt.ParseRSAPublicKeyFromPEM(publicPEM)
+	defer func() {
+		// the spans of the last requests are still in the batcher
+		ctx, cancel := context.WithTimeout(context.Background(), cfg.App.ShutdownTimeout)
+		defer cancel()
+		err := shutdownTracing(ctx)
+		if err != nil {
+			log.Error().Err(err).Msg("error in flushing the traces")
+		}
+	}()
+	log.Info().Str("exporter", cfg.Tracing.Exporter).Msg("main started : tracing initialized")
+
+	// =========================================================================
+	// initializing the authentication support
+	log.Info().Msg("main started : initializing the authentication support")
+
+	keys, err := loadKeyRing(cfg.Auth)
 	if err != nil {
-		return fmt.Errorf("error in parsing auth public key : %w", err) // %w is used for error wraping
+		return err
 	}
-	a, err := auth.NewAuth(privateKey, publicKey)
+	log.Info().Str("kid", keys.Current().ID).Msg("main started : signing key loaded")
+	a, err := auth.NewAuth(keys)
 	if err != nil {
 		return fmt.Errorf("error in constructing auth %w", err)
 	}
@@ -58,57 +151,143 @@ func StartApp() error {
 
 	log.Info().Msg("main started : initializing the data")
 
-	db, err := database.ConnectToDatabase()
+	db, err := database.ConnectToDatabase(cfg.Database)
 	if err != nil {
 		return fmt.Errorf("error in opening the database connection : %w", err)
 	}
 
-	pg, err := db.DB()
-	if err != nil {
-		return fmt.Errorf("error in getting the database instance")
-	}
-
-	ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.Database.PingTimeout)
 	defer cancel()
 
-	err = pg.PingContext(ctx)
+	err = checkDatabase(ctx, db)
 	if err != nil {
-		return fmt.Errorf("database is not connected: %w", err)
+		return err
 	}
 
 	// =========================================================================
 	// initialize the repository layer
-	repo, err := repository.NewRepository(db)
+	repo, err := repository.NewRepository(db, queryTimeouts(cfg.Database))
+	if err != nil {
+		return err
+	}
+
+	rl := cfg.RateLimit
+	lockout := ratelimit.NewMemoryLockout(ratelimit.LockoutPolicy{
+		Threshold: rl.LockoutThreshold,
+		Base:      rl.LockoutBase,
+		Max:       rl.LockoutMax,
+	})
+	mailer, err := newMailer(cfg.Mail)
+	if err != nil {
+		return err
+	}
+	svc, err := service.NewService(repo, a,
+		service.WithTokenTTL(cfg.Auth.AccessTokenTTL, cfg.Auth.RefreshTokenTTL),
+		service.WithLockout(lockout),
+		service.WithMailer(mailer),
+		service.WithVerification(service.Verification{
+			Required: cfg.Auth.RequireVerifiedEmail,
+			TTL:      cfg.Auth.EmailVerificationTTL,
+			URL:      cfg.Mail.VerifyURL,
+		}),
+		service.WithPasswordReset(service.PasswordReset{
+			TTL: cfg.Auth.PasswordResetTTL,
+			URL: cfg.Mail.ResetURL,
+		}),
+		service.WithInvitationURL(cfg.Mail.InviteURL),
+		passwords(cfg.Password),
+	)
 	if err != nil {
 		return err
 	}
+	// the revoked access tokens are loaded back so a restart does not bring them back to life,
+	// and read again every so often so a logout through another instance holds here too
+	err = svc.LoadRevocations(ctx)
+	if err != nil {
+		return fmt.Errorf("error in loading the revoked tokens : %w", err)
+	}
+	syncCtx, stopSync := context.WithCancel(context.Background())
+	defer stopSync()
+	if cfg.Auth.RevocationSyncInterval > 0 {
+		go syncRevocations(syncCtx, svc, cfg.Auth.RevocationSyncInterval)
+	}
 
-	svc, err := service.NewService(repo, a)
+	limits := handler.RateLimits{
+		Store:      ratelimit.NewMemoryStore(),
+		PerIP:      ratelimit.Limit{Burst: rl.IPBurst, Every: rl.IPInterval},
+		PerAccount: ratelimit.Limit{Burst: rl.AccountBurst, Every: rl.AccountInterval},
+	}
+
+	// =========================================================================
+	// readiness checks of /readyz
+	pg, err := db.DB()
+	if err != nil {
+		return fmt.Errorf("error in getting the database instance")
+	}
+	migrator, err := database.NewMigrator(db)
 	if err != nil {
 		return err
 	}
+	checker := health.NewChecker(cfg.App.ProbeTimeout)
+	checker.Add("database", health.Database(pg))
+	checker.Add("signing_keys", health.SigningKeys(keys))
+	checker.Add("migrations", health.Migrations(migrator))
+
+	// =========================================================================
+	// metrics of /metrics and the sql spans, the http metrics and spans are recorded by the middleware
+	err = db.Use(metrics.GormPlugin{})
+	if err != nil {
+		return fmt.Errorf("error in registering the gorm metrics : %w", err)
+	}
+	err = db.Use(tracing.GormPlugin{})
+	if err != nil {
+		return fmt.Errorf("error in registering the gorm tracing : %w", err)
+	}
+	prometheus.MustRegister(collectors.NewDBStatsCollector(pg, cfg.Database.Name))
+
+	router := handler.SetupApi(a, service.Traced(svc), checker, limits)
+	// without trusted proxies the client ip is the remote address, X-Forwarded-For could dodge the rate limits otherwise
+	err = router.SetTrustedProxies(cfg.App.TrustedProxies)
+	if err != nil {
+		return fmt.Errorf("error in setting the trusted proxies : %w", err)
+	}
 
 	// initializing the http server
 	api := http.Server{
-		Addr:         ":8080",
-		ReadTimeout:  8000 * time.Second,
-		WriteTimeout: 800 * time.Second,
-		IdleTimeout:  800 * time.Second,
-		Handler:      handler.SetupApi(a, svc),
+		Addr:         cfg.App.Addr(),
+		ReadTimeout:  cfg.App.ReadTimeout,
+		WriteTimeout: cfg.App.WriteTimeout,
+		IdleTimeout:  cfg.App.IdleTimeout,
+		Handler:      router,
+	}
+
+	// the scrape listens apart from the api so it can be kept off the public network
+	metricsServer := http.Server{
+		Addr:         cfg.App.MetricsAddr,
+		ReadTimeout:  cfg.App.ReadTimeout,
+		WriteTimeout: cfg.App.WriteTimeout,
+		IdleTimeout:  cfg.App.IdleTimeout,
+		Handler:      handler.SetupMetrics(),
 	}
 
 	// channel to store any errors while setting up the service
-	serverErrors := make(chan error, 1)
+	serverErrors := make(chan error, 2)
 
 	go func() {
 		log.Info().Str("Port", api.Addr).Msg("main started : api is listening")
 		serverErrors <- api.ListenAndServe()
 	}()
+	if metricsServer.Addr != "" {
+		go func() {
+			log.Info().Str("Port", metricsServer.Addr).Msg("main started : metrics are listening")
+			serverErrors <- metricsServer.ListenAndServe()
+		}()
+	}
 
-	//shutdown channel intercepts ctrl+c signals
+	//shutdown channel intercepts ctrl+c signals and the sigterm kubernetes sends before killing the pod
 	shutdown := make(chan os.Signal, 1)
 
-	signal.Notify(shutdown, os.Interrupt)
+	signal.Notify(shutdown, os.Interrupt, syscall.SIGTERM)
 
 	select {
 	case err := <-serverErrors:
@@ -116,7 +295,11 @@ func StartApp() error {
 
 	case sig := <-shutdown:
 		log.Info().Msgf("main: Start shutdown %s", sig)
-		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		// readiness fails first so the load balancer stops sending requests before the listener closes
+		checker.Shutdown()
+		time.Sleep(cfg.App.ShutdownDelay)
+
+		ctx, cancel := context.WithTimeout(context.Background(), cfg.App.ShutdownTimeout)
 		defer cancel()
 
 		err := api.Shutdown(ctx)
@@ -124,7 +307,155 @@ func StartApp() error {
 			err := api.Close()
 			return fmt.Errorf("could not stop server gracefully : %w", err)
 		}
+		// the metrics stay up until the api is down so the last requests are still scraped
+		if metricsServer.Addr != "" {
+			err = metricsServer.Shutdown(ctx)
+			if err != nil {
+				log.Error().Err(err).Msg("main: metrics server did not stop gracefully")
+			}
+		}
+		// the mails of the last requests are sent after their responses
+		err = svc.Wait(ctx)
+		if err != nil {
+			log.Error().Err(err).Msg("main: background work did not finish before the shutdown timeout")
+		}
 	}
 	return nil
 
 }
+
+// syncRevocations reloads the revoked tokens until ctx ends, a failed reload is logged and tried again on the next tick
+func syncRevocations(ctx context.Context, svc service.UserService, every time.Duration) {
+	ticker := time.NewTicker(every)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			err := svc.LoadRevocations(ctx)
+			if err != nil {
+				log.Error().Err(err).Msg("error in reloading the revoked tokens")
+			}
+		}
+	}
+}
+
+// loadKeyRing reads the signing keys from the keys dir, or from the single key pair when no dir is configured
+func loadKeyRing(cfg config.AuthConfig) (*auth.KeyRing, error) {
+	if cfg.KeysDir != "" {
+		keys, err := auth.LoadKeyDir(cfg.KeysDir, cfg.CurrentKeyID, cfg.AccessTokenTTL)
+		if err != nil {
+			return nil, fmt.Errorf("error in loading the auth keys : %w", err)
+		}
+		return keys, nil
+	}
+
+	//reading the private key file
+	privatePEM, err := os.ReadFile(cfg.PrivateKeyPath)
+	if err != nil {
+		return nil, fmt.Errorf("error in reading auth private key : %w", err) // %w is used for error wraping
+	}
+	privateKey, err := jwt.ParseRSAPrivateKeyFromPEM(privatePEM)
+	if err != nil {
+		return nil, fmt.Errorf("error in parsing auth private key : %w", err) // %w is used for error wraping
+	}
+	publicPEM, err := os.ReadFile(cfg.PublicKeyPath)
+	if err != nil {
+		return nil, fmt.Errorf("error in reading auth public key : %w", err) // %w is used for error wraping
+	}
+	publicKey, err := jwt.ParseRSAPublicKeyFromPEM(publicPEM)
+	if err != nil {
+		return nil, fmt.Errorf("error in parsing auth public key : %w", err) // %w is used for error wraping
+	}
+	return auth.NewKeyRing(auth.SigningKey{
+		ID:         cfg.CurrentKeyID,
+		PrivateKey: privateKey,
+		PublicKey:  publicKey,
+	})
+}
+
+// checkDatabase pings the database and refuses a schema with pending migrations,
+// the schema is only changed by the migrate command and serving on an older one would fail at random
+func checkDatabase(ctx context.Context, db *gorm.DB) error {
+	pg, err := db.DB()
+	if err != nil {
+		return fmt.Errorf("error in getting the database instance")
+	}
+	err = pg.PingContext(ctx)
+	if err != nil {
+		return fmt.Errorf("database is not connected: %w", err)
+	}
+
+	migrator, err := database.NewMigrator(db)
+	if err != nil {
+		return err
+	}
+	return migrator.Check(ctx)
+}
+
+// openRepository loads the config and connects the repository for the commands that work on the data
+func openRepository() (config.Config, repository.UserRepo, error) {
+	cfg, err := config.Load()
+	if err != nil {
+		return config.Config{}, nil, fmt.Errorf("error in loading the config : %w", err)
+	}
+	db, err := database.ConnectToDatabase(cfg.Database)
+	if err != nil {
+		return config.Config{}, nil, fmt.Errorf("error in opening the database connection : %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.Database.PingTimeout)
+	defer cancel()
+	err = checkDatabase(ctx, db)
+	if err != nil {
+		return config.Config{}, nil, err
+	}
+
+	repo, err := repository.NewRepository(db, queryTimeouts(cfg.Database))
+	if err != nil {
+		return config.Config{}, nil, err
+	}
+	return cfg, repo, nil
+}
+
+// newMailer builds the mailer of the configured driver
+func newMailer(cfg config.MailConfig) (mail.Mailer, error) {
+	switch cfg.Driver {
+	case config.MailerSMTP:
+		return mail.NewSMTPMailer(mail.SMTPConfig{
+			Host:     cfg.SMTPHost,
+			Port:     cfg.SMTPPort,
+			Username: cfg.SMTPUsername,
+			Password: cfg.SMTPPassword,
+			From:     cfg.From,
+		})
+	case config.MailerFile:
+		return mail.NewFileMailer(cfg.Dir, cfg.From)
+	default:
+		log.Warn().Msg("mail driver is log, mails are logged and not sent")
+		return mail.LogMailer{}, nil
+	}
+}
+
+// passwords hands the password policy and the hasher of the config to the service
+func passwords(cfg config.PasswordConfig) service.Option {
+	var current password.Algorithm = password.Bcrypt{Cost: cfg.BcryptCost}
+	if cfg.Hasher == config.HasherArgon2id {
+		current = password.Argon2id{
+			Time:    uint32(cfg.Argon2Time),
+			Memory:  uint32(cfg.Argon2Memory),
+			Threads: uint8(cfg.Argon2Threads),
+		}
+	}
+	return service.WithPasswords(password.Policy{MinLength: cfg.MinLength, MaxLength: cfg.MaxLength}, password.NewHasher(current))
+}
+
+// queryTimeouts hands the query timeouts of the config to the repository
+func queryTimeouts(cfg config.DatabaseConfig) repository.Option {
+	return repository.WithTimeouts(repository.Timeouts{
+		Read:   cfg.ReadTimeout,
+		Write:  cfg.WriteTimeout,
+		Search: cfg.SearchTimeout,
+	})
+}
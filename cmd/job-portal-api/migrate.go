@@ -0,0 +1,116 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/afthaab/job-portal/internal/config"
+	"github.com/afthaab/job-portal/internal/database"
+)
+
+const migrateUsage = `usage: job-portal-api migrate <command> [flags]
+
+commands:
+  up                 apply every pending migration
+  down [-steps n]    revert the last n applied migrations, 1 by default
+  status             list the migrations and when they were applied
+  create <name>      write an empty up and down file for the next version`
+
+// runMigrate is the migrate subcommand, every command but create connects to the configured database
+func runMigrate(args []string) error {
+	if len(args) == 0 {
+		return errors.New(migrateUsage)
+	}
+	command, args := args[0], args[1:]
+
+	if command == "create" {
+		fs := flag.NewFlagSet("migrate create", flag.ContinueOnError)
+		dir := fs.String("dir", database.MigrationsDir, "directory of the sql migrations")
+		err := fs.Parse(args)
+		if err != nil {
+			return err
+		}
+		if fs.NArg() != 1 {
+			return errors.New("usage: job-portal-api migrate create [-dir dir] <name>")
+		}
+		paths, err := database.CreateMigration(*dir, fs.Arg(0))
+		if err != nil {
+			return err
+		}
+		for _, path := range paths {
+			fmt.Println("created", path)
+		}
+		return nil
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("error in loading the config : %w", err)
+	}
+	db, err := database.ConnectToDatabase(cfg.Database)
+	if err != nil {
+		return fmt.Errorf("error in opening the database connection : %w", err)
+	}
+	migrator, err := database.NewMigrator(db)
+	if err != nil {
+		return err
+	}
+	ctx := context.Background()
+
+	switch command {
+	case "up":
+		done, err := migrator.Up(ctx)
+		for _, m := range done {
+			fmt.Printf("applied %04d_%s\n", m.Version, m.Name)
+		}
+		if err != nil {
+			return err
+		}
+		if len(done) == 0 {
+			fmt.Println("schema is up to date")
+		}
+		return nil
+
+	case "down":
+		fs := flag.NewFlagSet("migrate down", flag.ContinueOnError)
+		steps := fs.Int("steps", 1, "number of migrations to revert")
+		err := fs.Parse(args)
+		if err != nil {
+			return err
+		}
+		if *steps < 1 {
+			return errors.New("steps must be at least 1")
+		}
+		done, err := migrator.Down(ctx, *steps)
+		for _, m := range done {
+			fmt.Printf("reverted %04d_%s\n", m.Version, m.Name)
+		}
+		return err
+
+	case "status":
+		statuses, err := migrator.Status(ctx)
+		if err != nil {
+			return err
+		}
+		w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(w, "VERSION\tNAME\tAPPLIED AT")
+		for _, s := range statuses {
+			appliedAt := "pending"
+			if s.AppliedAt != nil {
+				appliedAt = s.AppliedAt.Format("2006-01-02 15:04:05 MST")
+			}
+			if s.Unknown {
+				appliedAt += " (not in this binary)"
+			}
+			fmt.Fprintf(w, "%04d\t%s\t%s\n", s.Version, s.Name, appliedAt)
+		}
+		return w.Flush()
+
+	default:
+		return fmt.Errorf("unknown migrate command %q\n%s", command, migrateUsage)
+	}
+}
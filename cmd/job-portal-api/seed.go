@@ -0,0 +1,126 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"strconv"
+
+	"github.com/afthaab/job-portal/internal/apperr"
+	"github.com/afthaab/job-portal/internal/auth"
+	"github.com/afthaab/job-portal/internal/models"
+	"github.com/afthaab/job-portal/internal/service"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+type demoCompany struct {
+	company models.NewCompany
+	jobs    []models.NewJob
+}
+
+// demoCompanies are loaded by the seed command, company names are unique so seeding twice skips them
+var demoCompanies = []demoCompany{
+	{
+		company: models.NewCompany{Name: "Acme Cloud", Location: "Bengaluru", Field: "Software"},
+		jobs: []models.NewJob{
+			{
+				Name:             "Backend Engineer",
+				Compensation:     models.Compensation{MinAmount: 1800000, MaxAmount: 2600000, Currency: "INR", PayPeriod: "yearly"},
+				NoticePeriodDays: 30,
+				Description:      "Build and run the go services behind our storage api.",
+				Status:           models.JobOpen,
+			},
+			{
+				Name:             "Site Reliability Engineer",
+				Compensation:     models.Compensation{MinAmount: 2000000, MaxAmount: 3000000, Currency: "INR", PayPeriod: "yearly", Equity: true},
+				NoticePeriodDays: 60,
+				Description:      "Keep the postgres fleet and the kubernetes clusters healthy.",
+				Status:           models.JobOpen,
+			},
+		},
+	},
+	{
+		company: models.NewCompany{Name: "Northwind Health", Location: "Hyderabad", Field: "Healthcare"},
+		jobs: []models.NewJob{
+			{
+				Name:             "Data Analyst",
+				Compensation:     models.Compensation{MinAmount: 90000, MaxAmount: 130000, Currency: "INR", PayPeriod: "monthly"},
+				NoticePeriodDays: 30,
+				Description:      "Turn the clinic data into reports for the operations team.",
+				Status:           models.JobOpen,
+			},
+			{
+				Name:             "Frontend Developer",
+				Compensation:     models.Compensation{MinAmount: 1200000, MaxAmount: 1800000, Currency: "INR", PayPeriod: "yearly"},
+				NoticePeriodDays: 45,
+				Description:      "Own the patient portal written in react.",
+				Status:           models.JobDraft,
+			},
+		},
+	},
+	{
+		company: models.NewCompany{Name: "Blue Harbor Logistics", Location: "Chennai", Field: "Logistics"},
+		jobs: []models.NewJob{
+			{
+				Name:             "Warehouse Supervisor",
+				Compensation:     models.Compensation{MinAmount: 250, MaxAmount: 400, Currency: "INR", PayPeriod: "hourly"},
+				NoticePeriodDays: 15,
+				Description:      "Lead the night shift of the port warehouse.",
+				Status:           models.JobOpen,
+			},
+		},
+	},
+}
+
+// runSeed is the seed subcommand, it loads the demo companies through the service as if the owner had added them
+func runSeed(args []string) error {
+	fs := flag.NewFlagSet("seed", flag.ContinueOnError)
+	owner := fs.Uint64("owner", 0, "id of the user that owns the demo companies, create one with create-admin")
+	err := fs.Parse(args)
+	if err != nil {
+		return err
+	}
+	if *owner == 0 {
+		return errors.New("usage: job-portal-api seed -owner <user id>")
+	}
+
+	_, repo, err := openRepository()
+	if err != nil {
+		return err
+	}
+	// no tokens are signed here so the keys are not loaded
+	svc, err := service.NewService(repo, nil)
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	ownerDetails, err := repo.FindUserById(ctx, *owner)
+	if err != nil {
+		return err
+	}
+	claims := auth.Claims{
+		RegisteredClaims: jwt.RegisteredClaims{Subject: strconv.FormatUint(*owner, 10)},
+		Role:             ownerDetails.Role,
+	}
+
+	for _, demo := range demoCompanies {
+		companyDetails, err := svc.AddCompanyDetails(ctx, claims, demo.company)
+		if errors.Is(err, apperr.ErrConflict) {
+			fmt.Printf("skipped %s, it already exists\n", demo.company.Name)
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("error in seeding %s : %w", demo.company.Name, err)
+		}
+		for _, job := range demo.jobs {
+			_, err = svc.AddJobDetails(ctx, claims, job, uint64(companyDetails.ID))
+			if err != nil {
+				return fmt.Errorf("error in seeding %s at %s : %w", job.Name, demo.company.Name, err)
+			}
+		}
+		fmt.Printf("created %s with %d jobs\n", demo.company.Name, len(demo.jobs))
+	}
+	return nil
+}
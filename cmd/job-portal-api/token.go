@@ -0,0 +1,55 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+
+	"github.com/afthaab/job-portal/internal/auth"
+	"github.com/afthaab/job-portal/internal/service"
+)
+
+// runToken is the token subcommand, it signs an access token for the user with the server keys
+// so the routes can be called as that user while debugging
+func runToken(args []string) error {
+	fs := flag.NewFlagSet("token", flag.ContinueOnError)
+	uid := fs.Uint64("user", 0, "id of the user the token is for")
+	ttl := fs.Duration("ttl", 0, "how long the token stays valid, the configured access token ttl by default")
+	err := fs.Parse(args)
+	if err != nil {
+		return err
+	}
+	if *uid == 0 {
+		return errors.New("usage: job-portal-api token -user <id> [-ttl duration]")
+	}
+
+	cfg, repo, err := openRepository()
+	if err != nil {
+		return err
+	}
+	keys, err := loadKeyRing(cfg.Auth)
+	if err != nil {
+		return err
+	}
+	a, err := auth.NewAuth(keys)
+	if err != nil {
+		return fmt.Errorf("error in constructing auth %w", err)
+	}
+
+	accessTTL := cfg.Auth.AccessTokenTTL
+	if *ttl > 0 {
+		accessTTL = *ttl
+	}
+	svc, err := service.NewService(repo, a, service.WithTokenTTL(accessTTL, cfg.Auth.RefreshTokenTTL))
+	if err != nil {
+		return err
+	}
+
+	token, err := svc.AccessToken(context.Background(), *uid)
+	if err != nil {
+		return err
+	}
+	fmt.Println(token)
+	return nil
+}
@@ -0,0 +1,173 @@
+// Package apperr holds the domain errors shared by the repository, service and handler layers.
+// The repository and the service return them and the handler maps their kind to a status code once.
+package apperr
+
+import (
+	"errors"
+	"time"
+)
+
+type Kind int
+
+const (
+	// KindInternal is the zero value so any error that was not classified is treated as internal
+	KindInternal Kind = iota
+	KindNotFound
+	KindConflict
+	KindValidation
+	KindUnauthorized
+	KindForbidden
+	KindTooLarge
+	// KindCanceled is a request the client gave up on, KindTimeout one that ran out of time on our side
+	KindCanceled
+	KindTimeout
+	KindTooManyRequests
+)
+
+func (k Kind) String() string {
+	switch k {
+	case KindNotFound:
+		return "not found"
+	case KindConflict:
+		return "conflict"
+	case KindValidation:
+		return "validation"
+	case KindUnauthorized:
+		return "unauthorized"
+	case KindForbidden:
+		return "forbidden"
+	case KindTooLarge:
+		return "too large"
+	case KindCanceled:
+		return "canceled"
+	case KindTimeout:
+		return "timeout"
+	case KindTooManyRequests:
+		return "too many requests"
+	default:
+		return "internal"
+	}
+}
+
+// Error is a domain error, the message is safe to show to the client and the wrapped error is only logged
+type Error struct {
+	Kind Kind
+	Msg  string
+	Err  error
+	// Fields lists what is wrong with each field of a request that failed validation
+	Fields []FieldError
+	// RetryAfter tells a throttled client when to come back
+	RetryAfter time.Duration
+}
+
+func (e *Error) Error() string {
+	if e.Msg == "" {
+		return e.Kind.String()
+	}
+	return e.Msg
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Is lets errors.Is match any error against the kind sentinels below, other errors only match themselves
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	return ok && t.Msg == "" && t.Err == nil && t.Kind == e.Kind
+}
+
+// sentinels to check the kind of an error with errors.Is
+var (
+	ErrInternal        = &Error{Kind: KindInternal}
+	ErrNotFound        = &Error{Kind: KindNotFound}
+	ErrConflict        = &Error{Kind: KindConflict}
+	ErrValidation      = &Error{Kind: KindValidation}
+	ErrUnauthorized    = &Error{Kind: KindUnauthorized}
+	ErrForbidden       = &Error{Kind: KindForbidden}
+	ErrTooLarge        = &Error{Kind: KindTooLarge}
+	ErrCanceled        = &Error{Kind: KindCanceled}
+	ErrTimeout         = &Error{Kind: KindTimeout}
+	ErrTooManyRequests = &Error{Kind: KindTooManyRequests}
+)
+
+func New(kind Kind, msg string) error {
+	return &Error{Kind: kind, Msg: msg}
+}
+
+func Wrap(kind Kind, msg string, err error) error {
+	return &Error{Kind: kind, Msg: msg, Err: err}
+}
+
+func NotFound(msg string) error {
+	return New(KindNotFound, msg)
+}
+
+func Conflict(msg string) error {
+	return New(KindConflict, msg)
+}
+
+func Validation(msg string) error {
+	return New(KindValidation, msg)
+}
+
+// Invalid is a validation error that carries the details of every field that failed
+func Invalid(msg string, fields ...FieldError) error {
+	return &Error{Kind: KindValidation, Msg: msg, Fields: fields}
+}
+
+func Unauthorized(msg string) error {
+	return New(KindUnauthorized, msg)
+}
+
+func Forbidden(msg string) error {
+	return New(KindForbidden, msg)
+}
+
+func TooLarge(msg string) error {
+	return New(KindTooLarge, msg)
+}
+
+func Canceled(msg string) error {
+	return New(KindCanceled, msg)
+}
+
+func Timeout(msg string) error {
+	return New(KindTimeout, msg)
+}
+
+// TooManyRequests is a throttled request, the client may try again after retryAfter
+func TooManyRequests(msg string, retryAfter time.Duration) error {
+	return &Error{Kind: KindTooManyRequests, Msg: msg, RetryAfter: retryAfter}
+}
+
+func Internal(msg string, err error) error {
+	return Wrap(KindInternal, msg, err)
+}
+
+// KindOf returns the kind of the first domain error in the chain, errors that are not domain errors are internal
+func KindOf(err error) Kind {
+	var e *Error
+	if errors.As(err, &e) {
+		return e.Kind
+	}
+	return KindInternal
+}
+
+// FieldsOf returns the field errors of the first domain error in the chain
+func FieldsOf(err error) []FieldError {
+	var e *Error
+	if errors.As(err, &e) {
+		return e.Fields
+	}
+	return nil
+}
+
+// RetryAfterOf returns how long a throttled client has to wait, zero for every other error
+func RetryAfterOf(err error) time.Duration {
+	var e *Error
+	if errors.As(err, &e) {
+		return e.RetryAfter
+	}
+	return 0
+}
@@ -0,0 +1,47 @@
+package apperr
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestError_Is(t *testing.T) {
+	notFound := NotFound("could not find the company")
+	wrapped := fmt.Errorf("loading the company : %w", notFound)
+	specific := Unauthorized("refresh token has already been used")
+
+	tests := []struct {
+		name   string
+		err    error
+		target error
+		want   bool
+	}{
+		{"kind matches", notFound, ErrNotFound, true},
+		{"kind matches through wrapping", wrapped, ErrNotFound, true},
+		{"other kind", notFound, ErrConflict, false},
+		{"specific error matches itself", specific, specific, true},
+		{"specific error is not matched by another of the same kind", Unauthorized("invalid credentials"), specific, false},
+		{"plain error", errors.New("boom"), ErrInternal, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := errors.Is(tt.err, tt.target); got != tt.want {
+				t.Errorf("errors.Is() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestKindOf(t *testing.T) {
+	if got := KindOf(fmt.Errorf("wrapped : %w", Forbidden("no"))); got != KindForbidden {
+		t.Errorf("KindOf() = %v, want %v", got, KindForbidden)
+	}
+	if got := KindOf(errors.New("boom")); got != KindInternal {
+		t.Errorf("KindOf() = %v, want %v", got, KindInternal)
+	}
+	cause := errors.New("connection refused")
+	if got := Internal("could not find the company", cause); !errors.Is(got, cause) {
+		t.Errorf("Internal() does not wrap the cause")
+	}
+}
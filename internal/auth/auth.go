@@ -1,8 +1,8 @@
 package auth
 
 import (
-	"crypto/rsa"
 	"errors"
+	"time"
 
 	"github.com/golang-jwt/jwt/v5"
 )
@@ -11,24 +11,33 @@ type ctxKey int
 
 const Key ctxKey = 1
 
+// Claims carries the role of the user along with the registered claims
+type Claims struct {
+	jwt.RegisteredClaims
+	Role string `json:"role"`
+}
+
 type Auth struct {
-	privateKey *rsa.PrivateKey
-	publickey  *rsa.PublicKey
+	keys    *KeyRing
+	revoked RevocationList
 }
 
 //go:generate mockgen -source=auth.go -destination=mockModels/auth_mock.go -package=auth
 
 type Authentication interface {
-	GenerateAuthToken(claims jwt.RegisteredClaims) (string, error)
-	ValidateToken(token string) (jwt.RegisteredClaims, error)
+	GenerateAuthToken(claims Claims) (string, error)
+	ValidateToken(token string) (Claims, error)
+	RevokeToken(jti string, expiresAt time.Time)
+	RevokeSubject(subject string, issuedBefore time.Time, expiresAt time.Time)
+	JWKS() JWKS
 }
 
-func NewAuth(privateKey *rsa.PrivateKey, publicKey *rsa.PublicKey) (Authentication, error) {
-	if privateKey == nil && publicKey == nil {
-		return nil, errors.New("publickey and privatekey cannot be null")
+func NewAuth(keys *KeyRing) (Authentication, error) {
+	if keys == nil {
+		return nil, errors.New("key ring cannot be null")
 	}
 	return &Auth{
-		privateKey: privateKey,
-		publickey:  publicKey,
+		keys:    keys,
+		revoked: NewMemoryRevocationList(),
 	}, nil
 }
@@ -0,0 +1,37 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+)
+
+// MinKeyBits is the smallest RSA key GenerateKeyPEM hands out
+const MinKeyBits = 2048
+
+// GenerateKeyPEM creates an RSA key pair encoded the way openssl genpkey and openssl rsa -pubout write them,
+// so LoadKeyFile and LoadKeyDir read the files just like the ones made by hand
+func GenerateKeyPEM(bits int) (privatePEM []byte, publicPEM []byte, err error) {
+	if bits < MinKeyBits {
+		return nil, nil, fmt.Errorf("rsa keys need at least %d bits", MinKeyBits)
+	}
+	privateKey, err := rsa.GenerateKey(rand.Reader, bits)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error in generating the rsa key : %w", err)
+	}
+
+	privateDER, err := x509.MarshalPKCS8PrivateKey(privateKey)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error in encoding the private key : %w", err)
+	}
+	publicDER, err := x509.MarshalPKIXPublicKey(&privateKey.PublicKey)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error in encoding the public key : %w", err)
+	}
+
+	privatePEM = pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: privateDER})
+	publicPEM = pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicDER})
+	return privatePEM, publicPEM, nil
+}
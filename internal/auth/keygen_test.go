@@ -0,0 +1,42 @@
+package auth
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestGenerateKeyPEM(t *testing.T) {
+	_, _, err := GenerateKeyPEM(1024)
+	if err == nil {
+		t.Errorf("GenerateKeyPEM() should refuse keys below %d bits", MinKeyBits)
+	}
+
+	privatePEM, publicPEM, err := GenerateKeyPEM(MinKeyBits)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	err = os.WriteFile(filepath.Join(dir, "2024-06-01.pem"), privatePEM, 0o600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	publicFile := filepath.Join(t.TempDir(), "pubkey.pem")
+	err = os.WriteFile(publicFile, publicPEM, 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ring, err := LoadKeyDir(dir, "", time.Hour)
+	if err != nil {
+		t.Fatalf("LoadKeyDir() on a generated key error = %v", err)
+	}
+	public, err := LoadKeyFile(publicFile)
+	if err != nil {
+		t.Fatalf("LoadKeyFile() on a generated public key error = %v", err)
+	}
+	if public.PrivateKey != nil || !public.PublicKey.Equal(ring.Current().PublicKey) {
+		t.Errorf("generated public key does not match the private key")
+	}
+}
@@ -0,0 +1,262 @@
+package auth
+
+import (
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// SigningKey is one RSA key of the key ring, keys without a private key can only verify tokens
+type SigningKey struct {
+	ID         string
+	PrivateKey *rsa.PrivateKey
+	PublicKey  *rsa.PublicKey
+	// NotAfter is when the key stops verifying tokens, the zero value means it never does
+	NotAfter time.Time
+}
+
+func (k SigningKey) expired(now time.Time) bool {
+	return !k.NotAfter.IsZero() && now.After(k.NotAfter)
+}
+
+// KeyRing signs with the current key and verifies with every key that has not expired,
+// so keys can be rotated without logging everyone out
+type KeyRing struct {
+	mu      sync.RWMutex
+	current string
+	keys    map[string]SigningKey
+}
+
+// NewKeyRing creates a ring that signs with the given key
+func NewKeyRing(current SigningKey) (*KeyRing, error) {
+	if current.PrivateKey == nil {
+		return nil, errors.New("the current key needs a private key")
+	}
+	if current.PublicKey == nil {
+		current.PublicKey = &current.PrivateKey.PublicKey
+	}
+	if current.ID == "" {
+		current.ID = Thumbprint(current.PublicKey)
+	}
+	return &KeyRing{
+		current: current.ID,
+		keys:    map[string]SigningKey{current.ID: current},
+	}, nil
+}
+
+// Add puts a key in the ring that is only used to verify tokens
+func (r *KeyRing) Add(key SigningKey) error {
+	if key.PublicKey == nil {
+		if key.PrivateKey == nil {
+			return errors.New("key needs a public key")
+		}
+		key.PublicKey = &key.PrivateKey.PublicKey
+	}
+	if key.ID == "" {
+		key.ID = Thumbprint(key.PublicKey)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if key.ID == r.current {
+		return fmt.Errorf("key %s is already the current key", key.ID)
+	}
+	r.keys[key.ID] = key
+	return nil
+}
+
+// Rotate makes the given key the current one, the old key keeps verifying tokens for the grace period
+// which should be at least as long as an access token lives
+func (r *KeyRing) Rotate(next SigningKey, grace time.Duration) error {
+	if next.PrivateKey == nil {
+		return errors.New("the current key needs a private key")
+	}
+	if next.PublicKey == nil {
+		next.PublicKey = &next.PrivateKey.PublicKey
+	}
+	if next.ID == "" {
+		next.ID = Thumbprint(next.PublicKey)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	old := r.keys[r.current]
+	old.NotAfter = time.Now().Add(grace)
+	r.keys[old.ID] = old
+	r.keys[next.ID] = next
+	r.current = next.ID
+	return nil
+}
+
+// Current returns the key new tokens are signed with
+func (r *KeyRing) Current() SigningKey {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.keys[r.current]
+}
+
+// Verifier returns the key with the given id if it is still allowed to verify tokens
+func (r *KeyRing) Verifier(kid string) (SigningKey, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	key, ok := r.keys[kid]
+	if !ok || key.expired(time.Now()) {
+		return SigningKey{}, false
+	}
+	return key, true
+}
+
+// JWKS returns the public part of every key that can still verify tokens
+func (r *KeyRing) JWKS() JWKS {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	now := time.Now()
+	set := JWKS{Keys: []JWK{}}
+	for _, key := range r.keys {
+		if key.expired(now) {
+			continue
+		}
+		set.Keys = append(set.Keys, newJWK(key.ID, key.PublicKey))
+	}
+	// the current key first and the rest in a stable order
+	sort.Slice(set.Keys, func(i, j int) bool {
+		if set.Keys[i].Kid == r.current || set.Keys[j].Kid == r.current {
+			return set.Keys[i].Kid == r.current
+		}
+		return set.Keys[i].Kid < set.Keys[j].Kid
+	})
+	return set
+}
+
+// LoadKeyDir builds a key ring from a directory of pem files named <kid>.pem. Private keys can sign
+// and public keys can only verify. The current key is the one named currentKid, or the last private key
+// by name when currentKid is empty, so date based names like 2024-01-15.pem rotate by adding a file.
+// Every other key verifies for the grace period only, counted from when the file of the current key was
+// last modified because that is when the others stopped signing, so a restart does not extend it.
+// Switching back to an older key by currentKid should touch its file for the same reason
+func LoadKeyDir(dir string, currentKid string, grace time.Duration) (*KeyRing, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*.pem"))
+	if err != nil {
+		return nil, fmt.Errorf("error in listing the keys : %w", err)
+	}
+	sort.Strings(files)
+
+	var keys []SigningKey
+	modTimes := make(map[string]time.Time, len(files))
+	for _, file := range files {
+		key, err := LoadKeyFile(file)
+		if err != nil {
+			return nil, err
+		}
+		info, err := os.Stat(file)
+		if err != nil {
+			return nil, fmt.Errorf("error in reading the key %s : %w", file, err)
+		}
+		key.ID = strings.TrimSuffix(filepath.Base(file), ".pem")
+		modTimes[key.ID] = info.ModTime()
+		keys = append(keys, key)
+	}
+
+	if currentKid == "" {
+		for _, key := range keys {
+			if key.PrivateKey != nil {
+				currentKid = key.ID
+			}
+		}
+	}
+
+	var ring *KeyRing
+	for _, key := range keys {
+		if key.ID == currentKid {
+			ring, err = NewKeyRing(key)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+	if ring == nil {
+		return nil, fmt.Errorf("no private key to sign with found in %s", dir)
+	}
+
+	notAfter := modTimes[currentKid].Add(grace)
+	for _, key := range keys {
+		if key.ID == currentKid {
+			continue
+		}
+		key.NotAfter = notAfter
+		err = ring.Add(key)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return ring, nil
+}
+
+// LoadKeyFile reads a pem file holding either an RSA private key or an RSA public key
+func LoadKeyFile(path string) (SigningKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return SigningKey{}, fmt.Errorf("error in reading the key %s : %w", path, err)
+	}
+	privateKey, err := jwt.ParseRSAPrivateKeyFromPEM(data)
+	if err == nil {
+		return SigningKey{PrivateKey: privateKey, PublicKey: &privateKey.PublicKey}, nil
+	}
+	publicKey, err := jwt.ParseRSAPublicKeyFromPEM(data)
+	if err != nil {
+		return SigningKey{}, fmt.Errorf("error in parsing the key %s : %w", path, err)
+	}
+	return SigningKey{PublicKey: publicKey}, nil
+}
+
+// JWK is the json web key form of an RSA public key
+type JWK struct {
+	Kty string `json:"kty"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	Kid string `json:"kid"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+}
+
+// JWKS is the document served at /.well-known/jwks.json
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+func newJWK(kid string, publicKey *rsa.PublicKey) JWK {
+	return JWK{
+		Kty: "RSA",
+		Use: "sig",
+		Alg: jwt.SigningMethodRS256.Alg(),
+		Kid: kid,
+		N:   base64.RawURLEncoding.EncodeToString(publicKey.N.Bytes()),
+		E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(publicKey.E)).Bytes()),
+	}
+}
+
+// Thumbprint is the RFC 7638 thumbprint of the key, it is used as the kid when none is given
+func Thumbprint(publicKey *rsa.PublicKey) string {
+	jwk := newJWK("", publicKey)
+	// the members have to be in lexical order with no whitespace
+	data, _ := json.Marshal(struct {
+		E   string `json:"e"`
+		Kty string `json:"kty"`
+		N   string `json:"n"`
+	}{jwk.E, jwk.Kty, jwk.N})
+	sum := sha256.Sum256(data)
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
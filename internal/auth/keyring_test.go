@@ -0,0 +1,163 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+func newTestKey(t *testing.T, kid string) SigningKey {
+	t.Helper()
+	privateKey, err := rsa.GenerateKey(rand.Reader, 1024)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return SigningKey{ID: kid, PrivateKey: privateKey}
+}
+
+func testClaims() Claims {
+	return Claims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   "1",
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+		},
+		Role: "candidate",
+	}
+}
+
+func TestAuth_KeyRotation(t *testing.T) {
+	ring, err := NewKeyRing(newTestKey(t, "old"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	a, err := NewAuth(ring)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	oldToken, err := a.GenerateAuthToken(testClaims())
+	if err != nil {
+		t.Fatal(err)
+	}
+	tkn, _, err := jwt.NewParser().ParseUnverified(oldToken, &Claims{})
+	if err != nil || tkn.Header["kid"] != "old" {
+		t.Fatalf("token header = %v, want kid old", tkn.Header)
+	}
+
+	err = ring.Rotate(newTestKey(t, "new"), time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	newToken, err := a.GenerateAuthToken(testClaims())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for name, token := range map[string]string{"old": oldToken, "new": newToken} {
+		_, err = a.ValidateToken(token)
+		if err != nil {
+			t.Errorf("ValidateToken() with the %s key error = %v", name, err)
+		}
+	}
+
+	jwks := a.JWKS()
+	if len(jwks.Keys) != 2 || jwks.Keys[0].Kid != "new" || jwks.Keys[1].Kid != "old" {
+		t.Errorf("JWKS() = %+v, want the new and the old key", jwks)
+	}
+
+	// once the grace period is over the old key stops verifying and is no longer published
+	err = ring.Rotate(newTestKey(t, "newer"), -time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = a.ValidateToken(newToken)
+	if err == nil {
+		t.Errorf("ValidateToken() accepted a token signed with an expired key")
+	}
+	_, err = a.ValidateToken(oldToken)
+	if err != nil {
+		t.Errorf("ValidateToken() with the old key error = %v", err)
+	}
+	for _, key := range a.JWKS().Keys {
+		if key.Kid == "new" {
+			t.Errorf("JWKS() still publishes the expired key")
+		}
+	}
+}
+
+func TestAuth_ValidateTokenUnknownKid(t *testing.T) {
+	ring, err := NewKeyRing(newTestKey(t, "current"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	a, _ := NewAuth(ring)
+
+	other, _ := NewKeyRing(newTestKey(t, "other"))
+	b, _ := NewAuth(other)
+	token, err := b.GenerateAuthToken(testClaims())
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = a.ValidateToken(token)
+	if err == nil || !strings.Contains(err.Error(), "unknown or expired signing key") {
+		t.Errorf("ValidateToken() error = %v, want unknown signing key", err)
+	}
+}
+
+func TestLoadKeyDir(t *testing.T) {
+	dir := t.TempDir()
+	for _, kid := range []string{"2024-01-01", "2024-06-01"} {
+		key := newTestKey(t, kid)
+		data := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key.PrivateKey)})
+		err := os.WriteFile(filepath.Join(dir, kid+".pem"), data, 0o600)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	ring, err := LoadKeyDir(dir, "", time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := ring.Current().ID; got != "2024-06-01" {
+		t.Errorf("LoadKeyDir() current key = %v, want 2024-06-01", got)
+	}
+	if _, ok := ring.Verifier("2024-01-01"); !ok {
+		t.Errorf("LoadKeyDir() dropped the older key")
+	}
+
+	ring, err = LoadKeyDir(dir, "2024-01-01", time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := ring.Current().ID; got != "2024-01-01" {
+		t.Errorf("LoadKeyDir() current key = %v, want 2024-01-01", got)
+	}
+
+	_, err = LoadKeyDir(t.TempDir(), "", time.Hour)
+	if err == nil {
+		t.Errorf("LoadKeyDir() on an empty dir should fail")
+	}
+
+	// the grace period started when the current key was added, loading again later does not extend it
+	rotatedAt := time.Now().Add(-2 * time.Hour)
+	err = os.Chtimes(filepath.Join(dir, "2024-06-01.pem"), rotatedAt, rotatedAt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ring, err = LoadKeyDir(dir, "", time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := ring.Verifier("2024-01-01"); ok {
+		t.Errorf("LoadKeyDir() kept verifying with a key retired longer than the grace period ago")
+	}
+}
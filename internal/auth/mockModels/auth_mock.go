@@ -10,8 +10,9 @@ package auth
 
 import (
 	reflect "reflect"
+	time "time"
 
-	jwt "github.com/golang-jwt/jwt/v5"
+	auth "github.com/afthaab/job-portal/internal/auth"
 	gomock "go.uber.org/mock/gomock"
 )
 
@@ -39,7 +40,7 @@ func (m *MockAuthentication) EXPECT() *MockAuthenticationMockRecorder {
 }
 
 // GenerateAuthToken mocks base method.
-func (m *MockAuthentication) GenerateAuthToken(claims jwt.RegisteredClaims) (string, error) {
+func (m *MockAuthentication) GenerateAuthToken(claims auth.Claims) (string, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "GenerateAuthToken", claims)
 	ret0, _ := ret[0].(string)
@@ -53,11 +54,49 @@ func (mr *MockAuthenticationMockRecorder) GenerateAuthToken(claims any) *gomock.
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GenerateAuthToken", reflect.TypeOf((*MockAuthentication)(nil).GenerateAuthToken), claims)
 }
 
+// JWKS mocks base method.
+func (m *MockAuthentication) JWKS() auth.JWKS {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "JWKS")
+	ret0, _ := ret[0].(auth.JWKS)
+	return ret0
+}
+
+// JWKS indicates an expected call of JWKS.
+func (mr *MockAuthenticationMockRecorder) JWKS() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "JWKS", reflect.TypeOf((*MockAuthentication)(nil).JWKS))
+}
+
+// RevokeSubject mocks base method.
+func (m *MockAuthentication) RevokeSubject(subject string, issuedBefore, expiresAt time.Time) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "RevokeSubject", subject, issuedBefore, expiresAt)
+}
+
+// RevokeSubject indicates an expected call of RevokeSubject.
+func (mr *MockAuthenticationMockRecorder) RevokeSubject(subject, issuedBefore, expiresAt any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeSubject", reflect.TypeOf((*MockAuthentication)(nil).RevokeSubject), subject, issuedBefore, expiresAt)
+}
+
+// RevokeToken mocks base method.
+func (m *MockAuthentication) RevokeToken(jti string, expiresAt time.Time) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "RevokeToken", jti, expiresAt)
+}
+
+// RevokeToken indicates an expected call of RevokeToken.
+func (mr *MockAuthenticationMockRecorder) RevokeToken(jti, expiresAt any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeToken", reflect.TypeOf((*MockAuthentication)(nil).RevokeToken), jti, expiresAt)
+}
+
 // ValidateToken mocks base method.
-func (m *MockAuthentication) ValidateToken(token string) (jwt.RegisteredClaims, error) {
+func (m *MockAuthentication) ValidateToken(token string) (auth.Claims, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "ValidateToken", token)
-	ret0, _ := ret[0].(jwt.RegisteredClaims)
+	ret0, _ := ret[0].(auth.Claims)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
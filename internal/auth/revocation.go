@@ -0,0 +1,82 @@
+package auth
+
+import (
+	"sync"
+	"time"
+)
+
+// RevocationList holds the jti of access tokens that must be rejected before they expire,
+// and the subjects whose every token issued up to a point must be rejected, after a password reset
+type RevocationList interface {
+	Revoke(jti string, expiresAt time.Time)
+	IsRevoked(jti string) bool
+	RevokeSubject(subject string, issuedBefore time.Time, expiresAt time.Time)
+	IsSubjectRevoked(subject string, issuedAt time.Time) bool
+}
+
+type subjectRevocation struct {
+	issuedBefore time.Time
+	expiresAt    time.Time
+}
+
+// MemoryRevocationList keeps the revoked jti in memory until the token would have expired anyway
+type MemoryRevocationList struct {
+	mu       sync.RWMutex
+	revoked  map[string]time.Time
+	subjects map[string]subjectRevocation
+}
+
+func NewMemoryRevocationList() *MemoryRevocationList {
+	return &MemoryRevocationList{
+		revoked:  make(map[string]time.Time),
+		subjects: make(map[string]subjectRevocation),
+	}
+}
+
+func (l *MemoryRevocationList) Revoke(jti string, expiresAt time.Time) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	// expired entries are dropped here so the list never grows past the live tokens
+	now := time.Now()
+	for id, exp := range l.revoked {
+		if now.After(exp) {
+			delete(l.revoked, id)
+		}
+	}
+	l.revoked[jti] = expiresAt
+}
+
+func (l *MemoryRevocationList) IsRevoked(jti string) bool {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	_, ok := l.revoked[jti]
+	return ok
+}
+
+// RevokeSubject rejects the tokens of the subject issued at or before issuedBefore, expiresAt is when
+// the last of them expires and the entry can go. A later revocation of the same subject replaces the earlier one
+func (l *MemoryRevocationList) RevokeSubject(subject string, issuedBefore time.Time, expiresAt time.Time) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	for sub, r := range l.subjects {
+		if now.After(r.expiresAt) {
+			delete(l.subjects, sub)
+		}
+	}
+	if r, ok := l.subjects[subject]; ok && r.issuedBefore.After(issuedBefore) {
+		return
+	}
+	l.subjects[subject] = subjectRevocation{issuedBefore: issuedBefore, expiresAt: expiresAt}
+}
+
+// IsSubjectRevoked compares whole seconds because the issued at claim has no finer precision,
+// a token issued in the same second as the revocation is rejected too
+func (l *MemoryRevocationList) IsSubjectRevoked(subject string, issuedAt time.Time) bool {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	r, ok := l.subjects[subject]
+	return ok && issuedAt.Unix() <= r.issuedBefore.Unix()
+}
@@ -0,0 +1,56 @@
+package auth
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+func TestAuth_RevokeSubject(t *testing.T) {
+	ring, err := NewKeyRing(newTestKey(t, "kid"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	a, err := NewAuth(ring)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sign := func(subject string, issuedAt time.Time) string {
+		claims := testClaims()
+		claims.Subject = subject
+		claims.IssuedAt = jwt.NewNumericDate(issuedAt)
+		token, err := a.GenerateAuthToken(claims)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return token
+	}
+
+	reset := time.Now().Add(-time.Minute)
+	before := sign("1", reset.Add(-time.Minute))
+	sameSecond := sign("1", reset)
+	after := sign("1", reset.Add(2*time.Second))
+	otherUser := sign("2", reset.Add(-time.Minute))
+
+	a.RevokeSubject("1", reset, time.Now().Add(time.Hour))
+	// an older revocation arriving late does not let the tokens in between back in
+	a.RevokeSubject("1", reset.Add(-time.Hour), time.Now().Add(time.Hour))
+
+	for name, tt := range map[string]struct {
+		token   string
+		revoked bool
+	}{
+		"issued before":      {before, true},
+		"issued same second": {sameSecond, true},
+		"issued after":       {after, false},
+		"other subject":      {otherUser, false},
+	} {
+		_, err := a.ValidateToken(tt.token)
+		if errors.Is(err, ErrTokenRevoked) != tt.revoked {
+			t.Errorf("%s: ValidateToken() error = %v, want revoked %v", name, err, tt.revoked)
+		}
+	}
+}
@@ -3,16 +3,23 @@ package auth
 import (
 	"errors"
 	"fmt"
+	"time"
 
 	"github.com/golang-jwt/jwt/v5"
 )
 
-func (a *Auth) GenerateAuthToken(claims jwt.RegisteredClaims) (string, error) {
-	// creates a new token with signing menthod and claims
+// ErrTokenRevoked is returned by ValidateToken for a token that was revoked on logout or by a password reset
+var ErrTokenRevoked = errors.New("token has been revoked")
+
+func (a *Auth) GenerateAuthToken(claims Claims) (string, error) {
+	key := a.keys.Current()
+
+	// creates a new token with signing menthod and claims, the kid tells the verifier which key to use
 	tkn := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	tkn.Header["kid"] = key.ID
 
-	// signing our token with the private key
-	token, err := tkn.SignedString(a.privateKey)
+	// signing our token with the current private key
+	token, err := tkn.SignedString(key.PrivateKey)
 	if err != nil {
 		return "", fmt.Errorf("error in signing the token : %w", err)
 	}
@@ -20,21 +27,66 @@ func (a *Auth) GenerateAuthToken(claims jwt.RegisteredClaims) (string, error) {
 	return token, nil
 }
 
-func (a *Auth) ValidateToken(token string) (jwt.RegisteredClaims, error) {
-	// Parse the token with the registered claims.
-	var c jwt.RegisteredClaims
+func (a *Auth) ValidateToken(token string) (Claims, error) {
+	// Parse the token with the portal claims.
+	var c Claims
 	tkn, err := jwt.ParseWithClaims(token, &c, func(t *jwt.Token) (interface{}, error) {
-		return a.publickey, nil
-	})
+		kid, _ := t.Header["kid"].(string)
+		if kid == "" {
+			// tokens signed before the key ring was introduced carry no kid
+			return a.keys.Current().PublicKey, nil
+		}
+		key, ok := a.keys.Verifier(kid)
+		if !ok {
+			return nil, fmt.Errorf("unknown or expired signing key %q", kid)
+		}
+		return key.PublicKey, nil
+	}, jwt.WithValidMethods([]string{jwt.SigningMethodRS256.Alg()}))
 	if err != nil {
-		return jwt.RegisteredClaims{}, fmt.Errorf("error in parsing the token : %w", err)
+		return Claims{}, fmt.Errorf("error in parsing the token : %w", err)
 	}
 
 	// checking if the token is valid or not
 	if !tkn.Valid {
-		return jwt.RegisteredClaims{}, errors.New("token in not valid")
+		return Claims{}, errors.New("token in not valid")
+	}
+
+	// checking if the token was revoked on logout
+	if c.ID != "" && a.revoked != nil && a.revoked.IsRevoked(c.ID) {
+		return Claims{}, ErrTokenRevoked
+	}
+	// and if every token of the user was revoked when the password changed, a token without iat can not be placed
+	if a.revoked != nil && a.revoked.IsSubjectRevoked(c.Subject, issuedAt(c)) {
+		return Claims{}, ErrTokenRevoked
 	}
 
 	return c, nil
 
 }
+
+func (a *Auth) RevokeToken(jti string, expiresAt time.Time) {
+	if a.revoked == nil {
+		a.revoked = NewMemoryRevocationList()
+	}
+	a.revoked.Revoke(jti, expiresAt)
+}
+
+// RevokeSubject revokes every access token of the subject issued up to issuedBefore,
+// expiresAt is the latest those tokens expire
+func (a *Auth) RevokeSubject(subject string, issuedBefore time.Time, expiresAt time.Time) {
+	if a.revoked == nil {
+		a.revoked = NewMemoryRevocationList()
+	}
+	a.revoked.RevokeSubject(subject, issuedBefore, expiresAt)
+}
+
+func issuedAt(c Claims) time.Time {
+	if c.IssuedAt == nil {
+		return time.Time{}
+	}
+	return c.IssuedAt.Time
+}
+
+func (a *Auth) JWKS() JWKS {
+	return a.keys.JWKS()
+}
@@ -0,0 +1,567 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ConfigFileEnv names the environment variable holding the path of the optional yaml config file
+const ConfigFileEnv = "CONFIG_FILE"
+
+const redacted = "*****"
+
+type Config struct {
+	App       AppConfig       `yaml:"app"`
+	Database  DatabaseConfig  `yaml:"database"`
+	Auth      AuthConfig      `yaml:"auth"`
+	Tracing   TracingConfig   `yaml:"tracing"`
+	RateLimit RateLimitConfig `yaml:"rate_limit"`
+	Mail      MailConfig      `yaml:"mail"`
+	Password  PasswordConfig  `yaml:"password"`
+}
+
+type AppConfig struct {
+	Host            string        `yaml:"host"`
+	Port            int           `yaml:"port"`
+	ReadTimeout     time.Duration `yaml:"read_timeout"`
+	WriteTimeout    time.Duration `yaml:"write_timeout"`
+	IdleTimeout     time.Duration `yaml:"idle_timeout"`
+	ShutdownTimeout time.Duration `yaml:"shutdown_timeout"`
+	// ShutdownDelay is how long /readyz reports not ready before the server stops accepting connections,
+	// it gives the load balancer time to take the instance out of rotation
+	ShutdownDelay time.Duration `yaml:"shutdown_delay"`
+	// ProbeTimeout bounds every dependency check of /readyz
+	ProbeTimeout time.Duration `yaml:"probe_timeout"`
+	// TrustedProxies are the addresses allowed to set X-Forwarded-For, the client ip of a request
+	// from anywhere else is its remote address
+	TrustedProxies []string `yaml:"trusted_proxies"`
+	// MetricsAddr is the host:port /metrics is served on, apart from the api so the scrape is not public.
+	// Empty does not serve the metrics at all
+	MetricsAddr string `yaml:"metrics_addr"`
+}
+
+type DatabaseConfig struct {
+	Host        string        `yaml:"host"`
+	Port        int           `yaml:"port"`
+	User        string        `yaml:"user"`
+	Password    string        `yaml:"password"`
+	Name        string        `yaml:"name"`
+	SSLMode     string        `yaml:"sslmode"`
+	TimeZone    string        `yaml:"timezone"`
+	PingTimeout time.Duration `yaml:"ping_timeout"`
+	// the longest a read, a write and a keyword search may run, the client going away cancels them sooner
+	ReadTimeout   time.Duration `yaml:"read_timeout"`
+	WriteTimeout  time.Duration `yaml:"write_timeout"`
+	SearchTimeout time.Duration `yaml:"search_timeout"`
+}
+
+type AuthConfig struct {
+	PrivateKeyPath string `yaml:"private_key_path"`
+	PublicKeyPath  string `yaml:"public_key_path"`
+	// KeysDir holds one <kid>.pem per key, when it is set the key paths above are not used
+	KeysDir         string        `yaml:"keys_dir"`
+	CurrentKeyID    string        `yaml:"current_key_id"`
+	AccessTokenTTL  time.Duration `yaml:"access_token_ttl"`
+	RefreshTokenTTL time.Duration `yaml:"refresh_token_ttl"`
+	// RequireVerifiedEmail refuses sign in until the user verified the address, the link stays valid for EmailVerificationTTL
+	RequireVerifiedEmail bool          `yaml:"require_verified_email"`
+	EmailVerificationTTL time.Duration `yaml:"email_verification_ttl"`
+	// PasswordResetTTL is how long a password reset link stays valid
+	PasswordResetTTL time.Duration `yaml:"password_reset_ttl"`
+	// RevocationSyncInterval is how often the revoked tokens are read again from the database so a logout on one
+	// instance holds on the others, 0 only reads them on start and fits a single instance
+	RevocationSyncInterval time.Duration `yaml:"revocation_sync_interval"`
+}
+
+// exporters the spans can be sent to
+const (
+	ExporterNone   = "none"
+	ExporterStdout = "stdout"
+)
+
+type TracingConfig struct {
+	// Exporter is where the finished spans go, none still propagates the incoming trace context
+	Exporter    string  `yaml:"exporter"`
+	ServiceName string  `yaml:"service_name"`
+	SampleRatio float64 `yaml:"sample_ratio"`
+}
+
+// drivers the mail can be sent with
+const (
+	MailerLog  = "log"
+	MailerFile = "file"
+	MailerSMTP = "smtp"
+)
+
+type MailConfig struct {
+	// Driver is smtp, file to write every mail to Dir or log to only log them
+	Driver       string `yaml:"driver"`
+	From         string `yaml:"from"`
+	Dir          string `yaml:"dir"`
+	SMTPHost     string `yaml:"smtp_host"`
+	SMTPPort     int    `yaml:"smtp_port"`
+	SMTPUsername string `yaml:"smtp_username"`
+	SMTPPassword string `yaml:"smtp_password"`
+	// VerifyURL is the page the verification links open, the token is added as the token query parameter.
+	// Without it the mail holds the bare token
+	VerifyURL string `yaml:"verify_url"`
+	// ResetURL is the page the password reset links open, the same way
+	ResetURL string `yaml:"reset_url"`
+	// InviteURL is the page the company invitations link to, the id of the invitation is added as the invitation query parameter
+	InviteURL string `yaml:"invite_url"`
+}
+
+// algorithms the passwords can be hashed with
+const (
+	HasherBcrypt   = "bcrypt"
+	HasherArgon2id = "argon2id"
+)
+
+// PasswordConfig is the policy new passwords must meet and how they are hashed,
+// raising the cost or switching the hasher upgrades each stored hash on the next sign in of its user
+type PasswordConfig struct {
+	MinLength  int    `yaml:"min_length"`
+	MaxLength  int    `yaml:"max_length"`
+	Hasher     string `yaml:"hasher"`
+	BcryptCost int    `yaml:"bcrypt_cost"`
+	// Argon2Memory is in KiB
+	Argon2Time    int `yaml:"argon2_time"`
+	Argon2Memory  int `yaml:"argon2_memory"`
+	Argon2Threads int `yaml:"argon2_threads"`
+}
+
+// RateLimitConfig throttles sign in and sign up, a bucket holds Burst requests and gets one back every Interval
+type RateLimitConfig struct {
+	IPBurst         int           `yaml:"ip_burst"`
+	IPInterval      time.Duration `yaml:"ip_interval"`
+	AccountBurst    int           `yaml:"account_burst"`
+	AccountInterval time.Duration `yaml:"account_interval"`
+	// LockoutThreshold failed passwords in a row lock the account for LockoutBase,
+	// every further failure doubles the lock up to LockoutMax
+	LockoutThreshold int           `yaml:"lockout_threshold"`
+	LockoutBase      time.Duration `yaml:"lockout_base"`
+	LockoutMax       time.Duration `yaml:"lockout_max"`
+}
+
+// Default is the configuration used for everything that is not set in the file or the environment
+func Default() Config {
+	return Config{
+		App: AppConfig{
+			Port:            8080,
+			ReadTimeout:     10 * time.Second,
+			WriteTimeout:    30 * time.Second,
+			IdleTimeout:     120 * time.Second,
+			ShutdownTimeout: 10 * time.Second,
+			ProbeTimeout:    2 * time.Second,
+			MetricsAddr:     "127.0.0.1:9091",
+		},
+		Database: DatabaseConfig{
+			Host:          "localhost",
+			Port:          5432,
+			User:          "postgres",
+			Name:          "jportal",
+			SSLMode:       "disable",
+			TimeZone:      "Asia/Shanghai",
+			PingTimeout:   5 * time.Second,
+			ReadTimeout:   3 * time.Second,
+			WriteTimeout:  5 * time.Second,
+			SearchTimeout: 10 * time.Second,
+		},
+		Auth: AuthConfig{
+			PrivateKeyPath:       "private.pem",
+			PublicKeyPath:        "pubkey.pem",
+			AccessTokenTTL:       15 * time.Minute,
+			RefreshTokenTTL:      30 * 24 * time.Hour,
+			RequireVerifiedEmail: true,
+			EmailVerificationTTL: 24 * time.Hour,
+			PasswordResetTTL:     time.Hour,
+			// a revoked token works on the other instances for at most this long
+			RevocationSyncInterval: 30 * time.Second,
+		},
+		Tracing: TracingConfig{
+			Exporter:    ExporterNone,
+			ServiceName: "job-portal-api",
+			SampleRatio: 1,
+		},
+		Mail: MailConfig{
+			Driver:   MailerLog,
+			From:     "no-reply@localhost",
+			Dir:      "mail",
+			SMTPPort: 587,
+		},
+		Password: PasswordConfig{
+			MinLength:     8,
+			MaxLength:     128,
+			Hasher:        HasherArgon2id,
+			BcryptCost:    12,
+			Argon2Time:    2,
+			Argon2Memory:  19 * 1024,
+			Argon2Threads: 1,
+		},
+		RateLimit: RateLimitConfig{
+			IPBurst:          20,
+			IPInterval:       3 * time.Second,
+			AccountBurst:     5,
+			AccountInterval:  30 * time.Second,
+			LockoutThreshold: 5,
+			LockoutBase:      30 * time.Second,
+			LockoutMax:       15 * time.Minute,
+		},
+	}
+}
+
+// Load builds the configuration from the defaults, then the yaml file named by CONFIG_FILE if there is one
+// and then the environment variables, the later ones win
+func Load() (Config, error) {
+	cfg := Default()
+
+	if path := os.Getenv(ConfigFileEnv); path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return Config{}, fmt.Errorf("error in reading the config file : %w", err)
+		}
+		err = yaml.Unmarshal(data, &cfg)
+		if err != nil {
+			return Config{}, fmt.Errorf("error in parsing the config file : %w", err)
+		}
+	}
+
+	err := cfg.loadEnv()
+	if err != nil {
+		return Config{}, err
+	}
+
+	err = cfg.Validate()
+	if err != nil {
+		return Config{}, err
+	}
+	return cfg, nil
+}
+
+func (c *Config) loadEnv() error {
+	lookupString("APP_HOST", &c.App.Host)
+	lookupString("APP_METRICS_ADDR", &c.App.MetricsAddr)
+	lookupString("DB_HOST", &c.Database.Host)
+	lookupString("DB_USER", &c.Database.User)
+	lookupString("DB_PASSWORD", &c.Database.Password)
+	lookupString("DB_NAME", &c.Database.Name)
+	lookupString("DB_SSLMODE", &c.Database.SSLMode)
+	lookupString("DB_TIMEZONE", &c.Database.TimeZone)
+	lookupString("AUTH_PRIVATE_KEY_PATH", &c.Auth.PrivateKeyPath)
+	lookupString("AUTH_PUBLIC_KEY_PATH", &c.Auth.PublicKeyPath)
+	lookupString("AUTH_KEYS_DIR", &c.Auth.KeysDir)
+	lookupString("AUTH_CURRENT_KEY_ID", &c.Auth.CurrentKeyID)
+	lookupString("TRACING_EXPORTER", &c.Tracing.Exporter)
+	lookupString("TRACING_SERVICE_NAME", &c.Tracing.ServiceName)
+
+	lookupString("PASSWORD_HASHER", &c.Password.Hasher)
+	lookupString("MAIL_DRIVER", &c.Mail.Driver)
+	lookupString("MAIL_FROM", &c.Mail.From)
+	lookupString("MAIL_DIR", &c.Mail.Dir)
+	lookupString("MAIL_SMTP_HOST", &c.Mail.SMTPHost)
+	lookupString("MAIL_SMTP_USERNAME", &c.Mail.SMTPUsername)
+	lookupString("MAIL_SMTP_PASSWORD", &c.Mail.SMTPPassword)
+	lookupString("MAIL_VERIFY_URL", &c.Mail.VerifyURL)
+	lookupString("MAIL_RESET_URL", &c.Mail.ResetURL)
+	lookupString("MAIL_INVITE_URL", &c.Mail.InviteURL)
+	lookupList("APP_TRUSTED_PROXIES", &c.App.TrustedProxies)
+
+	err := lookupFloat("TRACING_SAMPLE_RATIO", &c.Tracing.SampleRatio)
+	if err != nil {
+		return err
+	}
+
+	err = lookupBool("AUTH_REQUIRE_VERIFIED_EMAIL", &c.Auth.RequireVerifiedEmail)
+	if err != nil {
+		return err
+	}
+
+	ints := map[string]*int{
+		"APP_PORT":                 &c.App.Port,
+		"DB_PORT":                  &c.Database.Port,
+		"RATE_LIMIT_IP_BURST":      &c.RateLimit.IPBurst,
+		"RATE_LIMIT_ACCOUNT_BURST": &c.RateLimit.AccountBurst,
+		"LOCKOUT_THRESHOLD":        &c.RateLimit.LockoutThreshold,
+		"PASSWORD_MIN_LENGTH":      &c.Password.MinLength,
+		"PASSWORD_MAX_LENGTH":      &c.Password.MaxLength,
+		"PASSWORD_BCRYPT_COST":     &c.Password.BcryptCost,
+		"PASSWORD_ARGON2_TIME":     &c.Password.Argon2Time,
+		"PASSWORD_ARGON2_MEMORY":   &c.Password.Argon2Memory,
+		"PASSWORD_ARGON2_THREADS":  &c.Password.Argon2Threads,
+	}
+	for name, field := range ints {
+		err = lookupInt(name, field)
+		if err != nil {
+			return err
+		}
+	}
+
+	durations := map[string]*time.Duration{
+		"APP_READ_TIMEOUT":              &c.App.ReadTimeout,
+		"APP_WRITE_TIMEOUT":             &c.App.WriteTimeout,
+		"APP_IDLE_TIMEOUT":              &c.App.IdleTimeout,
+		"APP_SHUTDOWN_TIMEOUT":          &c.App.ShutdownTimeout,
+		"APP_SHUTDOWN_DELAY":            &c.App.ShutdownDelay,
+		"APP_PROBE_TIMEOUT":             &c.App.ProbeTimeout,
+		"DB_PING_TIMEOUT":               &c.Database.PingTimeout,
+		"DB_READ_TIMEOUT":               &c.Database.ReadTimeout,
+		"DB_WRITE_TIMEOUT":              &c.Database.WriteTimeout,
+		"DB_SEARCH_TIMEOUT":             &c.Database.SearchTimeout,
+		"AUTH_ACCESS_TOKEN_TTL":         &c.Auth.AccessTokenTTL,
+		"AUTH_REFRESH_TOKEN_TTL":        &c.Auth.RefreshTokenTTL,
+		"AUTH_EMAIL_VERIFICATION_TTL":   &c.Auth.EmailVerificationTTL,
+		"AUTH_PASSWORD_RESET_TTL":       &c.Auth.PasswordResetTTL,
+		"AUTH_REVOCATION_SYNC_INTERVAL": &c.Auth.RevocationSyncInterval,
+		"RATE_LIMIT_IP_INTERVAL":        &c.RateLimit.IPInterval,
+		"RATE_LIMIT_ACCOUNT_INTERVAL":   &c.RateLimit.AccountInterval,
+		"LOCKOUT_BASE":                  &c.RateLimit.LockoutBase,
+		"LOCKOUT_MAX":                   &c.RateLimit.LockoutMax,
+	}
+	for name, field := range durations {
+		err = lookupDuration(name, field)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c Config) Validate() error {
+	var errs []string
+	if c.App.Port < 1 || c.App.Port > 65535 {
+		errs = append(errs, "app port must be between 1 and 65535")
+	}
+	if c.App.ReadTimeout <= 0 || c.App.WriteTimeout <= 0 || c.App.IdleTimeout <= 0 || c.App.ShutdownTimeout <= 0 {
+		errs = append(errs, "app timeouts must be positive")
+	}
+	if c.App.ShutdownDelay < 0 || c.App.ProbeTimeout <= 0 {
+		errs = append(errs, "app shutdown delay can not be negative and the probe timeout must be positive")
+	}
+	if c.App.MetricsAddr != "" {
+		_, port, err := net.SplitHostPort(c.App.MetricsAddr)
+		if err != nil || port == "" || port == strconv.Itoa(c.App.Port) {
+			errs = append(errs, "app metrics addr must be a host:port apart from the api port")
+		}
+	}
+	if c.Database.Host == "" || c.Database.User == "" || c.Database.Name == "" {
+		errs = append(errs, "database host, user and name are required")
+	}
+	if c.Database.Port < 1 || c.Database.Port > 65535 {
+		errs = append(errs, "database port must be between 1 and 65535")
+	}
+	switch c.Database.SSLMode {
+	case "disable", "allow", "prefer", "require", "verify-ca", "verify-full":
+	default:
+		errs = append(errs, fmt.Sprintf("unknown database sslmode %q", c.Database.SSLMode))
+	}
+	if c.Database.PingTimeout <= 0 || c.Database.ReadTimeout <= 0 || c.Database.WriteTimeout <= 0 || c.Database.SearchTimeout <= 0 {
+		errs = append(errs, "database timeouts must be positive")
+	}
+	if c.Auth.KeysDir == "" && (c.Auth.PrivateKeyPath == "" || c.Auth.PublicKeyPath == "") {
+		errs = append(errs, "auth keys dir or private and public key paths are required")
+	}
+	if c.Auth.AccessTokenTTL <= 0 || c.Auth.RefreshTokenTTL <= c.Auth.AccessTokenTTL {
+		errs = append(errs, "auth access token ttl must be positive and shorter than the refresh token ttl")
+	}
+	switch c.Tracing.Exporter {
+	case ExporterNone, ExporterStdout:
+	default:
+		errs = append(errs, fmt.Sprintf("unknown tracing exporter %q", c.Tracing.Exporter))
+	}
+	if c.Tracing.SampleRatio < 0 || c.Tracing.SampleRatio > 1 {
+		errs = append(errs, "tracing sample ratio must be between 0 and 1")
+	}
+	if c.Auth.EmailVerificationTTL <= 0 || c.Auth.PasswordResetTTL <= 0 {
+		errs = append(errs, "auth email verification and password reset ttl must be positive")
+	}
+	if c.Auth.RevocationSyncInterval < 0 {
+		errs = append(errs, "auth revocation sync interval can not be negative")
+	}
+	switch c.Mail.Driver {
+	case MailerLog:
+	case MailerFile:
+		if c.Mail.Dir == "" {
+			errs = append(errs, "mail dir is required by the file driver")
+		}
+	case MailerSMTP:
+		if c.Mail.SMTPHost == "" || c.Mail.SMTPPort < 1 || c.Mail.SMTPPort > 65535 {
+			errs = append(errs, "mail smtp host and a port between 1 and 65535 are required by the smtp driver")
+		}
+	default:
+		errs = append(errs, fmt.Sprintf("unknown mail driver %q", c.Mail.Driver))
+	}
+	if c.Mail.From == "" {
+		errs = append(errs, "mail from address is required")
+	}
+	links := []struct {
+		name string
+		url  string
+	}{
+		{"verify", c.Mail.VerifyURL},
+		{"reset", c.Mail.ResetURL},
+		{"invite", c.Mail.InviteURL},
+	}
+	for _, link := range links {
+		if link.url == "" {
+			continue
+		}
+		u, err := url.Parse(link.url)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			errs = append(errs, fmt.Sprintf("mail %s url must be an absolute http or https url", link.name))
+		}
+	}
+	p := c.Password
+	if p.MinLength < 8 || p.MaxLength < p.MinLength {
+		errs = append(errs, "password min length must be at least 8 and the max length can not be below it")
+	}
+	switch p.Hasher {
+	case HasherBcrypt:
+		if p.BcryptCost < 10 || p.BcryptCost > 31 {
+			errs = append(errs, "password bcrypt cost must be between 10 and 31")
+		}
+	case HasherArgon2id:
+		if p.Argon2Time < 1 || p.Argon2Memory < 8*1024 || p.Argon2Threads < 1 || p.Argon2Threads > 255 {
+			errs = append(errs, "password argon2 time must be positive, memory at least 8192 KiB and threads between 1 and 255")
+		}
+	default:
+		errs = append(errs, fmt.Sprintf("unknown password hasher %q", p.Hasher))
+	}
+	r := c.RateLimit
+	if r.IPBurst < 1 || r.AccountBurst < 1 || r.IPInterval <= 0 || r.AccountInterval <= 0 {
+		errs = append(errs, "rate limit bursts and intervals must be positive")
+	}
+	if r.LockoutThreshold < 1 || r.LockoutBase <= 0 || r.LockoutMax < r.LockoutBase {
+		errs = append(errs, "lockout threshold and base must be positive and the max can not be below the base")
+	}
+	if len(errs) > 0 {
+		return errors.New("invalid config : " + strings.Join(errs, ", "))
+	}
+	return nil
+}
+
+// Addr is the address the api listens on
+func (a AppConfig) Addr() string {
+	return fmt.Sprintf("%s:%d", a.Host, a.Port)
+}
+
+// DSN is the postgres connection string, values are quoted so passwords can hold spaces and quotes
+func (d DatabaseConfig) DSN() string {
+	params := []struct {
+		key   string
+		value string
+	}{
+		{"host", d.Host},
+		{"user", d.User},
+		{"password", d.Password},
+		{"dbname", d.Name},
+		{"port", strconv.Itoa(d.Port)},
+		{"sslmode", d.SSLMode},
+		{"TimeZone", d.TimeZone},
+	}
+	parts := make([]string, 0, len(params))
+	for _, p := range params {
+		if p.value == "" {
+			continue
+		}
+		parts = append(parts, fmt.Sprintf("%s=%s", p.key, quote(p.value)))
+	}
+	return strings.Join(parts, " ")
+}
+
+// Redacted returns a copy of the config that is safe to log
+func (c Config) Redacted() Config {
+	if c.Database.Password != "" {
+		c.Database.Password = redacted
+	}
+	if c.Mail.SMTPPassword != "" {
+		c.Mail.SMTPPassword = redacted
+	}
+	return c
+}
+
+func quote(value string) string {
+	value = strings.ReplaceAll(value, `\`, `\\`)
+	value = strings.ReplaceAll(value, `'`, `\'`)
+	return "'" + value + "'"
+}
+
+func lookupString(name string, field *string) {
+	if value, ok := os.LookupEnv(name); ok {
+		*field = value
+	}
+}
+
+// lookupList reads a comma separated list, an empty variable clears the list
+func lookupList(name string, field *[]string) {
+	value, ok := os.LookupEnv(name)
+	if !ok {
+		return
+	}
+	var list []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	*field = list
+}
+
+func lookupBool(name string, field *bool) error {
+	value, ok := os.LookupEnv(name)
+	if !ok {
+		return nil
+	}
+	parsed, err := strconv.ParseBool(value)
+	if err != nil {
+		return fmt.Errorf("error in parsing %s : %w", name, err)
+	}
+	*field = parsed
+	return nil
+}
+
+func lookupInt(name string, field *int) error {
+	value, ok := os.LookupEnv(name)
+	if !ok {
+		return nil
+	}
+	parsed, err := strconv.Atoi(value)
+	if err != nil {
+		return fmt.Errorf("error in parsing %s : %w", name, err)
+	}
+	*field = parsed
+	return nil
+}
+
+func lookupFloat(name string, field *float64) error {
+	value, ok := os.LookupEnv(name)
+	if !ok {
+		return nil
+	}
+	parsed, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return fmt.Errorf("error in parsing %s : %w", name, err)
+	}
+	*field = parsed
+	return nil
+}
+
+func lookupDuration(name string, field *time.Duration) error {
+	value, ok := os.LookupEnv(name)
+	if !ok {
+		return nil
+	}
+	parsed, err := time.ParseDuration(value)
+	if err != nil {
+		return fmt.Errorf("error in parsing %s : %w", name, err)
+	}
+	*field = parsed
+	return nil
+}
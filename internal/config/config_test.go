@@ -0,0 +1,173 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLoad(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "config.yaml")
+	err := os.WriteFile(file, []byte("app:\n  port: 9090\n  read_timeout: 15s\ndatabase:\n  password: from-file\n"), 0o600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		env     map[string]string
+		check   func(Config) bool
+		wantErr bool
+	}{
+		{
+			name: "defaults",
+			env:  map[string]string{},
+			check: func(c Config) bool {
+				return c.App.Port == 8080 && c.Database.Host == "localhost" && c.App.MetricsAddr == "127.0.0.1:9091"
+			},
+		},
+		{
+			name: "file overrides defaults",
+			env:  map[string]string{ConfigFileEnv: file},
+			check: func(c Config) bool {
+				return c.App.Port == 9090 && c.App.ReadTimeout == 15*time.Second && c.Database.Password == "from-file" && c.App.WriteTimeout == 30*time.Second
+			},
+		},
+		{
+			name:  "environment overrides file",
+			env:   map[string]string{ConfigFileEnv: file, "APP_PORT": "7070", "DB_PASSWORD": "from-env"},
+			check: func(c Config) bool { return c.App.Port == 7070 && c.Database.Password == "from-env" },
+		},
+		{
+			name:    "missing file",
+			env:     map[string]string{ConfigFileEnv: filepath.Join(dir, "missing.yaml")},
+			wantErr: true,
+		},
+		{
+			name:    "bad duration",
+			env:     map[string]string{"APP_READ_TIMEOUT": "soon"},
+			wantErr: true,
+		},
+		{
+			name:    "port out of range",
+			env:     map[string]string{"APP_PORT": "70000"},
+			wantErr: true,
+		},
+		{
+			name:    "negative shutdown delay",
+			env:     map[string]string{"APP_SHUTDOWN_DELAY": "-1s"},
+			wantErr: true,
+		},
+		{
+			name:  "stdout tracing",
+			env:   map[string]string{"TRACING_EXPORTER": "stdout", "TRACING_SAMPLE_RATIO": "0.25"},
+			check: func(c Config) bool { return c.Tracing.Exporter == ExporterStdout && c.Tracing.SampleRatio == 0.25 },
+		},
+		{
+			name:    "unknown tracing exporter",
+			env:     map[string]string{"TRACING_EXPORTER": "carrier-pigeon"},
+			wantErr: true,
+		},
+		{
+			name: "trusted proxies and rate limits",
+			env:  map[string]string{"APP_TRUSTED_PROXIES": "10.0.0.1, 10.1.0.0/16", "RATE_LIMIT_IP_BURST": "50", "LOCKOUT_MAX": "1h"},
+			check: func(c Config) bool {
+				return strings.Join(c.App.TrustedProxies, ";") == "10.0.0.1;10.1.0.0/16" && c.RateLimit.IPBurst == 50 && c.RateLimit.LockoutMax == time.Hour
+			},
+		},
+		{
+			name:    "lockout max below base",
+			env:     map[string]string{"LOCKOUT_MAX": "1s"},
+			wantErr: true,
+		},
+		{
+			name: "smtp mail",
+			env:  map[string]string{"MAIL_DRIVER": "smtp", "MAIL_SMTP_HOST": "smtp.example.com", "AUTH_REQUIRE_VERIFIED_EMAIL": "false"},
+			check: func(c Config) bool {
+				return c.Mail.Driver == MailerSMTP && c.Mail.SMTPPort == 587 && !c.Auth.RequireVerifiedEmail
+			},
+		},
+		{
+			name:    "smtp mail without a host",
+			env:     map[string]string{"MAIL_DRIVER": "smtp"},
+			wantErr: true,
+		},
+		{
+			name:    "relative verify url",
+			env:     map[string]string{"MAIL_VERIFY_URL": "/verify"},
+			wantErr: true,
+		},
+		{
+			name:  "bcrypt hasher",
+			env:   map[string]string{"PASSWORD_HASHER": "bcrypt", "PASSWORD_BCRYPT_COST": "13"},
+			check: func(c Config) bool { return c.Password.Hasher == HasherBcrypt && c.Password.BcryptCost == 13 },
+		},
+		{
+			name:    "password min length below 8",
+			env:     map[string]string{"PASSWORD_MIN_LENGTH": "4"},
+			wantErr: true,
+		},
+		{
+			name:  "metrics on another address",
+			env:   map[string]string{"APP_METRICS_ADDR": ":9100"},
+			check: func(c Config) bool { return c.App.MetricsAddr == ":9100" },
+		},
+		{
+			name:    "metrics on the api port",
+			env:     map[string]string{"APP_METRICS_ADDR": ":8080"},
+			wantErr: true,
+		},
+		{
+			name:    "unknown sslmode",
+			env:     map[string]string{"DB_SSLMODE": "sometimes"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, name := range []string{ConfigFileEnv, "APP_PORT", "APP_READ_TIMEOUT", "APP_SHUTDOWN_DELAY", "DB_PASSWORD", "DB_SSLMODE", "TRACING_EXPORTER", "TRACING_SAMPLE_RATIO", "APP_TRUSTED_PROXIES", "RATE_LIMIT_IP_BURST", "LOCKOUT_MAX", "MAIL_DRIVER", "MAIL_SMTP_HOST", "MAIL_VERIFY_URL", "AUTH_REQUIRE_VERIFIED_EMAIL", "PASSWORD_HASHER", "PASSWORD_BCRYPT_COST", "PASSWORD_MIN_LENGTH", "APP_METRICS_ADDR"} {
+				t.Setenv(name, "")
+				os.Unsetenv(name)
+			}
+			for name, value := range tt.env {
+				t.Setenv(name, value)
+			}
+			got, err := Load()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Load() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if tt.check != nil && !tt.check(got) {
+				t.Errorf("Load() = %+v", got)
+			}
+		})
+	}
+}
+
+func TestDatabaseConfig_DSN(t *testing.T) {
+	cfg := Default().Database
+	cfg.Password = `it's secret`
+	want := `host='localhost' user='postgres' password='it\'s secret' dbname='jportal' port='5432' sslmode='disable' TimeZone='Asia/Shanghai'`
+	if got := cfg.DSN(); got != want {
+		t.Errorf("DatabaseConfig.DSN() = %v, want %v", got, want)
+	}
+}
+
+func TestConfig_Redacted(t *testing.T) {
+	cfg := Default()
+	cfg.Database.Password = "12345"
+	cfg.Mail.SMTPPassword = "67890"
+	got := cfg.Redacted()
+	if strings.Contains(got.Database.DSN(), "12345") {
+		t.Errorf("Config.Redacted() leaked the password: %v", got.Database.DSN())
+	}
+	if got.Mail.SMTPPassword == "67890" {
+		t.Errorf("Config.Redacted() leaked the smtp password")
+	}
+	if cfg.Database.Password != "12345" {
+		t.Errorf("Config.Redacted() changed the original config")
+	}
+}
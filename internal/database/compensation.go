@@ -0,0 +1,231 @@
+package database
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/afthaab/job-portal/internal/models"
+	"gorm.io/gorm"
+)
+
+// the portal only listed jobs in india before the salary was structured,
+// so amounts without a currency are rupees and amounts without a period are yearly packages
+const (
+	legacyDefaultCurrency  = "INR"
+	legacyDefaultPayPeriod = models.PayYearly
+)
+
+var (
+	legacyAmount       = regexp.MustCompile(`(\d[\d,]*(?:\.\d+)?)\s*(k|lpa|lakhs?|l|cr|crores?)?\b`)
+	legacyNoticePeriod = regexp.MustCompile(`^(\d+)\s*(days?|d|weeks?|w|months?|m)?$`)
+
+	legacyMultipliers = map[string]float64{
+		"k":      1000,
+		"l":      100000,
+		"lpa":    100000,
+		"lakh":   100000,
+		"lakhs":  100000,
+		"cr":     10000000,
+		"crore":  10000000,
+		"crores": 10000000,
+	}
+	legacyCurrencySymbols = map[string]string{
+		"₹": "INR",
+		"$": "USD",
+		"€": "EUR",
+		"£": "GBP",
+	}
+	legacyCurrencyCodes = map[string]string{
+		"rs":  "INR",
+		"inr": "INR",
+		"usd": "USD",
+		"eur": "EUR",
+		"gbp": "GBP",
+	}
+	legacyPayPeriods = map[string]string{
+		"hour":    models.PayHourly,
+		"hourly":  models.PayHourly,
+		"hr":      models.PayHourly,
+		"month":   models.PayMonthly,
+		"monthly": models.PayMonthly,
+		"mo":      models.PayMonthly,
+		"pm":      models.PayMonthly,
+		"year":    models.PayYearly,
+		"yearly":  models.PayYearly,
+		"yr":      models.PayYearly,
+		"annum":   models.PayYearly,
+		"annual":  models.PayYearly,
+		"pa":      models.PayYearly,
+		"lpa":     models.PayYearly,
+	}
+)
+
+// migrateCompensation moves the free text salary and notice period of the jobs into the structured columns,
+// the jobs that could not be parsed are flagged for review and the old text is kept in the legacy columns
+func migrateCompensation(db *gorm.DB) error {
+	if !db.Migrator().HasColumn("jobs", "salary") {
+		return nil
+	}
+
+	type legacyJob struct {
+		ID           uint
+		Salary       *string
+		NoticePeriod *string
+	}
+	var legacyJobs []legacyJob
+	err := db.Table("jobs").Select("id, salary, notice_period").Find(&legacyJobs).Error
+	if err != nil {
+		return fmt.Errorf("error in reading the legacy salaries : %w", err)
+	}
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		for _, job := range legacyJobs {
+			compensation, salaryOk := parseLegacySalary(valueOf(job.Salary))
+			days, noticeOk := parseLegacyNoticePeriod(valueOf(job.NoticePeriod))
+
+			updates := map[string]interface{}{
+				"needs_review": !salaryOk || !noticeOk,
+			}
+			if salaryOk {
+				updates["salary_min_amount"] = compensation.MinAmount
+				updates["salary_max_amount"] = compensation.MaxAmount
+				updates["salary_currency"] = compensation.Currency
+				updates["salary_pay_period"] = compensation.PayPeriod
+				updates["salary_equity"] = compensation.Equity
+			}
+			if noticeOk {
+				updates["notice_period_days"] = days
+			}
+
+			err := tx.Table("jobs").Where("id = ?", job.ID).Updates(updates).Error
+			if err != nil {
+				return fmt.Errorf("error in migrating the salary of job %d : %w", job.ID, err)
+			}
+		}
+
+		err := tx.Migrator().RenameColumn("jobs", "salary", "legacy_salary")
+		if err != nil {
+			return fmt.Errorf("error in renaming the legacy salary column : %w", err)
+		}
+		err = tx.Migrator().RenameColumn("jobs", "notice_period", "legacy_notice_period")
+		if err != nil {
+			return fmt.Errorf("error in renaming the legacy notice period column : %w", err)
+		}
+		return nil
+	})
+}
+
+// revertCompensation brings the free text columns back under their old names, the structured columns
+// are left for the first migration to drop
+func revertCompensation(db *gorm.DB) error {
+	if !db.Migrator().HasColumn("jobs", "legacy_salary") {
+		return nil
+	}
+	err := db.Migrator().RenameColumn("jobs", "legacy_salary", "salary")
+	if err != nil {
+		return fmt.Errorf("error in renaming the legacy salary column back : %w", err)
+	}
+	err = db.Migrator().RenameColumn("jobs", "legacy_notice_period", "notice_period")
+	if err != nil {
+		return fmt.Errorf("error in renaming the legacy notice period column back : %w", err)
+	}
+	return nil
+}
+
+// parseLegacySalary understands texts like "10000", "₹ 5-8 lpa", "$40/hr" or "50k - 60k per month + equity"
+func parseLegacySalary(text string) (models.Compensation, bool) {
+	text = strings.ToLower(strings.TrimSpace(text))
+	if text == "" {
+		return models.Compensation{}, false
+	}
+
+	compensation := models.Compensation{
+		Currency:  legacyDefaultCurrency,
+		PayPeriod: legacyDefaultPayPeriod,
+	}
+	for symbol, currency := range legacyCurrencySymbols {
+		if strings.Contains(text, symbol) {
+			compensation.Currency = currency
+		}
+	}
+	words := strings.FieldsFunc(text, func(r rune) bool {
+		return !(r >= 'a' && r <= 'z')
+	})
+	for _, word := range words {
+		if currency, ok := legacyCurrencyCodes[word]; ok {
+			compensation.Currency = currency
+		}
+		if period, ok := legacyPayPeriods[word]; ok {
+			compensation.PayPeriod = period
+		}
+		if word == "equity" || word == "esop" || word == "esops" || word == "stock" {
+			compensation.Equity = true
+		}
+	}
+
+	matches := legacyAmount.FindAllStringSubmatch(text, -1)
+	if len(matches) == 0 || len(matches) > 2 {
+		return models.Compensation{}, false
+	}
+
+	amounts := make([]int64, 0, len(matches))
+	for _, match := range matches {
+		amount, err := strconv.ParseFloat(strings.ReplaceAll(match[1], ",", ""), 64)
+		if err != nil {
+			return models.Compensation{}, false
+		}
+		if multiplier, ok := legacyMultipliers[match[2]]; ok {
+			amount *= multiplier
+		}
+		amounts = append(amounts, int64(math.Round(amount)))
+	}
+
+	// "5-8 lpa" puts the unit on the second amount only
+	if len(matches) == 2 && matches[0][2] == "" && matches[1][2] != "" {
+		amounts[0] = int64(math.Round(float64(amounts[0]) * legacyMultipliers[matches[1][2]]))
+	}
+
+	compensation.MinAmount = amounts[0]
+	compensation.MaxAmount = amounts[len(amounts)-1]
+	if compensation.MinAmount > compensation.MaxAmount {
+		return models.Compensation{}, false
+	}
+	return compensation, true
+}
+
+// parseLegacyNoticePeriod understands texts like "30", "45 days", "2 weeks", "3 months" or "immediate"
+func parseLegacyNoticePeriod(text string) (int, bool) {
+	text = strings.ToLower(strings.TrimSpace(text))
+	switch text {
+	case "":
+		return 0, false
+	case "immediate", "immediately", "none":
+		return 0, true
+	}
+
+	match := legacyNoticePeriod.FindStringSubmatch(text)
+	if match == nil {
+		return 0, false
+	}
+	days, err := strconv.Atoi(match[1])
+	if err != nil {
+		return 0, false
+	}
+	switch {
+	case strings.HasPrefix(match[2], "w"):
+		days *= 7
+	case strings.HasPrefix(match[2], "m"):
+		days *= 30
+	}
+	return days, true
+}
+
+func valueOf(text *string) string {
+	if text == nil {
+		return ""
+	}
+	return *text
+}
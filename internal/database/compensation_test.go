@@ -0,0 +1,90 @@
+package database
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/afthaab/job-portal/internal/models"
+)
+
+func Test_parseLegacySalary(t *testing.T) {
+	tests := []struct {
+		text   string
+		want   models.Compensation
+		wantOk bool
+	}{
+		{
+			text:   "10000",
+			want:   models.Compensation{MinAmount: 10000, MaxAmount: 10000, Currency: "INR", PayPeriod: models.PayYearly},
+			wantOk: true,
+		},
+		{
+			text:   "₹ 5-8 LPA",
+			want:   models.Compensation{MinAmount: 500000, MaxAmount: 800000, Currency: "INR", PayPeriod: models.PayYearly},
+			wantOk: true,
+		},
+		{
+			text:   "1,00,000 per month",
+			want:   models.Compensation{MinAmount: 100000, MaxAmount: 100000, Currency: "INR", PayPeriod: models.PayMonthly},
+			wantOk: true,
+		},
+		{
+			text:   "$40/hr",
+			want:   models.Compensation{MinAmount: 40, MaxAmount: 40, Currency: "USD", PayPeriod: models.PayHourly},
+			wantOk: true,
+		},
+		{
+			text:   "USD 50k - 60k yearly + equity",
+			want:   models.Compensation{MinAmount: 50000, MaxAmount: 60000, Currency: "USD", PayPeriod: models.PayYearly, Equity: true},
+			wantOk: true,
+		},
+		{
+			text:   "competitive",
+			wantOk: false,
+		},
+		{
+			text:   "20000 - 10000",
+			wantOk: false,
+		},
+		{
+			text:   "",
+			wantOk: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			got, ok := parseLegacySalary(tt.text)
+			if ok != tt.wantOk {
+				t.Errorf("parseLegacySalary() ok = %v, want %v", ok, tt.wantOk)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseLegacySalary() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_parseLegacyNoticePeriod(t *testing.T) {
+	tests := []struct {
+		text   string
+		want   int
+		wantOk bool
+	}{
+		{text: "30", want: 30, wantOk: true},
+		{text: "45 days", want: 45, wantOk: true},
+		{text: "2 weeks", want: 14, wantOk: true},
+		{text: "3 Months", want: 90, wantOk: true},
+		{text: "immediate", want: 0, wantOk: true},
+		{text: "negotiable", want: 0, wantOk: false},
+		{text: "", want: 0, wantOk: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			got, ok := parseLegacyNoticePeriod(tt.text)
+			if ok != tt.wantOk || got != tt.want {
+				t.Errorf("parseLegacyNoticePeriod() = %v, %v, want %v, %v", got, ok, tt.want, tt.wantOk)
+			}
+		})
+	}
+}
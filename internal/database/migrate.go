@@ -0,0 +1,378 @@
+package database
+
+import (
+	"context"
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// migrationFiles are the sql migrations compiled into the binary, each version has an up and a down file
+//
+//go:embed migrations/*.sql
+var migrationFiles embed.FS
+
+// MigrationsDir is where the migrate create command writes new files, relative to the root of the repository
+const MigrationsDir = "internal/database/migrations"
+
+// migrationLockID is the key of the postgres advisory lock held while a migration runs,
+// so two instances starting at the same time do not apply the same migration twice
+const migrationLockID = 7259146581
+
+var migrationFileName = regexp.MustCompile(`^(\d+)_([a-z0-9_]+)\.(up|down)\.sql$`)
+
+// ErrSchemaBehind is returned by Check when the binary knows migrations the database has not applied yet
+var ErrSchemaBehind = errors.New("database schema is behind")
+
+// Migration is one versioned change of the schema, most of them are sql files and the few
+// backfills that can not be written in sql are go functions
+type Migration struct {
+	Version int64
+	Name    string
+	Up      func(tx *gorm.DB) error
+	Down    func(tx *gorm.DB) error
+}
+
+// MigrationStatus is a migration known to the binary or recorded in the database,
+// AppliedAt is nil while the migration is pending
+type MigrationStatus struct {
+	Version   int64
+	Name      string
+	AppliedAt *time.Time
+	// Unknown is set on the migrations the database has applied but this binary does not have
+	Unknown bool
+}
+
+// goMigrations are the migrations written in go, their versions share one sequence with the sql files
+var goMigrations = []Migration{
+	{
+		Version: 3,
+		Name:    "structure_legacy_salaries",
+		Up:      migrateCompensation,
+		Down:    revertCompensation,
+	},
+}
+
+type schemaMigration struct {
+	Version   int64
+	Name      string
+	AppliedAt time.Time
+}
+
+type Migrator struct {
+	db         *gorm.DB
+	migrations []Migration
+}
+
+// NewMigrator returns a migrator for the migrations built into the binary
+func NewMigrator(db *gorm.DB) (*Migrator, error) {
+	migrations, err := loadMigrations(migrationFiles, "migrations", goMigrations)
+	if err != nil {
+		return nil, err
+	}
+	return &Migrator{
+		db:         db,
+		migrations: migrations,
+	}, nil
+}
+
+// loadMigrations reads the sql files of dir and merges them with the go migrations, sorted by version
+func loadMigrations(fsys fs.FS, dir string, extra []Migration) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return nil, fmt.Errorf("error in reading the migrations : %w", err)
+	}
+
+	byVersion := make(map[int64]*Migration)
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		match := migrationFileName.FindStringSubmatch(entry.Name())
+		if match == nil {
+			return nil, fmt.Errorf("migration file %s is not named <version>_<name>.<up|down>.sql", entry.Name())
+		}
+		version, err := strconv.ParseInt(match[1], 10, 64)
+		if err != nil || version < 1 {
+			return nil, fmt.Errorf("migration file %s has an invalid version", entry.Name())
+		}
+		content, err := fs.ReadFile(fsys, path.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("error in reading the migration %s : %w", entry.Name(), err)
+		}
+
+		m, ok := byVersion[version]
+		if !ok {
+			m = &Migration{Version: version, Name: match[2]}
+			byVersion[version] = m
+		}
+		if m.Name != match[2] {
+			return nil, fmt.Errorf("migration %d is named both %s and %s", version, m.Name, match[2])
+		}
+		if match[3] == "up" {
+			m.Up = execSQL(string(content))
+		} else {
+			m.Down = execSQL(string(content))
+		}
+	}
+
+	for _, m := range extra {
+		if _, ok := byVersion[m.Version]; ok {
+			return nil, fmt.Errorf("migration %d is defined twice", m.Version)
+		}
+		m := m
+		byVersion[m.Version] = &m
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		if m.Up == nil || m.Down == nil {
+			return nil, fmt.Errorf("migration %d %s needs both an up and a down", m.Version, m.Name)
+		}
+		migrations = append(migrations, *m)
+	}
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+	return migrations, nil
+}
+
+// execSQL runs a whole migration file at once, without arguments postgres takes several statements in one go
+func execSQL(statements string) func(tx *gorm.DB) error {
+	return func(tx *gorm.DB) error {
+		return tx.Exec(statements).Error
+	}
+}
+
+func (m *Migrator) createTable(ctx context.Context) error {
+	err := m.db.WithContext(ctx).Exec(`CREATE TABLE IF NOT EXISTS schema_migrations (
+		version bigint PRIMARY KEY,
+		name text NOT NULL,
+		applied_at timestamptz NOT NULL DEFAULT now()
+	)`).Error
+	if err != nil {
+		return fmt.Errorf("error in creating the schema_migrations table : %w", err)
+	}
+	return nil
+}
+
+// applied returns the migrations recorded in the database, a database without the table has none
+func (m *Migrator) applied(ctx context.Context) (map[int64]schemaMigration, error) {
+	applied := make(map[int64]schemaMigration)
+	db := m.db.WithContext(ctx)
+	if !db.Migrator().HasTable("schema_migrations") {
+		return applied, nil
+	}
+
+	var rows []schemaMigration
+	err := db.Table("schema_migrations").Order("version").Find(&rows).Error
+	if err != nil {
+		return nil, fmt.Errorf("error in reading the applied migrations : %w", err)
+	}
+	for _, row := range rows {
+		applied[row.Version] = row
+	}
+	return applied, nil
+}
+
+// Up applies every pending migration in order, each one in its own transaction
+func (m *Migrator) Up(ctx context.Context) ([]Migration, error) {
+	err := m.createTable(ctx)
+	if err != nil {
+		return nil, err
+	}
+	applied, err := m.applied(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var done []Migration
+	for _, migration := range m.migrations {
+		if _, ok := applied[migration.Version]; ok {
+			continue
+		}
+		ran, err := m.run(ctx, migration, true)
+		if err != nil {
+			return done, fmt.Errorf("error in applying migration %d %s : %w", migration.Version, migration.Name, err)
+		}
+		if ran {
+			done = append(done, migration)
+		}
+	}
+	return done, nil
+}
+
+// Down reverts the last steps applied migrations, newest first
+func (m *Migrator) Down(ctx context.Context, steps int) ([]Migration, error) {
+	err := m.createTable(ctx)
+	if err != nil {
+		return nil, err
+	}
+	applied, err := m.applied(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	versions := make([]int64, 0, len(applied))
+	for version := range applied {
+		versions = append(versions, version)
+	}
+	sort.Slice(versions, func(i, j int) bool {
+		return versions[i] > versions[j]
+	})
+	if steps < len(versions) {
+		versions = versions[:steps]
+	}
+
+	var done []Migration
+	for _, version := range versions {
+		migration, ok := m.find(version)
+		if !ok {
+			return done, fmt.Errorf("migration %d %s is not known to this binary and can not be reverted", version, applied[version].Name)
+		}
+		ran, err := m.run(ctx, migration, false)
+		if err != nil {
+			return done, fmt.Errorf("error in reverting migration %d %s : %w", migration.Version, migration.Name, err)
+		}
+		if ran {
+			done = append(done, migration)
+		}
+	}
+	return done, nil
+}
+
+// run applies or reverts one migration together with its row in schema_migrations,
+// it reports false when another instance got to the migration first
+func (m *Migrator) run(ctx context.Context, migration Migration, up bool) (bool, error) {
+	ran := false
+	err := m.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Exec("SELECT pg_advisory_xact_lock(?)", migrationLockID).Error
+		if err != nil {
+			return err
+		}
+
+		var count int64
+		err = tx.Table("schema_migrations").Where("version = ?", migration.Version).Count(&count).Error
+		if err != nil {
+			return err
+		}
+		if up == (count > 0) {
+			return nil
+		}
+
+		if up {
+			err = migration.Up(tx)
+			if err != nil {
+				return err
+			}
+			err = tx.Exec("INSERT INTO schema_migrations (version, name) VALUES (?, ?)", migration.Version, migration.Name).Error
+		} else {
+			err = migration.Down(tx)
+			if err != nil {
+				return err
+			}
+			err = tx.Exec("DELETE FROM schema_migrations WHERE version = ?", migration.Version).Error
+		}
+		if err != nil {
+			return err
+		}
+		ran = true
+		return nil
+	})
+	return ran, err
+}
+
+func (m *Migrator) find(version int64) (Migration, bool) {
+	for _, migration := range m.migrations {
+		if migration.Version == version {
+			return migration, true
+		}
+	}
+	return Migration{}, false
+}
+
+// Status lists every migration known to the binary or recorded in the database, sorted by version
+func (m *Migrator) Status(ctx context.Context) ([]MigrationStatus, error) {
+	applied, err := m.applied(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]MigrationStatus, 0, len(m.migrations))
+	for _, migration := range m.migrations {
+		status := MigrationStatus{Version: migration.Version, Name: migration.Name}
+		if row, ok := applied[migration.Version]; ok {
+			appliedAt := row.AppliedAt
+			status.AppliedAt = &appliedAt
+			delete(applied, migration.Version)
+		}
+		statuses = append(statuses, status)
+	}
+	for _, row := range applied {
+		appliedAt := row.AppliedAt
+		statuses = append(statuses, MigrationStatus{Version: row.Version, Name: row.Name, AppliedAt: &appliedAt, Unknown: true})
+	}
+	sort.Slice(statuses, func(i, j int) bool {
+		return statuses[i].Version < statuses[j].Version
+	})
+	return statuses, nil
+}
+
+// Check fails with ErrSchemaBehind when a migration of the binary has not been applied,
+// the server does not start on such a schema because the queries would fail at random
+func (m *Migrator) Check(ctx context.Context) error {
+	statuses, err := m.Status(ctx)
+	if err != nil {
+		return err
+	}
+	var pending []string
+	for _, status := range statuses {
+		if status.AppliedAt == nil {
+			pending = append(pending, fmt.Sprintf("%d_%s", status.Version, status.Name))
+		}
+	}
+	if len(pending) > 0 {
+		return fmt.Errorf("%w, %d pending migrations (%s), run the migrate up command first", ErrSchemaBehind, len(pending), strings.Join(pending, ", "))
+	}
+	return nil
+}
+
+// CreateMigration writes an empty up and down file for the next version into dir and returns their paths
+func CreateMigration(dir string, name string) ([]string, error) {
+	name = strings.Trim(regexp.MustCompile(`[^a-z0-9]+`).ReplaceAllString(strings.ToLower(name), "_"), "_")
+	if name == "" {
+		return nil, errors.New("migration name must contain letters or digits")
+	}
+
+	migrations, err := loadMigrations(os.DirFS(dir), ".", goMigrations)
+	if err != nil {
+		return nil, err
+	}
+	var version int64 = 1
+	if len(migrations) > 0 {
+		version = migrations[len(migrations)-1].Version + 1
+	}
+
+	var paths []string
+	for _, direction := range []string{"up", "down"} {
+		file := filepath.Join(dir, fmt.Sprintf("%04d_%s.%s.sql", version, name, direction))
+		content := fmt.Sprintf("-- %s %s, runs in one transaction together with its row in schema_migrations\n", name, direction)
+		err := os.WriteFile(file, []byte(content), 0o644)
+		if err != nil {
+			return paths, fmt.Errorf("error in writing the migration %s : %w", file, err)
+		}
+		paths = append(paths, file)
+	}
+	return paths, nil
+}
@@ -0,0 +1,118 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/afthaab/job-portal/internal/models"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+// baselineSchema is what AutoMigrate created for the first release, before roles, job statuses,
+// descriptions and the structured compensation existed
+const baselineSchema = `
+CREATE TABLE users (
+	id bigserial PRIMARY KEY,
+	created_at timestamptz,
+	updated_at timestamptz,
+	deleted_at timestamptz,
+	username text UNIQUE,
+	email text UNIQUE,
+	password_hash text
+);
+CREATE INDEX idx_users_deleted_at ON users (deleted_at);
+CREATE TABLE companies (
+	id bigserial PRIMARY KEY,
+	created_at timestamptz,
+	updated_at timestamptz,
+	deleted_at timestamptz,
+	name text UNIQUE,
+	location text,
+	field text
+);
+CREATE INDEX idx_companies_deleted_at ON companies (deleted_at);
+CREATE TABLE jobs (
+	id bigserial PRIMARY KEY,
+	created_at timestamptz,
+	updated_at timestamptz,
+	deleted_at timestamptz,
+	cid bigint CONSTRAINT fk_jobs_company REFERENCES companies (id),
+	name text,
+	salary text,
+	notice_period text
+);
+CREATE INDEX idx_jobs_deleted_at ON jobs (deleted_at);
+INSERT INTO users (created_at, username, email, password_hash) VALUES (now(), 'afthab', 'afthab606@gmail.com', 'hash');
+INSERT INTO companies (created_at, name, location, field) VALUES (now(), 'tek', 'bangalore', 'software');
+INSERT INTO jobs (created_at, cid, name, salary, notice_period) VALUES (now(), 1, 'golang developer', '₹ 5-8 LPA', '30 days');
+`
+
+// TestMigrator_UpFromBaseline takes over a database of the first release, it needs a postgres
+// and only runs when TEST_DATABASE_DSN points at one. Everything happens in a schema of its own that is dropped after
+func TestMigrator_UpFromBaseline(t *testing.T) {
+	dsn := os.Getenv("TEST_DATABASE_DSN")
+	if dsn == "" {
+		t.Skip("TEST_DATABASE_DSN is not set")
+	}
+	ctx := context.Background()
+	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{TranslateError: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// one connection so the search path below holds for every statement
+	sqlDB.SetMaxOpenConns(1)
+	t.Cleanup(func() { sqlDB.Close() })
+
+	schema := fmt.Sprintf("baseline_test_%d", time.Now().UnixNano())
+	err = db.Exec("CREATE SCHEMA " + schema).Error
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Exec("DROP SCHEMA " + schema + " CASCADE") })
+	err = db.Exec("SET search_path TO " + schema).Error
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = db.Exec(baselineSchema).Error
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	migrator, err := NewMigrator(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = migrator.Up(ctx)
+	if err != nil {
+		t.Fatalf("Migrator.Up() error = %v", err)
+	}
+
+	var job models.Jobs
+	err = db.First(&job, 1).Error
+	if err != nil {
+		t.Fatal(err)
+	}
+	if job.Status != models.JobOpen || job.Compensation.MinAmount != 500000 || job.NoticePeriodDays != 30 || job.NeedsReview {
+		t.Errorf("migrated job = %+v, want an open job with its salary and notice period structured", job)
+	}
+	var user models.User
+	err = db.First(&user, 1).Error
+	if err != nil {
+		t.Fatal(err)
+	}
+	if user.Role != models.RoleCandidate {
+		t.Errorf("migrated user role = %q, want %q", user.Role, models.RoleCandidate)
+	}
+	err = db.Create(&models.User{Username: "new", Email: "new@example.com", PasswordHash: "hash", Role: models.RoleRecruiter}).Error
+	if err != nil {
+		t.Errorf("creating a user after the migrations error = %v", err)
+	}
+}
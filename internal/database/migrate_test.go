@@ -0,0 +1,124 @@
+package database
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"testing/fstest"
+)
+
+func Test_loadMigrations(t *testing.T) {
+	file := func(content string) *fstest.MapFile {
+		return &fstest.MapFile{Data: []byte(content)}
+	}
+	tests := []struct {
+		name         string
+		files        fstest.MapFS
+		wantVersions []int64
+		wantErr      bool
+	}{
+		{
+			name: "sorted by version",
+			files: fstest.MapFS{
+				"migrations/0002_add_jobs.up.sql":      file("CREATE TABLE jobs ()"),
+				"migrations/0002_add_jobs.down.sql":    file("DROP TABLE jobs"),
+				"migrations/0001_add_users.up.sql":     file("CREATE TABLE users ()"),
+				"migrations/0001_add_users.down.sql":   file("DROP TABLE users"),
+				"migrations/0004_add_indexes.up.sql":   file("CREATE INDEX idx ON jobs (id)"),
+				"migrations/0004_add_indexes.down.sql": file("DROP INDEX idx"),
+			},
+			wantVersions: []int64{1, 2, 3, 4},
+		},
+		{
+			name: "missing down",
+			files: fstest.MapFS{
+				"migrations/0001_add_users.up.sql": file("CREATE TABLE users ()"),
+			},
+			wantErr: true,
+		},
+		{
+			name: "badly named file",
+			files: fstest.MapFS{
+				"migrations/add_users.sql": file("CREATE TABLE users ()"),
+			},
+			wantErr: true,
+		},
+		{
+			name: "version taken by a go migration",
+			files: fstest.MapFS{
+				"migrations/0003_add_users.up.sql":   file("CREATE TABLE users ()"),
+				"migrations/0003_add_users.down.sql": file("DROP TABLE users"),
+			},
+			wantErr: true,
+		},
+		{
+			name: "one version with two names",
+			files: fstest.MapFS{
+				"migrations/0001_add_users.up.sql":      file("CREATE TABLE users ()"),
+				"migrations/0001_create_users.down.sql": file("DROP TABLE users"),
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := loadMigrations(tt.files, "migrations", goMigrations)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("loadMigrations() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			var versions []int64
+			for _, m := range got {
+				versions = append(versions, m.Version)
+			}
+			if !reflect.DeepEqual(versions, tt.wantVersions) {
+				t.Errorf("loadMigrations() versions = %v, want %v", versions, tt.wantVersions)
+			}
+		})
+	}
+}
+
+func TestNewMigrator(t *testing.T) {
+	// the migrations built into the binary have to load, a broken file name would stop every start
+	m, err := NewMigrator(nil)
+	if err != nil {
+		t.Fatalf("NewMigrator() error = %v", err)
+	}
+	for i, migration := range m.migrations {
+		if migration.Version != int64(i+1) {
+			t.Errorf("migration %s has version %d, want %d without gaps", migration.Name, migration.Version, i+1)
+		}
+	}
+}
+
+func TestCreateMigration(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"0001_add_users.up.sql", "0001_add_users.down.sql"} {
+		err := os.WriteFile(filepath.Join(dir, name), []byte("SELECT 1"), 0o644)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	got, err := CreateMigration(dir, "Add Email Verification")
+	if err != nil {
+		t.Fatalf("CreateMigration() error = %v", err)
+	}
+	want := []string{
+		filepath.Join(dir, "0004_add_email_verification.up.sql"),
+		filepath.Join(dir, "0004_add_email_verification.down.sql"),
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("CreateMigration() = %v, want %v", got, want)
+	}
+	for _, path := range want {
+		if _, err := os.Stat(path); err != nil {
+			t.Errorf("CreateMigration() did not write %s", path)
+		}
+	}
+
+	_, err = CreateMigration(dir, "--")
+	if err == nil {
+		t.Errorf("CreateMigration() accepted a name without letters")
+	}
+}
@@ -0,0 +1,9 @@
+DROP TABLE IF EXISTS company_invitations;
+DROP TABLE IF EXISTS company_members;
+DROP TABLE IF EXISTS revoked_tokens;
+DROP TABLE IF EXISTS refresh_tokens;
+DROP TABLE IF EXISTS application_audits;
+DROP TABLE IF EXISTS applications;
+DROP TABLE IF EXISTS jobs;
+DROP TABLE IF EXISTS companies;
+DROP TABLE IF EXISTS users;
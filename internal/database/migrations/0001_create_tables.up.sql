@@ -0,0 +1,136 @@
+-- the schema AutoMigrate used to create at startup, every statement is guarded so a database
+-- that was created before the migrations existed is taken over. AutoMigrate only ever added tables
+-- and columns, so the columns added since the first release are added at the end of this file
+-- for a database last started by an older build
+
+CREATE TABLE IF NOT EXISTS users (
+	id bigserial PRIMARY KEY,
+	created_at timestamptz,
+	updated_at timestamptz,
+	deleted_at timestamptz,
+	username text UNIQUE,
+	email text UNIQUE,
+	password_hash text,
+	role text NOT NULL DEFAULT 'candidate'
+);
+CREATE INDEX IF NOT EXISTS idx_users_deleted_at ON users (deleted_at);
+
+CREATE TABLE IF NOT EXISTS companies (
+	id bigserial PRIMARY KEY,
+	created_at timestamptz,
+	updated_at timestamptz,
+	deleted_at timestamptz,
+	name text UNIQUE,
+	location text,
+	field text
+);
+CREATE INDEX IF NOT EXISTS idx_companies_deleted_at ON companies (deleted_at);
+
+CREATE TABLE IF NOT EXISTS jobs (
+	id bigserial PRIMARY KEY,
+	created_at timestamptz,
+	updated_at timestamptz,
+	deleted_at timestamptz,
+	cid bigint CONSTRAINT fk_jobs_company REFERENCES companies (id),
+	name text,
+	salary_min_amount bigint,
+	salary_max_amount bigint,
+	salary_currency text,
+	salary_pay_period text,
+	salary_equity boolean,
+	notice_period_days bigint,
+	description text,
+	status text NOT NULL DEFAULT 'open',
+	needs_review boolean NOT NULL DEFAULT false
+);
+CREATE INDEX IF NOT EXISTS idx_jobs_deleted_at ON jobs (deleted_at);
+
+CREATE TABLE IF NOT EXISTS applications (
+	id bigserial PRIMARY KEY,
+	created_at timestamptz,
+	updated_at timestamptz,
+	deleted_at timestamptz,
+	uid bigint CONSTRAINT fk_applications_user REFERENCES users (id),
+	jid bigint CONSTRAINT fk_applications_job REFERENCES jobs (id),
+	cover_letter text,
+	status text NOT NULL DEFAULT 'submitted'
+);
+CREATE INDEX IF NOT EXISTS idx_applications_deleted_at ON applications (deleted_at);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_application_user_job ON applications (uid, jid);
+
+CREATE TABLE IF NOT EXISTS application_audits (
+	id bigserial PRIMARY KEY,
+	created_at timestamptz,
+	updated_at timestamptz,
+	deleted_at timestamptz,
+	aid bigint CONSTRAINT fk_application_audits_application REFERENCES applications (id),
+	from_status text,
+	to_status text,
+	changed_by bigint
+);
+CREATE INDEX IF NOT EXISTS idx_application_audits_deleted_at ON application_audits (deleted_at);
+CREATE INDEX IF NOT EXISTS idx_application_audits_aid ON application_audits (aid);
+
+CREATE TABLE IF NOT EXISTS refresh_tokens (
+	id bigserial PRIMARY KEY,
+	created_at timestamptz,
+	updated_at timestamptz,
+	deleted_at timestamptz,
+	uid bigint CONSTRAINT fk_refresh_tokens_user REFERENCES users (id),
+	token_hash text NOT NULL,
+	family text NOT NULL,
+	expires_at timestamptz,
+	revoked_at timestamptz,
+	replaced_by bigint
+);
+CREATE INDEX IF NOT EXISTS idx_refresh_tokens_deleted_at ON refresh_tokens (deleted_at);
+CREATE INDEX IF NOT EXISTS idx_refresh_tokens_uid ON refresh_tokens (uid);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_refresh_tokens_token_hash ON refresh_tokens (token_hash);
+CREATE INDEX IF NOT EXISTS idx_refresh_tokens_family ON refresh_tokens (family);
+
+CREATE TABLE IF NOT EXISTS revoked_tokens (
+	jti text PRIMARY KEY,
+	expires_at timestamptz
+);
+CREATE INDEX IF NOT EXISTS idx_revoked_tokens_expires_at ON revoked_tokens (expires_at);
+
+CREATE TABLE IF NOT EXISTS company_members (
+	id bigserial PRIMARY KEY,
+	created_at timestamptz,
+	updated_at timestamptz,
+	deleted_at timestamptz,
+	cid bigint CONSTRAINT fk_company_members_company REFERENCES companies (id),
+	uid bigint CONSTRAINT fk_company_members_user REFERENCES users (id),
+	role text NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_company_members_deleted_at ON company_members (deleted_at);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_company_member ON company_members (cid, uid);
+
+CREATE TABLE IF NOT EXISTS company_invitations (
+	id bigserial PRIMARY KEY,
+	created_at timestamptz,
+	updated_at timestamptz,
+	deleted_at timestamptz,
+	cid bigint CONSTRAINT fk_company_invitations_company REFERENCES companies (id),
+	email text NOT NULL,
+	role text NOT NULL,
+	invited_by bigint,
+	expires_at timestamptz,
+	accepted_at timestamptz
+);
+CREATE INDEX IF NOT EXISTS idx_company_invitations_deleted_at ON company_invitations (deleted_at);
+CREATE INDEX IF NOT EXISTS idx_company_invitations_cid ON company_invitations (cid);
+CREATE INDEX IF NOT EXISTS idx_company_invitations_email ON company_invitations (email);
+
+-- columns of users and jobs that AutoMigrate added after the first release, which only had the
+-- free text salary and notice_period that migration 3 turns into the structured columns
+ALTER TABLE users ADD COLUMN IF NOT EXISTS role text NOT NULL DEFAULT 'candidate';
+ALTER TABLE jobs ADD COLUMN IF NOT EXISTS salary_min_amount bigint;
+ALTER TABLE jobs ADD COLUMN IF NOT EXISTS salary_max_amount bigint;
+ALTER TABLE jobs ADD COLUMN IF NOT EXISTS salary_currency text;
+ALTER TABLE jobs ADD COLUMN IF NOT EXISTS salary_pay_period text;
+ALTER TABLE jobs ADD COLUMN IF NOT EXISTS salary_equity boolean;
+ALTER TABLE jobs ADD COLUMN IF NOT EXISTS notice_period_days bigint;
+ALTER TABLE jobs ADD COLUMN IF NOT EXISTS description text;
+ALTER TABLE jobs ADD COLUMN IF NOT EXISTS status text NOT NULL DEFAULT 'open';
+ALTER TABLE jobs ADD COLUMN IF NOT EXISTS needs_review boolean NOT NULL DEFAULT false;
@@ -0,0 +1,6 @@
+DROP TRIGGER IF EXISTS companies_search_vector_trigger ON companies;
+DROP FUNCTION IF EXISTS companies_search_vector_update();
+DROP TRIGGER IF EXISTS jobs_search_vector_trigger ON jobs;
+DROP FUNCTION IF EXISTS jobs_search_vector_update();
+DROP INDEX IF EXISTS idx_jobs_search_vector;
+ALTER TABLE jobs DROP COLUMN IF EXISTS search_vector;
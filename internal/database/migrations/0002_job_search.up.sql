@@ -0,0 +1,42 @@
+-- the full text search vector of the jobs is built by a trigger because it also covers
+-- the name, field and location of the company the job belongs to
+
+ALTER TABLE jobs ADD COLUMN IF NOT EXISTS search_vector tsvector;
+
+CREATE INDEX IF NOT EXISTS idx_jobs_search_vector ON jobs USING GIN (search_vector);
+
+CREATE OR REPLACE FUNCTION jobs_search_vector_update() RETURNS trigger AS $$
+DECLARE
+	company companies%ROWTYPE;
+BEGIN
+	SELECT * INTO company FROM companies WHERE id = NEW.cid;
+	NEW.search_vector :=
+		setweight(to_tsvector('english', coalesce(NEW.name, '')), 'A') ||
+		setweight(to_tsvector('english', coalesce(company.name, '')), 'B') ||
+		setweight(to_tsvector('english', coalesce(company.field, '')), 'B') ||
+		setweight(to_tsvector('english', coalesce(NEW.description, '')), 'C') ||
+		setweight(to_tsvector('english', coalesce(company.location, '')), 'D');
+	RETURN NEW;
+END
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS jobs_search_vector_trigger ON jobs;
+
+CREATE TRIGGER jobs_search_vector_trigger BEFORE INSERT OR UPDATE ON jobs
+	FOR EACH ROW EXECUTE FUNCTION jobs_search_vector_update();
+
+-- touching the jobs of a company fires the trigger above again
+CREATE OR REPLACE FUNCTION companies_search_vector_update() RETURNS trigger AS $$
+BEGIN
+	UPDATE jobs SET updated_at = updated_at WHERE cid = NEW.id;
+	RETURN NEW;
+END
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS companies_search_vector_trigger ON companies;
+
+CREATE TRIGGER companies_search_vector_trigger AFTER UPDATE OF name, field, location ON companies
+	FOR EACH ROW EXECUTE FUNCTION companies_search_vector_update();
+
+-- jobs written before the trigger existed
+UPDATE jobs SET updated_at = updated_at WHERE search_vector IS NULL;
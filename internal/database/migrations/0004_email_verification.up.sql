@@ -0,0 +1,19 @@
+-- accounts created before verification existed keep signing in, they count as verified from their creation
+ALTER TABLE users ADD COLUMN IF NOT EXISTS email_verified_at timestamptz;
+UPDATE users SET email_verified_at = created_at WHERE email_verified_at IS NULL;
+
+CREATE TABLE IF NOT EXISTS user_tokens (
+	id bigserial PRIMARY KEY,
+	created_at timestamptz,
+	updated_at timestamptz,
+	deleted_at timestamptz,
+	uid bigint CONSTRAINT fk_user_tokens_user REFERENCES users (id),
+	purpose text NOT NULL,
+	token_hash text NOT NULL,
+	email text,
+	expires_at timestamptz,
+	used_at timestamptz
+);
+CREATE INDEX IF NOT EXISTS idx_user_tokens_deleted_at ON user_tokens (deleted_at);
+CREATE INDEX IF NOT EXISTS idx_user_tokens_uid ON user_tokens (uid);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_user_tokens_token_hash ON user_tokens (token_hash);
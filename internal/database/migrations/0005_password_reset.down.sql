@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_users_password_changed_at;
+ALTER TABLE users DROP COLUMN IF EXISTS password_changed_at;
@@ -0,0 +1,3 @@
+-- the access tokens of a user issued before the last password reset are revoked, a restart reloads them from here
+ALTER TABLE users ADD COLUMN IF NOT EXISTS password_changed_at timestamptz;
+CREATE INDEX IF NOT EXISTS idx_users_password_changed_at ON users (password_changed_at);
@@ -0,0 +1,3 @@
+ALTER TABLE users DROP COLUMN IF EXISTS location;
+ALTER TABLE users DROP COLUMN IF EXISTS headline;
+ALTER TABLE users DROP COLUMN IF EXISTS full_name;
@@ -0,0 +1,4 @@
+-- the profile a user edits through /user/me, empty until then
+ALTER TABLE users ADD COLUMN IF NOT EXISTS full_name text NOT NULL DEFAULT '';
+ALTER TABLE users ADD COLUMN IF NOT EXISTS headline text NOT NULL DEFAULT '';
+ALTER TABLE users ADD COLUMN IF NOT EXISTS location text NOT NULL DEFAULT '';
@@ -0,0 +1,7 @@
+-- every access token of an erased account is revoked, the account is gone so a restart reloads them from here
+CREATE TABLE IF NOT EXISTS revoked_subjects (
+	subject text PRIMARY KEY,
+	issued_before timestamptz,
+	expires_at timestamptz
+);
+CREATE INDEX IF NOT EXISTS idx_revoked_subjects_expires_at ON revoked_subjects (expires_at);
@@ -0,0 +1,205 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/afthaab/job-portal/internal/auth"
+	"github.com/afthaab/job-portal/internal/middleware"
+	"github.com/afthaab/job-portal/internal/models"
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog/log"
+)
+
+func (h *handler) ApplyForJob(c *gin.Context) {
+	ctx := c.Request.Context()
+	traceid, ok := ctx.Value(middleware.TraceIDKey).(string)
+	if !ok {
+		log.Error().Msg("traceid missing from context")
+		middleware.AbortWithProblem(c, http.StatusInternalServerError, "")
+		return
+	}
+	claims, ok := ctx.Value(auth.Key).(auth.Claims)
+	if !ok {
+		log.Error().Str("Trace Id", traceid).Msg("login first")
+		middleware.AbortWithProblem(c, http.StatusUnauthorized, "")
+		return
+	}
+
+	uid, err := strconv.ParseUint(claims.Subject, 10, 64)
+	if err != nil {
+		log.Error().Err(err).Str("trace id", traceid).Msg("invalid subject in the claims")
+		middleware.AbortWithProblem(c, http.StatusUnauthorized, "")
+		return
+	}
+
+	jid, err := pathID(c, "id")
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+
+	var applicationData models.NewApplication
+
+	err = bindJSON(c, &applicationData)
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+
+	application, err := h.service.ApplyForJob(ctx, uid, jid, applicationData)
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, application)
+}
+
+func (h *handler) WithdrawApplication(c *gin.Context) {
+	ctx := c.Request.Context()
+	traceid, ok := ctx.Value(middleware.TraceIDKey).(string)
+	if !ok {
+		log.Error().Msg("traceid missing from context")
+		middleware.AbortWithProblem(c, http.StatusInternalServerError, "")
+		return
+	}
+	claims, ok := ctx.Value(auth.Key).(auth.Claims)
+	if !ok {
+		log.Error().Str("Trace Id", traceid).Msg("login first")
+		middleware.AbortWithProblem(c, http.StatusUnauthorized, "")
+		return
+	}
+
+	uid, err := strconv.ParseUint(claims.Subject, 10, 64)
+	if err != nil {
+		log.Error().Err(err).Str("trace id", traceid).Msg("invalid subject in the claims")
+		middleware.AbortWithProblem(c, http.StatusUnauthorized, "")
+		return
+	}
+
+	aid, err := pathID(c, "id")
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+
+	application, err := h.service.WithdrawApplication(ctx, uid, aid)
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, application)
+}
+
+func (h *handler) ViewMyApplications(c *gin.Context) {
+	ctx := c.Request.Context()
+	traceid, ok := ctx.Value(middleware.TraceIDKey).(string)
+	if !ok {
+		log.Error().Msg("traceid missing from context")
+		middleware.AbortWithProblem(c, http.StatusInternalServerError, "")
+		return
+	}
+	claims, ok := ctx.Value(auth.Key).(auth.Claims)
+	if !ok {
+		log.Error().Str("Trace Id", traceid).Msg("login first")
+		middleware.AbortWithProblem(c, http.StatusUnauthorized, "")
+		return
+	}
+
+	uid, err := strconv.ParseUint(claims.Subject, 10, 64)
+	if err != nil {
+		log.Error().Err(err).Str("trace id", traceid).Msg("invalid subject in the claims")
+		middleware.AbortWithProblem(c, http.StatusUnauthorized, "")
+		return
+	}
+
+	page, err := pageQuery(c)
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+
+	applicationDatas, err := h.service.ViewMyApplications(ctx, uid, page)
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, applicationDatas)
+}
+
+func (h *handler) ViewJobApplicants(c *gin.Context) {
+	ctx := c.Request.Context()
+	traceid, ok := ctx.Value(middleware.TraceIDKey).(string)
+	if !ok {
+		log.Error().Msg("traceid missing from context")
+		middleware.AbortWithProblem(c, http.StatusInternalServerError, "")
+		return
+	}
+	claims, ok := ctx.Value(auth.Key).(auth.Claims)
+	if !ok {
+		log.Error().Str("Trace Id", traceid).Msg("login first")
+		middleware.AbortWithProblem(c, http.StatusUnauthorized, "")
+		return
+	}
+
+	jid, err := pathID(c, "id")
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+
+	page, err := pageQuery(c)
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+
+	applicationDatas, err := h.service.ViewJobApplicants(ctx, claims, jid, page)
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, applicationDatas)
+}
+
+func (h *handler) UpdateApplicationStatus(c *gin.Context) {
+	ctx := c.Request.Context()
+	traceid, ok := ctx.Value(middleware.TraceIDKey).(string)
+	if !ok {
+		log.Error().Msg("traceid missing from context")
+		middleware.AbortWithProblem(c, http.StatusInternalServerError, "")
+		return
+	}
+	claims, ok := ctx.Value(auth.Key).(auth.Claims)
+	if !ok {
+		log.Error().Str("Trace Id", traceid).Msg("login first")
+		middleware.AbortWithProblem(c, http.StatusUnauthorized, "")
+		return
+	}
+
+	aid, err := pathID(c, "id")
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+
+	var statusData models.ApplicationStatus
+
+	err = bindJSON(c, &statusData)
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+
+	application, err := h.service.UpdateApplicationStatus(ctx, claims, aid, statusData.Status)
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, application)
+}
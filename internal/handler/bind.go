@@ -0,0 +1,224 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/afthaab/job-portal/internal/apperr"
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+)
+
+// maxBodyBytes is the largest request body a handler reads, a job description is the biggest field we accept
+const maxBodyBytes = 1 << 20
+
+// codes of the field errors, clients can rely on them while the messages may change
+const (
+	codeRequired        = "required"
+	codeInvalidType     = "invalid_type"
+	codeUnknownField    = "unknown_field"
+	codeInvalidEmail    = "invalid_email"
+	codeInvalidCurrency = "invalid_currency"
+	codeNotAllowed      = "not_allowed"
+	codeTooShort        = "too_short"
+	codeTooLong         = "too_long"
+	codeTooSmall        = "too_small"
+	codeTooLarge        = "too_large"
+	codeOutOfRange      = "out_of_range"
+	codeInvalidID       = "invalid_id"
+	codeInvalid         = "invalid"
+)
+
+// errEmptyBody lets the handlers with an optional body tell a missing body apart from an invalid one
+var errEmptyBody = apperr.Validation("request body must not be empty")
+
+// validate is shared by every handler, it caches the parsed struct tags
+var validate = newValidator()
+
+func newValidator() *validator.Validate {
+	v := validator.New()
+	// report the json names of the fields so the errors match what the client sent
+	v.RegisterTagNameFunc(func(f reflect.StructField) string {
+		name := strings.SplitN(f.Tag.Get("json"), ",", 2)[0]
+		if name == "-" {
+			return ""
+		}
+		return name
+	})
+	return v
+}
+
+// bindJSON decodes the body of the request into dst and validates it, the body has to be a single
+// json object of at most maxBodyBytes without any field dst does not know about
+func bindJSON(c *gin.Context, dst interface{}) error {
+	body, err := io.ReadAll(io.LimitReader(c.Request.Body, maxBodyBytes+1))
+	if err != nil {
+		return apperr.Validation("could not read the request body")
+	}
+	if len(body) > maxBodyBytes {
+		return apperr.TooLarge(fmt.Sprintf("request body must not be larger than %d bytes", maxBodyBytes))
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(body))
+	dec.DisallowUnknownFields()
+	err = dec.Decode(dst)
+	if err != nil {
+		return decodeError(err)
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		return apperr.Validation("request body must only contain a single json object")
+	}
+
+	return validateStruct(dst)
+}
+
+func decodeError(err error) error {
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	switch {
+	case errors.Is(err, io.EOF):
+		return errEmptyBody
+	case errors.Is(err, io.ErrUnexpectedEOF):
+		return apperr.Validation("request body is not valid json")
+	case errors.As(err, &syntaxErr):
+		return apperr.Validation(fmt.Sprintf("request body is not valid json at byte %d", syntaxErr.Offset))
+	case errors.As(err, &typeErr):
+		return apperr.Invalid("request body has invalid fields", apperr.FieldError{
+			Field:   typeErr.Field,
+			Code:    codeInvalidType,
+			Message: fmt.Sprintf("must be %s", jsonType(typeErr.Type)),
+		})
+	case strings.HasPrefix(err.Error(), "json: unknown field "):
+		// the decoder has no error type for unknown fields, only this message
+		field := strings.Trim(strings.TrimPrefix(err.Error(), "json: unknown field "), `"`)
+		return apperr.Invalid("request body has invalid fields", apperr.FieldError{
+			Field:   field,
+			Code:    codeUnknownField,
+			Message: "is not a known field",
+		})
+	default:
+		return apperr.Validation("request body is not valid json")
+	}
+}
+
+// validateStruct runs the validate tags of v and turns every failed tag into a field error
+func validateStruct(v interface{}) error {
+	err := validate.Struct(v)
+	if err == nil {
+		return nil
+	}
+	var validationErrs validator.ValidationErrors
+	if !errors.As(err, &validationErrs) {
+		return apperr.Internal("could not validate the request", err)
+	}
+
+	root := reflect.TypeOf(v)
+	fields := make([]apperr.FieldError, 0, len(validationErrs))
+	for _, fe := range validationErrs {
+		fields = append(fields, fieldError(root, fe))
+	}
+	return apperr.Invalid("request body has invalid fields", fields...)
+}
+
+func fieldError(root reflect.Type, fe validator.FieldError) apperr.FieldError {
+	// the namespace starts with the name of the struct, the client only knows the path below it
+	field := fe.Namespace()
+	if i := strings.Index(field, "."); i >= 0 {
+		field = field[i+1:]
+	}
+
+	code, message := codeInvalid, "is invalid"
+	switch fe.Tag() {
+	case "required":
+		code, message = codeRequired, "is required"
+	case "email":
+		code, message = codeInvalidEmail, "must be a valid email address"
+	case "iso4217":
+		code, message = codeInvalidCurrency, "must be an ISO 4217 currency code"
+	case "oneof":
+		code, message = codeNotAllowed, "must be one of "+strings.Join(strings.Fields(fe.Param()), ", ")
+	case "min", "gte":
+		code, message = codeTooSmall, "must be at least "+fe.Param()
+		if fe.Kind() == reflect.String {
+			code, message = codeTooShort, "must be at least "+fe.Param()+" characters long"
+		}
+	case "max", "lte":
+		code, message = codeTooLarge, "must be at most "+fe.Param()
+		if fe.Kind() == reflect.String {
+			code, message = codeTooLong, "must be at most "+fe.Param()+" characters long"
+		}
+	case "gtefield":
+		code, message = codeOutOfRange, "must not be less than "+siblingName(root, fe)
+	}
+	return apperr.FieldError{Field: field, Code: code, Message: message}
+}
+
+// siblingName returns the json name of the field a cross field tag like gtefield compares with,
+// the tag only knows its go name
+func siblingName(root reflect.Type, fe validator.FieldError) string {
+	t := root
+	parts := strings.Split(fe.StructNamespace(), ".")
+	for i := 0; i < len(parts); i++ {
+		for t.Kind() == reflect.Ptr || t.Kind() == reflect.Slice || t.Kind() == reflect.Array || t.Kind() == reflect.Map {
+			t = t.Elem()
+		}
+		if i == 0 || i == len(parts)-1 {
+			continue
+		}
+		name := parts[i]
+		if j := strings.Index(name, "["); j >= 0 {
+			name = name[:j]
+		}
+		f, ok := t.FieldByName(name)
+		if !ok {
+			return fe.Param()
+		}
+		t = f.Type
+	}
+
+	f, ok := t.FieldByName(fe.Param())
+	if !ok {
+		return fe.Param()
+	}
+	name := strings.SplitN(f.Tag.Get("json"), ",", 2)[0]
+	if name == "" || name == "-" {
+		return fe.Param()
+	}
+	return name
+}
+
+func jsonType(t reflect.Type) string {
+	switch t.Kind() {
+	case reflect.Bool:
+		return "a boolean"
+	case reflect.String:
+		return "a string"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return "a number"
+	case reflect.Slice, reflect.Array:
+		return "an array"
+	default:
+		return "an object"
+	}
+}
+
+// pathID reads a numeric id from a route parameter like :id or :cid
+func pathID(c *gin.Context, name string) (uint64, error) {
+	id, err := strconv.ParseUint(c.Param(name), 10, 64)
+	if err != nil || id == 0 {
+		return 0, apperr.Invalid("invalid path parameter", apperr.FieldError{
+			Field:   name,
+			Code:    codeInvalidID,
+			Message: "must be a positive number",
+		})
+	}
+	return id, nil
+}
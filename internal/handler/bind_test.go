@@ -0,0 +1,150 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/afthaab/job-portal/internal/apperr"
+	"github.com/afthaab/job-portal/internal/models"
+	"github.com/gin-gonic/gin"
+)
+
+func Test_bindJSON(t *testing.T) {
+	tests := []struct {
+		name       string
+		body       string
+		dst        interface{}
+		wantKind   apperr.Kind
+		wantErr    bool
+		wantFields []apperr.FieldError
+	}{
+		{
+			name: "valid company",
+			body: `{"name":"tek","location":"bangalore","field":"software"}`,
+			dst:  &models.Company{},
+		},
+		{
+			name:     "empty body",
+			body:     ``,
+			dst:      &models.Company{},
+			wantErr:  true,
+			wantKind: apperr.KindValidation,
+		},
+		{
+			name:     "malformed json",
+			body:     `{"name":`,
+			dst:      &models.Company{},
+			wantErr:  true,
+			wantKind: apperr.KindValidation,
+		},
+		{
+			name:     "more than one object",
+			body:     `{"name":"tek","location":"bangalore","field":"software"}{}`,
+			dst:      &models.Company{},
+			wantErr:  true,
+			wantKind: apperr.KindValidation,
+		},
+		{
+			name:     "unknown field",
+			body:     `{"name":"tek","location":"bangalore","field":"software","owner":"me"}`,
+			dst:      &models.Company{},
+			wantErr:  true,
+			wantKind: apperr.KindValidation,
+			wantFields: []apperr.FieldError{
+				{Field: "owner", Code: "unknown_field", Message: "is not a known field"},
+			},
+		},
+		{
+			name:     "signup asking for a role",
+			body:     `{"username":"afthab","email":"afthab606@gmail.com","password":"purple tractor rain","role":"recruiter"}`,
+			dst:      &models.NewUser{},
+			wantErr:  true,
+			wantKind: apperr.KindValidation,
+			wantFields: []apperr.FieldError{
+				{Field: "role", Code: "unknown_field", Message: "is not a known field"},
+			},
+		},
+		{
+			name:     "wrong type",
+			body:     `{"name":"dev","notice_period_days":"soon"}`,
+			dst:      &models.NewJob{},
+			wantErr:  true,
+			wantKind: apperr.KindValidation,
+			wantFields: []apperr.FieldError{
+				{Field: "notice_period_days", Code: "invalid_type", Message: "must be a number"},
+			},
+		},
+		{
+			name:     "invalid fields",
+			body:     `{"notice_period_days":-1,"status":"hidden","compensation":{"min_amount":10,"max_amount":5,"currency":"XXY","pay_period":"yearly"}}`,
+			dst:      &models.NewJob{},
+			wantErr:  true,
+			wantKind: apperr.KindValidation,
+			wantFields: []apperr.FieldError{
+				{Field: "name", Code: "required", Message: "is required"},
+				{Field: "compensation.max_amount", Code: "out_of_range", Message: "must not be less than min_amount"},
+				{Field: "compensation.currency", Code: "invalid_currency", Message: "must be an ISO 4217 currency code"},
+				{Field: "notice_period_days", Code: "too_small", Message: "must be at least 0"},
+				{Field: "status", Code: "not_allowed", Message: "must be one of open, closed, draft"},
+			},
+		},
+		{
+			name:     "body too large",
+			body:     `{"name":"` + strings.Repeat("a", maxBodyBytes) + `"}`,
+			dst:      &models.Company{},
+			wantErr:  true,
+			wantKind: apperr.KindTooLarge,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, _ := gin.CreateTestContext(httptest.NewRecorder())
+			c.Request, _ = http.NewRequest(http.MethodPost, "http://test.com", strings.NewReader(tt.body))
+
+			err := bindJSON(c, tt.dst)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("bindJSON() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil {
+				return
+			}
+			if got := apperr.KindOf(err); got != tt.wantKind {
+				t.Errorf("bindJSON() kind = %v, want %v", got, tt.wantKind)
+			}
+			if got := apperr.FieldsOf(err); !reflect.DeepEqual(got, tt.wantFields) {
+				t.Errorf("bindJSON() fields = %v, want %v", got, tt.wantFields)
+			}
+		})
+	}
+}
+
+func Test_pathID(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   string
+		want    uint64
+		wantErr bool
+	}{
+		{name: "valid id", value: "12", want: 12},
+		{name: "not a number", value: "abc", wantErr: true},
+		{name: "zero", value: "0", wantErr: true},
+		{name: "negative", value: "-3", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, _ := gin.CreateTestContext(httptest.NewRecorder())
+			c.Params = gin.Params{{Key: "cid", Value: tt.value}}
+
+			got, err := pathID(c, "cid")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("pathID() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("pathID() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
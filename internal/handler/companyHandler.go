@@ -1,16 +1,12 @@
 package handler
 
 import (
-	"encoding/json"
 	"net/http"
-	"strconv"
 
 	"github.com/afthaab/job-portal/internal/auth"
 	"github.com/afthaab/job-portal/internal/middleware"
 	"github.com/afthaab/job-portal/internal/models"
 	"github.com/gin-gonic/gin"
-	"github.com/go-playground/validator/v10"
-	"github.com/golang-jwt/jwt/v5"
 	"github.com/rs/zerolog/log"
 )
 
@@ -19,31 +15,25 @@ func (h *handler) ViewCompany(c *gin.Context) {
 	traceid, ok := ctx.Value(middleware.TraceIDKey).(string)
 	if !ok {
 		log.Error().Msg("traceid missing from context")
-		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{
-			"error": http.StatusText(http.StatusInternalServerError),
-		})
+		middleware.AbortWithProblem(c, http.StatusInternalServerError, "")
 		return
 	}
-	_, ok = ctx.Value(auth.Key).(jwt.RegisteredClaims)
+	_, ok = ctx.Value(auth.Key).(auth.Claims)
 	if !ok {
 		log.Error().Str("Trace Id", traceid).Msg("login first")
-		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": http.StatusText(http.StatusUnauthorized)})
+		middleware.AbortWithProblem(c, http.StatusUnauthorized, "")
 		return
 	}
 
-	id := c.Param("id")
-
-	cid, err := strconv.ParseUint(id, 10, 64)
+	cid, err := pathID(c, "id")
 	if err != nil {
-		c.AbortWithStatusJSON(http.StatusBadRequest, http.StatusText(http.StatusBadRequest))
+		abortWithError(c, err)
+		return
 	}
 
 	companyData, err := h.service.ViewCompanyDetails(ctx, cid)
 	if err != nil {
-		log.Error().Err(err).Str("trace id", traceid)
-		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
-			"error": err.Error(),
-		})
+		abortWithError(c, err)
 		return
 	}
 
@@ -55,24 +45,25 @@ func (h *handler) ViewAllCompanies(c *gin.Context) {
 	traceid, ok := ctx.Value(middleware.TraceIDKey).(string)
 	if !ok {
 		log.Error().Msg("traceid missing from context")
-		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{
-			"error": http.StatusText(http.StatusInternalServerError),
-		})
+		middleware.AbortWithProblem(c, http.StatusInternalServerError, "")
 		return
 	}
-	_, ok = ctx.Value(auth.Key).(jwt.RegisteredClaims)
+	_, ok = ctx.Value(auth.Key).(auth.Claims)
 	if !ok {
 		log.Error().Str("Trace Id", traceid).Msg("login first")
-		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": http.StatusText(http.StatusUnauthorized)})
+		middleware.AbortWithProblem(c, http.StatusUnauthorized, "")
 		return
 	}
 
-	companyDetails, err := h.service.ViewAllCompanies(ctx)
+	page, err := pageQuery(c)
 	if err != nil {
-		log.Error().Err(err).Str("trace id", traceid)
-		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
-			"error": err.Error(),
-		})
+		abortWithError(c, err)
+		return
+	}
+
+	companyDetails, err := h.service.ViewAllCompanies(ctx, companyFilter(c), page)
+	if err != nil {
+		abortWithError(c, err)
 		return
 	}
 
@@ -85,49 +76,169 @@ func (h *handler) AddCompany(c *gin.Context) {
 	traceid, ok := ctx.Value(middleware.TraceIDKey).(string)
 	if !ok {
 		log.Error().Msg("traceid missing from context")
-		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{
-			"error": http.StatusText(http.StatusInternalServerError),
-		})
+		middleware.AbortWithProblem(c, http.StatusInternalServerError, "")
 		return
 	}
 
-	_, ok = ctx.Value(auth.Key).(jwt.RegisteredClaims)
+	claims, ok := ctx.Value(auth.Key).(auth.Claims)
 	if !ok {
 		log.Error().Str("Trace Id", traceid).Msg("login first")
-		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": http.StatusText(http.StatusUnauthorized)})
+		middleware.AbortWithProblem(c, http.StatusUnauthorized, "")
 		return
 	}
 
-	var companyData models.Company
+	var companyData models.NewCompany
 
-	err := json.NewDecoder(c.Request.Body).Decode(&companyData)
+	err := bindJSON(c, &companyData)
 	if err != nil {
-		log.Error().Err(err).Str("trace id", traceid)
-		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
-			"error": "please provide valid name, location and field",
-		})
+		abortWithError(c, err)
 		return
 	}
 
-	validate := validator.New()
-	err = validate.Struct(companyData)
+	companyDetails, err := h.service.AddCompanyDetails(ctx, claims, companyData)
 	if err != nil {
-		log.Error().Err(err).Str("trace id", traceid)
-		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
-			"error": "please provide valid name, location and field",
-		})
+		abortWithError(c, err)
 		return
 	}
 
-	companyData, err = h.service.AddCompanyDetails(ctx, companyData)
+	c.JSON(http.StatusOK, companyDetails)
+
+}
+
+func (h *handler) UpdateCompany(c *gin.Context) {
+	ctx := c.Request.Context()
+	traceid, ok := ctx.Value(middleware.TraceIDKey).(string)
+	if !ok {
+		log.Error().Msg("traceid missing from context")
+		middleware.AbortWithProblem(c, http.StatusInternalServerError, "")
+		return
+	}
+	claims, ok := ctx.Value(auth.Key).(auth.Claims)
+	if !ok {
+		log.Error().Str("Trace Id", traceid).Msg("login first")
+		middleware.AbortWithProblem(c, http.StatusUnauthorized, "")
+		return
+	}
+
+	cid, err := pathID(c, "id")
 	if err != nil {
-		log.Error().Err(err).Str("trace id", traceid)
-		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
-			"error": err.Error(),
-		})
+		abortWithError(c, err)
 		return
 	}
 
-	c.JSON(http.StatusOK, companyData)
+	var companyData models.NewCompany
+
+	err = bindJSON(c, &companyData)
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+
+	companyDetails, err := h.service.UpdateCompanyDetails(ctx, claims, cid, companyData)
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, companyDetails)
+}
+
+func (h *handler) PatchCompany(c *gin.Context) {
+	ctx := c.Request.Context()
+	traceid, ok := ctx.Value(middleware.TraceIDKey).(string)
+	if !ok {
+		log.Error().Msg("traceid missing from context")
+		middleware.AbortWithProblem(c, http.StatusInternalServerError, "")
+		return
+	}
+	claims, ok := ctx.Value(auth.Key).(auth.Claims)
+	if !ok {
+		log.Error().Str("Trace Id", traceid).Msg("login first")
+		middleware.AbortWithProblem(c, http.StatusUnauthorized, "")
+		return
+	}
+
+	cid, err := pathID(c, "id")
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+
+	var companyData models.UpdateCompany
+
+	err = bindJSON(c, &companyData)
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+
+	updatedData, err := h.service.PatchCompanyDetails(ctx, claims, cid, companyData)
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, updatedData)
+}
+
+func (h *handler) DeleteCompany(c *gin.Context) {
+	ctx := c.Request.Context()
+	traceid, ok := ctx.Value(middleware.TraceIDKey).(string)
+	if !ok {
+		log.Error().Msg("traceid missing from context")
+		middleware.AbortWithProblem(c, http.StatusInternalServerError, "")
+		return
+	}
+	claims, ok := ctx.Value(auth.Key).(auth.Claims)
+	if !ok {
+		log.Error().Str("Trace Id", traceid).Msg("login first")
+		middleware.AbortWithProblem(c, http.StatusUnauthorized, "")
+		return
+	}
+
+	cid, err := pathID(c, "id")
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+
+	err = h.service.DeleteCompany(ctx, claims, cid)
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
 
+	c.JSON(http.StatusOK, gin.H{
+		"message": "company deleted",
+	})
+}
+
+func (h *handler) RestoreCompany(c *gin.Context) {
+	ctx := c.Request.Context()
+	traceid, ok := ctx.Value(middleware.TraceIDKey).(string)
+	if !ok {
+		log.Error().Msg("traceid missing from context")
+		middleware.AbortWithProblem(c, http.StatusInternalServerError, "")
+		return
+	}
+	_, ok = ctx.Value(auth.Key).(auth.Claims)
+	if !ok {
+		log.Error().Str("Trace Id", traceid).Msg("login first")
+		middleware.AbortWithProblem(c, http.StatusUnauthorized, "")
+		return
+	}
+
+	cid, err := pathID(c, "id")
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+
+	companyData, err := h.service.RestoreCompany(ctx, cid)
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, companyData)
 }
@@ -11,7 +11,6 @@ import (
 	"github.com/afthaab/job-portal/internal/service"
 	"github.com/gin-gonic/gin"
 	"github.com/go-playground/assert/v2"
-	"github.com/golang-jwt/jwt/v5"
 )
 
 func Test_handler_ViewCompany(t *testing.T) {
@@ -32,7 +31,7 @@ func Test_handler_ViewCompany(t *testing.T) {
 				return c, rr, nil
 			},
 			expectedStatusCode: http.StatusInternalServerError,
-			expectedResponse:   `{"error":"Internal Server Error"}`,
+			expectedResponse:   `{"type":"about:blank","title":"Internal Server Error","status":500}`,
 		},
 		{
 			name: "misssing jwt claims",
@@ -48,7 +47,7 @@ func Test_handler_ViewCompany(t *testing.T) {
 				return c, rr, nil
 			},
 			expectedStatusCode: http.StatusUnauthorized,
-			expectedResponse:   `{"error":"Unauthorized"}`,
+			expectedResponse:   `{"type":"about:blank","title":"Unauthorized","status":401,"trace_id":"123"}`,
 		},
 		{
 			name: "invalid job id",
@@ -58,7 +57,7 @@ func Test_handler_ViewCompany(t *testing.T) {
 				httpRequest, _ := http.NewRequest(http.MethodGet, "http://test.com:8080", nil)
 				ctx := httpRequest.Context()
 				ctx = context.WithValue(ctx, middleware.TraceIDKey, "123")
-				ctx = context.WithValue(ctx, auth.Key, jwt.RegisteredClaims{})
+				ctx = context.WithValue(ctx, auth.Key, auth.Claims{})
 				httpRequest = httpRequest.WithContext(ctx)
 				c.Params = append(c.Params, gin.Param{Key: "id", Value: "abc"})
 
@@ -67,7 +66,7 @@ func Test_handler_ViewCompany(t *testing.T) {
 				return c, rr, nil
 			},
 			expectedStatusCode: http.StatusBadRequest,
-			expectedResponse:   `{"error":"Bad Request"}`,
+			expectedResponse:   `{"type":"about:blank","title":"Bad Request","status":400,"detail":"invalid path parameter","trace_id":"123","errors":[{"field":"id","code":"invalid_id","message":"must be a positive number"}]}`,
 		},
 		// {
 		// 	name: "error while fetching jobs from service",
@@ -77,7 +76,7 @@ func Test_handler_ViewCompany(t *testing.T) {
 		// 		httpRequest, _ := http.NewRequest(http.MethodGet, "http://test.com:8080", nil)
 		// 		ctx := httpRequest.Context()
 		// 		ctx = context.WithValue(ctx, middleware.TraceIDKey, "123")
-		// 		ctx = context.WithValue(ctx, auth.Key, jwt.RegisteredClaims{})
+		// 		ctx = context.WithValue(ctx, auth.Key, auth.Claims{})
 		// 		httpRequest = httpRequest.WithContext(ctx)
 		// 		c.Request = httpRequest
 		// 		c.Params = append(c.Params, gin.Param{Key: "id", Value: "123"})
@@ -99,7 +98,7 @@ func Test_handler_ViewCompany(t *testing.T) {
 		// 		httpRequest, _ := http.NewRequest(http.MethodGet, "http://test.com:8080", nil)
 		// 		ctx := httpRequest.Context()
 		// 		ctx = context.WithValue(ctx, middleware.TraceIDKey, "123")
-		// 		ctx = context.WithValue(ctx, auth.Key, jwt.RegisteredClaims{})
+		// 		ctx = context.WithValue(ctx, auth.Key, auth.Claims{})
 		// 		httpRequest = httpRequest.WithContext(ctx)
 		// 		c.Request = httpRequest
 		// 		c.Params = append(c.Params, gin.Param{Key: "id", Value: "123"})
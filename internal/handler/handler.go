@@ -14,15 +14,47 @@ type handler struct {
 type Handerfuncs interface {
 	Signin(c *gin.Context)
 	SignUp(c *gin.Context)
+	VerifyEmail(c *gin.Context)
+	ResendVerification(c *gin.Context)
+	ForgotPassword(c *gin.Context)
+	ResetPassword(c *gin.Context)
+	RefreshToken(c *gin.Context)
+	Logout(c *gin.Context)
+	ViewProfile(c *gin.Context)
+	UpdateProfile(c *gin.Context)
+	ChangePassword(c *gin.Context)
+	ChangeEmail(c *gin.Context)
+	DeleteAccount(c *gin.Context)
 
 	ViewCompany(c *gin.Context)
 	ViewAllCompanies(c *gin.Context)
 	AddCompany(c *gin.Context)
+	UpdateCompany(c *gin.Context)
+	PatchCompany(c *gin.Context)
+	DeleteCompany(c *gin.Context)
+	RestoreCompany(c *gin.Context)
+	InviteMember(c *gin.Context)
+	ViewCompanyMembers(c *gin.Context)
+	RemoveMember(c *gin.Context)
+	ViewMyInvitations(c *gin.Context)
+	AcceptInvitation(c *gin.Context)
 
 	ViewJobByID(c *gin.Context)
 	ViewAllJobs(c *gin.Context)
 	ViewJob(c *gin.Context)
 	AddJobs(c *gin.Context)
+	UpdateJob(c *gin.Context)
+	PatchJob(c *gin.Context)
+	DeleteJob(c *gin.Context)
+	CloseJob(c *gin.Context)
+	ReopenJob(c *gin.Context)
+	SearchJobs(c *gin.Context)
+
+	ApplyForJob(c *gin.Context)
+	WithdrawApplication(c *gin.Context)
+	ViewMyApplications(c *gin.Context)
+	ViewJobApplicants(c *gin.Context)
+	UpdateApplicationStatus(c *gin.Context)
 }
 
 func NewHandler(svc service.UserService) (Handerfuncs, error) {
@@ -0,0 +1,29 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/afthaab/job-portal/internal/health"
+	"github.com/gin-gonic/gin"
+)
+
+// Healthz is the liveness probe, it only tells that the process still serves requests
+// so a database outage does not get every instance restarted
+func Healthz(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{
+		"status": health.StatusOK,
+	})
+}
+
+// Readyz is the readiness probe, the load balancer only sends requests while every dependency check passes
+func Readyz(checker *health.Checker) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		report := checker.Ready(c.Request.Context())
+		status := http.StatusOK
+		if report.Status != health.StatusReady {
+			status = http.StatusServiceUnavailable
+		}
+		c.Header("Cache-Control", "no-store")
+		c.JSON(status, report)
+	}
+}
@@ -0,0 +1,63 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/afthaab/job-portal/internal/health"
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/assert/v2"
+)
+
+func TestReadyz(t *testing.T) {
+	tests := []struct {
+		name               string
+		check              health.CheckFunc
+		shutdown           bool
+		expectedStatusCode int
+		expectedStatus     string
+	}{
+		{
+			name:               "ready",
+			check:              func(ctx context.Context) (string, error) { return "", nil },
+			expectedStatusCode: http.StatusOK,
+			expectedStatus:     `"status":"ready"`,
+		},
+		{
+			name:               "database down",
+			check:              func(ctx context.Context) (string, error) { return "", errors.New("connection refused") },
+			expectedStatusCode: http.StatusServiceUnavailable,
+			expectedStatus:     `"status":"not_ready"`,
+		},
+		{
+			name:               "shutting down",
+			check:              func(ctx context.Context) (string, error) { return "", nil },
+			shutdown:           true,
+			expectedStatusCode: http.StatusServiceUnavailable,
+			expectedStatus:     `{"status":"shutting_down"}`,
+		},
+	}
+	gin.SetMode(gin.TestMode)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			checker := health.NewChecker(time.Second)
+			checker.Add("database", tt.check)
+			if tt.shutdown {
+				checker.Shutdown()
+			}
+
+			rec := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(rec)
+			c.Request = httptest.NewRequest(http.MethodGet, "/readyz", nil)
+			Readyz(checker)(c)
+
+			assert.Equal(t, tt.expectedStatusCode, rec.Code)
+			assert.Equal(t, true, strings.Contains(rec.Body.String(), tt.expectedStatus))
+		})
+	}
+}
@@ -1,15 +1,12 @@
 package handler
 
 import (
-	"encoding/json"
 	"net/http"
-	"strconv"
 
 	"github.com/afthaab/job-portal/internal/auth"
 	"github.com/afthaab/job-portal/internal/middleware"
 	"github.com/afthaab/job-portal/internal/models"
 	"github.com/gin-gonic/gin"
-	"github.com/golang-jwt/jwt/v5"
 	"github.com/rs/zerolog/log"
 )
 
@@ -18,31 +15,25 @@ func (h *handler) ViewJobByID(c *gin.Context) {
 	traceid, ok := ctx.Value(middleware.TraceIDKey).(string)
 	if !ok {
 		log.Error().Msg("traceid missing from context")
-		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{
-			"error": http.StatusText(http.StatusInternalServerError),
-		})
+		middleware.AbortWithProblem(c, http.StatusInternalServerError, "")
 		return
 	}
-	_, ok = ctx.Value(auth.Key).(jwt.RegisteredClaims)
+	claims, ok := ctx.Value(auth.Key).(auth.Claims)
 	if !ok {
 		log.Error().Str("Trace Id", traceid).Msg("login first")
-		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": http.StatusText(http.StatusUnauthorized)})
+		middleware.AbortWithProblem(c, http.StatusUnauthorized, "")
 		return
 	}
 
-	id := c.Param("id")
-
-	jid, err := strconv.ParseUint(id, 10, 64)
+	jid, err := pathID(c, "id")
 	if err != nil {
-		c.AbortWithStatusJSON(http.StatusBadRequest, http.StatusText(http.StatusBadRequest))
+		abortWithError(c, err)
+		return
 	}
 
-	jobData, err := h.service.ViewJobById(ctx, jid)
+	jobData, err := h.service.ViewJobById(ctx, claims, jid)
 	if err != nil {
-		log.Error().Err(err).Str("trace id", traceid)
-		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
-			"error": err.Error(),
-		})
+		abortWithError(c, err)
 		return
 	}
 
@@ -55,23 +46,30 @@ func (h *handler) ViewAllJobs(c *gin.Context) {
 	traceid, ok := ctx.Value(middleware.TraceIDKey).(string)
 	if !ok {
 		log.Error().Msg("traceid missing from context")
-		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{
-			"error": http.StatusText(http.StatusInternalServerError),
-		})
+		middleware.AbortWithProblem(c, http.StatusInternalServerError, "")
 		return
 	}
-	_, ok = ctx.Value(auth.Key).(jwt.RegisteredClaims)
+	claims, ok := ctx.Value(auth.Key).(auth.Claims)
 	if !ok {
 		log.Error().Str("Trace Id", traceid).Msg("login first")
-		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": http.StatusText(http.StatusUnauthorized)})
+		middleware.AbortWithProblem(c, http.StatusUnauthorized, "")
 		return
 	}
-	jobDatas, err := h.service.ViewAllJobs(ctx)
+	page, err := pageQuery(c)
 	if err != nil {
-		log.Error().Err(err).Str("trace id", traceid)
-		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
-			"error": err.Error(),
-		})
+		abortWithError(c, err)
+		return
+	}
+
+	filter, err := jobFilter(c)
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+
+	jobDatas, err := h.service.ViewAllJobs(ctx, claims, filter, page)
+	if err != nil {
+		abortWithError(c, err)
 		return
 	}
 
@@ -84,31 +82,31 @@ func (h *handler) ViewJob(c *gin.Context) {
 	traceid, ok := ctx.Value(middleware.TraceIDKey).(string)
 	if !ok {
 		log.Error().Msg("traceid missing from context")
-		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{
-			"error": http.StatusText(http.StatusInternalServerError),
-		})
+		middleware.AbortWithProblem(c, http.StatusInternalServerError, "")
 		return
 	}
-	_, ok = ctx.Value(auth.Key).(jwt.RegisteredClaims)
+	claims, ok := ctx.Value(auth.Key).(auth.Claims)
 	if !ok {
 		log.Error().Str("Trace Id", traceid).Msg("login first")
-		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": http.StatusText(http.StatusUnauthorized)})
+		middleware.AbortWithProblem(c, http.StatusUnauthorized, "")
 		return
 	}
 
-	id := c.Param("id")
+	cid, err := pathID(c, "id")
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
 
-	cid, err := strconv.ParseUint(id, 10, 64)
+	page, err := pageQuery(c)
 	if err != nil {
-		c.AbortWithStatusJSON(http.StatusBadRequest, http.StatusText(http.StatusBadRequest))
+		abortWithError(c, err)
+		return
 	}
 
-	jobData, err := h.service.ViewJob(ctx, cid)
+	jobData, err := h.service.ViewJob(ctx, claims, cid, page)
 	if err != nil {
-		log.Error().Err(err).Str("trace id", traceid)
-		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
-			"error": err.Error(),
-		})
+		abortWithError(c, err)
 		return
 	}
 
@@ -121,45 +119,212 @@ func (h *handler) AddJobs(c *gin.Context) {
 	traceid, ok := ctx.Value(middleware.TraceIDKey).(string)
 	if !ok {
 		log.Error().Msg("traceid missing from context")
-		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{
-			"error": http.StatusText(http.StatusInternalServerError),
-		})
+		middleware.AbortWithProblem(c, http.StatusInternalServerError, "")
 		return
 	}
-	_, ok = ctx.Value(auth.Key).(jwt.RegisteredClaims)
+	claims, ok := ctx.Value(auth.Key).(auth.Claims)
 	if !ok {
 		log.Error().Str("Trace Id", traceid).Msg("login first")
-		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": http.StatusText(http.StatusUnauthorized)})
+		middleware.AbortWithProblem(c, http.StatusUnauthorized, "")
+		return
+	}
+
+	cid, err := pathID(c, "cid")
+	if err != nil {
+		abortWithError(c, err)
 		return
 	}
 
-	id := c.Param("cid")
+	var jobData models.NewJob
+
+	err = bindJSON(c, &jobData)
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
 
-	cid, err := strconv.ParseUint(id, 10, 64)
+	jobDetails, err := h.service.AddJobDetails(ctx, claims, jobData, cid)
 	if err != nil {
-		c.AbortWithStatusJSON(http.StatusBadRequest, http.StatusText(http.StatusBadRequest))
+		abortWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, jobDetails)
+
+}
+
+func (h *handler) UpdateJob(c *gin.Context) {
+	ctx := c.Request.Context()
+	traceid, ok := ctx.Value(middleware.TraceIDKey).(string)
+	if !ok {
+		log.Error().Msg("traceid missing from context")
+		middleware.AbortWithProblem(c, http.StatusInternalServerError, "")
+		return
+	}
+	claims, ok := ctx.Value(auth.Key).(auth.Claims)
+	if !ok {
+		log.Error().Str("Trace Id", traceid).Msg("login first")
+		middleware.AbortWithProblem(c, http.StatusUnauthorized, "")
+		return
+	}
+
+	jid, err := pathID(c, "id")
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+
+	var jobData models.NewJob
+
+	err = bindJSON(c, &jobData)
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+
+	jobDetails, err := h.service.UpdateJobDetails(ctx, claims, jid, jobData)
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, jobDetails)
+}
+
+func (h *handler) PatchJob(c *gin.Context) {
+	ctx := c.Request.Context()
+	traceid, ok := ctx.Value(middleware.TraceIDKey).(string)
+	if !ok {
+		log.Error().Msg("traceid missing from context")
+		middleware.AbortWithProblem(c, http.StatusInternalServerError, "")
+		return
+	}
+	claims, ok := ctx.Value(auth.Key).(auth.Claims)
+	if !ok {
+		log.Error().Str("Trace Id", traceid).Msg("login first")
+		middleware.AbortWithProblem(c, http.StatusUnauthorized, "")
+		return
+	}
+
+	jid, err := pathID(c, "id")
+	if err != nil {
+		abortWithError(c, err)
+		return
 	}
 
-	var jobData models.Jobs
+	var jobData models.UpdateJob
 
-	err = json.NewDecoder(c.Request.Body).Decode(&jobData)
+	err = bindJSON(c, &jobData)
 	if err != nil {
-		log.Error().Err(err).Str("trace id", traceid)
-		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
-			"error": "please provide valid name, location and field",
-		})
+		abortWithError(c, err)
 		return
 	}
 
-	jobData, err = h.service.AddJobDetails(ctx, jobData, cid)
+	updatedData, err := h.service.PatchJobDetails(ctx, claims, jid, jobData)
 	if err != nil {
-		log.Error().Err(err).Str("trace id", traceid)
-		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
-			"error": err.Error(),
-		})
+		abortWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, updatedData)
+}
+
+func (h *handler) DeleteJob(c *gin.Context) {
+	ctx := c.Request.Context()
+	traceid, ok := ctx.Value(middleware.TraceIDKey).(string)
+	if !ok {
+		log.Error().Msg("traceid missing from context")
+		middleware.AbortWithProblem(c, http.StatusInternalServerError, "")
+		return
+	}
+	claims, ok := ctx.Value(auth.Key).(auth.Claims)
+	if !ok {
+		log.Error().Str("Trace Id", traceid).Msg("login first")
+		middleware.AbortWithProblem(c, http.StatusUnauthorized, "")
+		return
+	}
+
+	jid, err := pathID(c, "id")
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+
+	err = h.service.DeleteJob(ctx, claims, jid)
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "job deleted",
+	})
+}
+
+func (h *handler) CloseJob(c *gin.Context) {
+	h.changeJobStatus(c, models.JobClosed)
+}
+
+func (h *handler) ReopenJob(c *gin.Context) {
+	h.changeJobStatus(c, models.JobOpen)
+}
+
+func (h *handler) changeJobStatus(c *gin.Context, status string) {
+	ctx := c.Request.Context()
+	traceid, ok := ctx.Value(middleware.TraceIDKey).(string)
+	if !ok {
+		log.Error().Msg("traceid missing from context")
+		middleware.AbortWithProblem(c, http.StatusInternalServerError, "")
+		return
+	}
+	claims, ok := ctx.Value(auth.Key).(auth.Claims)
+	if !ok {
+		log.Error().Str("Trace Id", traceid).Msg("login first")
+		middleware.AbortWithProblem(c, http.StatusUnauthorized, "")
+		return
+	}
+
+	jid, err := pathID(c, "id")
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+
+	jobData, err := h.service.UpdateJobStatus(ctx, claims, jid, status)
+	if err != nil {
+		abortWithError(c, err)
 		return
 	}
 
 	c.JSON(http.StatusOK, jobData)
+}
+
+func (h *handler) SearchJobs(c *gin.Context) {
+	ctx := c.Request.Context()
+	traceid, ok := ctx.Value(middleware.TraceIDKey).(string)
+	if !ok {
+		log.Error().Msg("traceid missing from context")
+		middleware.AbortWithProblem(c, http.StatusInternalServerError, "")
+		return
+	}
+	claims, ok := ctx.Value(auth.Key).(auth.Claims)
+	if !ok {
+		log.Error().Str("Trace Id", traceid).Msg("login first")
+		middleware.AbortWithProblem(c, http.StatusUnauthorized, "")
+		return
+	}
+
+	page, err := pageQuery(c)
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+
+	results, err := h.service.SearchJobs(ctx, claims, c.Query("q"), page)
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
 
+	c.JSON(http.StatusOK, results)
 }
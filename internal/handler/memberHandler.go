@@ -0,0 +1,176 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/afthaab/job-portal/internal/auth"
+	"github.com/afthaab/job-portal/internal/middleware"
+	"github.com/afthaab/job-portal/internal/models"
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog/log"
+)
+
+func (h *handler) InviteMember(c *gin.Context) {
+	ctx := c.Request.Context()
+	traceid, ok := ctx.Value(middleware.TraceIDKey).(string)
+	if !ok {
+		log.Error().Msg("traceid missing from context")
+		middleware.AbortWithProblem(c, http.StatusInternalServerError, "")
+		return
+	}
+	claims, ok := ctx.Value(auth.Key).(auth.Claims)
+	if !ok {
+		log.Error().Str("Trace Id", traceid).Msg("login first")
+		middleware.AbortWithProblem(c, http.StatusUnauthorized, "")
+		return
+	}
+
+	cid, err := pathID(c, "id")
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+
+	var invitationData models.NewInvitation
+
+	err = bindJSON(c, &invitationData)
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+
+	invitation, err := h.service.InviteMember(ctx, claims, cid, invitationData)
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, invitation)
+}
+
+func (h *handler) ViewCompanyMembers(c *gin.Context) {
+	ctx := c.Request.Context()
+	traceid, ok := ctx.Value(middleware.TraceIDKey).(string)
+	if !ok {
+		log.Error().Msg("traceid missing from context")
+		middleware.AbortWithProblem(c, http.StatusInternalServerError, "")
+		return
+	}
+	claims, ok := ctx.Value(auth.Key).(auth.Claims)
+	if !ok {
+		log.Error().Str("Trace Id", traceid).Msg("login first")
+		middleware.AbortWithProblem(c, http.StatusUnauthorized, "")
+		return
+	}
+
+	cid, err := pathID(c, "id")
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+
+	page, err := pageQuery(c)
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+
+	memberDatas, err := h.service.ViewCompanyMembers(ctx, claims, cid, page)
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, memberDatas)
+}
+
+func (h *handler) RemoveMember(c *gin.Context) {
+	ctx := c.Request.Context()
+	traceid, ok := ctx.Value(middleware.TraceIDKey).(string)
+	if !ok {
+		log.Error().Msg("traceid missing from context")
+		middleware.AbortWithProblem(c, http.StatusInternalServerError, "")
+		return
+	}
+	claims, ok := ctx.Value(auth.Key).(auth.Claims)
+	if !ok {
+		log.Error().Str("Trace Id", traceid).Msg("login first")
+		middleware.AbortWithProblem(c, http.StatusUnauthorized, "")
+		return
+	}
+
+	cid, err := pathID(c, "id")
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+	uid, err := pathID(c, "uid")
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+
+	err = h.service.RemoveMember(ctx, claims, cid, uid)
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "member removed",
+	})
+}
+
+func (h *handler) ViewMyInvitations(c *gin.Context) {
+	ctx := c.Request.Context()
+	traceid, ok := ctx.Value(middleware.TraceIDKey).(string)
+	if !ok {
+		log.Error().Msg("traceid missing from context")
+		middleware.AbortWithProblem(c, http.StatusInternalServerError, "")
+		return
+	}
+	claims, ok := ctx.Value(auth.Key).(auth.Claims)
+	if !ok {
+		log.Error().Str("Trace Id", traceid).Msg("login first")
+		middleware.AbortWithProblem(c, http.StatusUnauthorized, "")
+		return
+	}
+
+	invitationDatas, err := h.service.ViewMyInvitations(ctx, claims)
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, invitationDatas)
+}
+
+func (h *handler) AcceptInvitation(c *gin.Context) {
+	ctx := c.Request.Context()
+	traceid, ok := ctx.Value(middleware.TraceIDKey).(string)
+	if !ok {
+		log.Error().Msg("traceid missing from context")
+		middleware.AbortWithProblem(c, http.StatusInternalServerError, "")
+		return
+	}
+	claims, ok := ctx.Value(auth.Key).(auth.Claims)
+	if !ok {
+		log.Error().Str("Trace Id", traceid).Msg("login first")
+		middleware.AbortWithProblem(c, http.StatusUnauthorized, "")
+		return
+	}
+
+	iid, err := pathID(c, "id")
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+
+	accepted, err := h.service.AcceptInvitation(ctx, claims, iid)
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, accepted)
+}
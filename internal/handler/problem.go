@@ -0,0 +1,57 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/afthaab/job-portal/internal/apperr"
+	"github.com/afthaab/job-portal/internal/middleware"
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog/log"
+)
+
+// statusOf is the one place where the kind of a domain error is turned into an http status
+func statusOf(err error) int {
+	switch apperr.KindOf(err) {
+	case apperr.KindNotFound:
+		return http.StatusNotFound
+	case apperr.KindConflict:
+		return http.StatusConflict
+	case apperr.KindValidation:
+		return http.StatusBadRequest
+	case apperr.KindUnauthorized:
+		return http.StatusUnauthorized
+	case apperr.KindForbidden:
+		return http.StatusForbidden
+	case apperr.KindTooLarge:
+		return http.StatusRequestEntityTooLarge
+	case apperr.KindCanceled:
+		return middleware.StatusClientClosedRequest
+	case apperr.KindTimeout:
+		return http.StatusGatewayTimeout
+	case apperr.KindTooManyRequests:
+		return http.StatusTooManyRequests
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// abortWithError ends the request with the problem matching a service error,
+// the message of an internal error is only logged so nothing about the database leaks out
+func abortWithError(c *gin.Context, err error) {
+	traceid, _ := c.Request.Context().Value(middleware.TraceIDKey).(string)
+	status := statusOf(err)
+	detail := err.Error()
+	switch {
+	case status == http.StatusInternalServerError:
+		log.Error().Err(err).Str("trace id", traceid).Send()
+		detail = "something went wrong, please try again later"
+	case status >= http.StatusInternalServerError:
+		log.Error().Err(err).Str("trace id", traceid).Int("status", status).Send()
+	default:
+		log.Info().Err(err).Str("trace id", traceid).Int("status", status).Send()
+	}
+	if wait := apperr.RetryAfterOf(err); wait > 0 {
+		middleware.SetRetryAfter(c, wait)
+	}
+	middleware.AbortWithProblem(c, status, detail, apperr.FieldsOf(err)...)
+}
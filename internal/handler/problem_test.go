@@ -0,0 +1,37 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/afthaab/job-portal/internal/apperr"
+)
+
+func Test_statusOf(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want int
+	}{
+		{name: "not found", err: apperr.NotFound("could not find the company"), want: http.StatusNotFound},
+		{name: "conflict", err: apperr.Conflict("email is already taken"), want: http.StatusConflict},
+		{name: "validation", err: apperr.Validation("nothing to update"), want: http.StatusBadRequest},
+		{name: "unauthorized", err: apperr.Unauthorized("invalid refresh token"), want: http.StatusUnauthorized},
+		{name: "forbidden", err: apperr.Forbidden("you are not allowed to manage this company"), want: http.StatusForbidden},
+		{name: "canceled", err: apperr.Canceled("the request was canceled"), want: 499},
+		{name: "timeout", err: apperr.Timeout("the database took too long to answer"), want: http.StatusGatewayTimeout},
+		{name: "too many requests", err: apperr.TooManyRequests("too many failed sign ins", time.Minute), want: http.StatusTooManyRequests},
+		{name: "wrapped", err: fmt.Errorf("job 1 : %w", apperr.NotFound("could not find the job")), want: http.StatusNotFound},
+		{name: "plain error", err: errors.New("connection refused"), want: http.StatusInternalServerError},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := statusOf(tt.err); got != tt.want {
+				t.Errorf("statusOf() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
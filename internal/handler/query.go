@@ -0,0 +1,111 @@
+package handler
+
+import (
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/afthaab/job-portal/internal/apperr"
+	"github.com/afthaab/job-portal/internal/models"
+	"github.com/afthaab/job-portal/internal/pkg"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	defaultPageLimit = 20
+	maxPageLimit     = 100
+)
+
+// pageQuery reads the limit, page or cursor and sort query parameters of a list endpoint,
+// a sort field prefixed with - sorts in descending order
+func pageQuery(c *gin.Context) (models.PageQuery, error) {
+	page := models.PageQuery{
+		Limit: defaultPageLimit,
+	}
+
+	if limit := c.Query("limit"); limit != "" {
+		l, err := strconv.Atoi(limit)
+		if err != nil || l < 1 || l > maxPageLimit {
+			return models.PageQuery{}, invalidQuery("limit", codeOutOfRange, "must be between 1 and 100")
+		}
+		page.Limit = l
+	}
+
+	cursor := c.Query("cursor")
+	pageNumber := c.Query("page")
+	switch {
+	case cursor != "" && pageNumber != "":
+		return models.PageQuery{}, invalidQuery("cursor", codeNotAllowed, "can not be used together with page")
+	case cursor != "":
+		offset, err := pkg.DecodeCursor(cursor)
+		if err != nil {
+			return models.PageQuery{}, invalidQuery("cursor", codeInvalid, err.Error())
+		}
+		page.Offset = offset
+	case pageNumber != "":
+		p, err := strconv.Atoi(pageNumber)
+		if err != nil || p < 1 {
+			return models.PageQuery{}, invalidQuery("page", codeTooSmall, "must be a positive number")
+		}
+		// the offset goes to the database and the in memory search, it must not overflow
+		if p > math.MaxInt32/page.Limit {
+			return models.PageQuery{}, invalidQuery("page", codeTooLarge, "is too large")
+		}
+		page.Offset = (p - 1) * page.Limit
+	}
+
+	sort := c.Query("sort")
+	if strings.HasPrefix(sort, "-") {
+		page.Desc = true
+		sort = strings.TrimPrefix(sort, "-")
+	}
+	page.Sort = sort
+
+	return page, nil
+}
+
+func jobFilter(c *gin.Context) (models.JobFilter, error) {
+	filter := models.JobFilter{
+		Location:  c.Query("location"),
+		Field:     c.Query("field"),
+		Currency:  strings.ToUpper(c.Query("currency")),
+		PayPeriod: c.Query("pay_period"),
+	}
+
+	if company := c.Query("company"); company != "" {
+		cid, err := strconv.ParseUint(company, 10, 64)
+		if err != nil {
+			return models.JobFilter{}, invalidQuery("company", codeInvalidID, "must be a company id")
+		}
+		filter.Cid = cid
+	}
+
+	if minSalary := c.Query("min_salary"); minSalary != "" {
+		salary, err := strconv.ParseInt(minSalary, 10, 64)
+		if err != nil {
+			return models.JobFilter{}, invalidQuery("min_salary", codeInvalidType, "must be a number")
+		}
+		filter.MinSalary = &salary
+	}
+	if maxSalary := c.Query("max_salary"); maxSalary != "" {
+		salary, err := strconv.ParseInt(maxSalary, 10, 64)
+		if err != nil {
+			return models.JobFilter{}, invalidQuery("max_salary", codeInvalidType, "must be a number")
+		}
+		filter.MaxSalary = &salary
+	}
+
+	return filter, nil
+}
+
+func invalidQuery(param, code, message string) error {
+	return apperr.Invalid("invalid query parameter", apperr.FieldError{Field: param, Code: code, Message: message})
+}
+
+func companyFilter(c *gin.Context) models.CompanyFilter {
+	return models.CompanyFilter{
+		Name:     c.Query("name"),
+		Location: c.Query("location"),
+		Field:    c.Query("field"),
+	}
+}
@@ -0,0 +1,82 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/afthaab/job-portal/internal/apperr"
+	"github.com/afthaab/job-portal/internal/models"
+	"github.com/afthaab/job-portal/internal/pkg"
+	"github.com/gin-gonic/gin"
+)
+
+func Test_pageQuery(t *testing.T) {
+	tests := []struct {
+		name       string
+		query      string
+		want       models.PageQuery
+		wantFields []apperr.FieldError
+	}{
+		{
+			name:  "defaults",
+			query: "",
+			want:  models.PageQuery{Limit: defaultPageLimit},
+		},
+		{
+			name:  "page and sort",
+			query: "limit=10&page=3&sort=-created_at",
+			want:  models.PageQuery{Limit: 10, Offset: 20, Sort: "created_at", Desc: true},
+		},
+		{
+			name:  "cursor",
+			query: "cursor=" + pkg.EncodeCursor(40),
+			want:  models.PageQuery{Limit: defaultPageLimit, Offset: 40},
+		},
+		{
+			name:       "limit too large",
+			query:      "limit=101",
+			wantFields: []apperr.FieldError{{Field: "limit", Code: "out_of_range", Message: "must be between 1 and 100"}},
+		},
+		{
+			name:       "page not positive",
+			query:      "page=0",
+			wantFields: []apperr.FieldError{{Field: "page", Code: "too_small", Message: "must be a positive number"}},
+		},
+		{
+			name:       "page overflowing the offset",
+			query:      "limit=100&page=" + strings.Repeat("9", 18),
+			wantFields: []apperr.FieldError{{Field: "page", Code: "too_large", Message: "is too large"}},
+		},
+		{
+			name:       "page and cursor",
+			query:      "page=2&cursor=" + pkg.EncodeCursor(40),
+			wantFields: []apperr.FieldError{{Field: "cursor", Code: "not_allowed", Message: "can not be used together with page"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, _ := gin.CreateTestContext(httptest.NewRecorder())
+			c.Request, _ = http.NewRequest(http.MethodGet, "http://test.com/jobs?"+tt.query, nil)
+
+			got, err := pageQuery(c)
+			if (err != nil) != (tt.wantFields != nil) {
+				t.Fatalf("pageQuery() error = %v, want fields %v", err, tt.wantFields)
+			}
+			if err != nil {
+				if apperr.KindOf(err) != apperr.KindValidation {
+					t.Errorf("pageQuery() kind = %v, want validation", apperr.KindOf(err))
+				}
+				if fields := apperr.FieldsOf(err); !reflect.DeepEqual(fields, tt.wantFields) {
+					t.Errorf("pageQuery() fields = %v, want %v", fields, tt.wantFields)
+				}
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("pageQuery() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
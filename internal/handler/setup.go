@@ -2,14 +2,27 @@ package handler
 
 import (
 	"log"
+	"net/http"
 
 	"github.com/afthaab/job-portal/internal/auth"
+	"github.com/afthaab/job-portal/internal/health"
 	"github.com/afthaab/job-portal/internal/middleware"
+	"github.com/afthaab/job-portal/internal/models"
+	"github.com/afthaab/job-portal/internal/ratelimit"
 	"github.com/afthaab/job-portal/internal/service"
 	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 )
 
-func SetupApi(a auth.Authentication, svc service.UserService) *gin.Engine {
+// RateLimits throttle sign in and sign up, once per client ip and once per email so neither
+// one address trying many accounts nor many addresses trying one account get far
+type RateLimits struct {
+	Store      ratelimit.Store
+	PerIP      ratelimit.Limit
+	PerAccount ratelimit.Limit
+}
+
+func SetupApi(a auth.Authentication, svc service.UserService, checker *health.Checker, limits RateLimits) *gin.Engine {
 	r := gin.New()
 
 	m, err := middleware.NewMiddleware(a)
@@ -22,29 +35,83 @@ func SetupApi(a auth.Authentication, svc service.UserService) *gin.Engine {
 		log.Panic("handlers not setup")
 	}
 
-	r.Use(m.Log(), gin.Recovery())
+	// the probes are registered before the middlewares, they are called every few seconds
+	// and would flood the request log and the request metrics
+	r.GET("/healthz", Healthz)
+	r.GET("/readyz", Readyz(checker))
+
+	r.Use(m.Trace(), m.Log(), m.Metrics(), gin.Recovery())
+
+	// roles allowed on the admin routes, every signed in user can view but only recruiters and admins can make changes
+	viewers := []string{models.RoleCandidate, models.RoleRecruiter, models.RoleAdmin}
+	editors := []string{models.RoleRecruiter, models.RoleAdmin}
+	candidates := []string{models.RoleCandidate}
+	admins := []string{models.RoleAdmin}
+
+	// the ip limit runs first so a client over it does not get its body read for the account limit
+	throttle := func(name string, next gin.HandlerFunc) gin.HandlerFunc {
+		next = m.RateLimit(limits.Store, name+"-account", limits.PerAccount, middleware.ByAccount, next)
+		return m.RateLimit(limits.Store, name+"-ip", limits.PerIP, middleware.ByIP, next)
+	}
 
 	r.GET("/check", m.Authenticate(Check))
+	r.GET("/.well-known/jwks.json", JWKS(a))
 	user := r.Group("/user")
 	{
-		user.POST("/signup", h.SignUp)
-		user.POST("/signin", h.Signin)
+		user.POST("/signup", throttle("signup", h.SignUp))
+		user.POST("/signin", throttle("signin", h.Signin))
+		user.POST("/email/verify", throttle("verify-email", h.VerifyEmail))
+		user.POST("/email/resend", throttle("resend-verification", h.ResendVerification))
+		user.POST("/password/forgot", throttle("forgot-password", h.ForgotPassword))
+		user.POST("/password/reset", throttle("reset-password", h.ResetPassword))
+		user.POST("/token/refresh", h.RefreshToken)
+		user.POST("/logout", m.Authenticate(m.Authorize(h.Logout, viewers...)))
+		user.GET("/me", m.Authenticate(m.Authorize(h.ViewProfile, viewers...)))
+		user.PATCH("/me", m.Authenticate(m.Authorize(h.UpdateProfile, viewers...)))
+		user.DELETE("/me", m.Authenticate(m.Authorize(h.DeleteAccount, viewers...)))
+		user.POST("/me/password", m.Authenticate(m.Authorize(h.ChangePassword, viewers...)))
+		user.POST("/me/email", m.Authenticate(m.Authorize(h.ChangeEmail, viewers...)))
+		user.GET("/applications/view/all", m.Authenticate(m.Authorize(h.ViewMyApplications, candidates...)))
+		user.POST("/applications/withdraw/:id", m.Authenticate(m.Authorize(h.WithdrawApplication, candidates...)))
+		user.GET("/invitations/view/all", m.Authenticate(m.Authorize(h.ViewMyInvitations, viewers...)))
+		user.POST("/invitations/accept/:id", m.Authenticate(m.Authorize(h.AcceptInvitation, viewers...)))
 	}
 	admin := r.Group("/admin")
 	{
 		company := admin.Group("company")
 		{
-			company.POST("/add", m.Authenticate(h.AddCompany))
-			company.GET("/view/all", m.Authenticate(h.ViewAllCompanies))
-			company.GET("/view/:id", m.Authenticate(h.ViewCompany))
-			company.GET("/job/view/:id", m.Authenticate(h.ViewJob))
+			company.POST("/add", m.Authenticate(m.Authorize(h.AddCompany, editors...)))
+			company.GET("/view/all", m.Authenticate(m.Authorize(h.ViewAllCompanies, viewers...)))
+			company.GET("/view/:id", m.Authenticate(m.Authorize(h.ViewCompany, viewers...)))
+			company.GET("/job/view/:id", m.Authenticate(m.Authorize(h.ViewJob, viewers...)))
+			company.PUT("/update/:id", m.Authenticate(m.Authorize(h.UpdateCompany, editors...)))
+			company.PATCH("/update/:id", m.Authenticate(m.Authorize(h.PatchCompany, editors...)))
+			company.DELETE("/delete/:id", m.Authenticate(m.Authorize(h.DeleteCompany, editors...)))
+			company.POST("/restore/:id", m.Authenticate(m.Authorize(h.RestoreCompany, admins...)))
+			// membership is checked again in the service, these only keep out the roles that can never pass
+			company.POST("/members/invite/:id", m.Authenticate(m.Authorize(h.InviteMember, editors...)))
+			company.GET("/members/view/:id", m.Authenticate(m.Authorize(h.ViewCompanyMembers, viewers...)))
+			company.DELETE("/members/remove/:id/:uid", m.Authenticate(m.Authorize(h.RemoveMember, viewers...)))
 		}
 
 		jobs := admin.Group("jobs")
 		{
-			jobs.POST("/add/:cid", m.Authenticate(h.AddJobs))
-			jobs.GET("/view/all", m.Authenticate(h.ViewAllJobs))
-			jobs.GET("/view/:id", m.Authenticate(h.ViewJobByID))
+			jobs.POST("/add/:cid", m.Authenticate(m.Authorize(h.AddJobs, editors...)))
+			jobs.GET("/view/all", m.Authenticate(m.Authorize(h.ViewAllJobs, viewers...)))
+			jobs.GET("/view/:id", m.Authenticate(m.Authorize(h.ViewJobByID, viewers...)))
+			jobs.GET("/search", m.Authenticate(m.Authorize(h.SearchJobs, viewers...)))
+			jobs.PUT("/update/:id", m.Authenticate(m.Authorize(h.UpdateJob, editors...)))
+			jobs.PATCH("/update/:id", m.Authenticate(m.Authorize(h.PatchJob, editors...)))
+			jobs.DELETE("/delete/:id", m.Authenticate(m.Authorize(h.DeleteJob, editors...)))
+			jobs.POST("/close/:id", m.Authenticate(m.Authorize(h.CloseJob, editors...)))
+			jobs.POST("/reopen/:id", m.Authenticate(m.Authorize(h.ReopenJob, editors...)))
+			jobs.POST("/apply/:id", m.Authenticate(m.Authorize(h.ApplyForJob, candidates...)))
+			jobs.GET("/applicants/:id", m.Authenticate(m.Authorize(h.ViewJobApplicants, editors...)))
+		}
+
+		applications := admin.Group("applications")
+		{
+			applications.PUT("/status/:id", m.Authenticate(m.Authorize(h.UpdateApplicationStatus, editors...)))
 		}
 	}
 
@@ -57,3 +124,19 @@ func Check(c *gin.Context) {
 		"Message": "ok",
 	})
 }
+
+// JWKS publishes the public signing keys so other services can verify portal tokens
+func JWKS(a auth.Authentication) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Header("Cache-Control", "public, max-age=300")
+		c.JSON(http.StatusOK, a.JWKS())
+	}
+}
+
+// SetupMetrics serves /metrics for the scrape, it listens apart from the api because the route templates,
+// sign in failures and token failure reasons it counts are not for the public
+func SetupMetrics() http.Handler {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.Handler())
+	return mux
+}
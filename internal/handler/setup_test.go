@@ -0,0 +1,27 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestSetupMetrics(t *testing.T) {
+	tests := []struct {
+		name               string
+		path               string
+		expectedStatusCode int
+	}{
+		{name: "scrape", path: "/metrics", expectedStatusCode: http.StatusOK},
+		{name: "no api routes", path: "/user/me", expectedStatusCode: http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			SetupMetrics().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tt.path, nil))
+			if rec.Code != tt.expectedStatusCode {
+				t.Errorf("GET %s status = %d, want %d", tt.path, rec.Code, tt.expectedStatusCode)
+			}
+		})
+	}
+}
@@ -1,100 +1,373 @@
 package handler
 
 import (
-	"encoding/json"
+	"errors"
 	"net/http"
 
+	"github.com/afthaab/job-portal/internal/auth"
 	"github.com/afthaab/job-portal/internal/middleware"
 	"github.com/afthaab/job-portal/internal/models"
 	"github.com/gin-gonic/gin"
-	"github.com/go-playground/validator/v10"
 	"github.com/rs/zerolog/log"
 )
 
 func (h *handler) Signin(c *gin.Context) {
 	ctx := c.Request.Context()
-	traceid, ok := ctx.Value(middleware.TraceIDKey).(string)
+	_, ok := ctx.Value(middleware.TraceIDKey).(string)
 	if !ok {
 		log.Error().Msg("traceid missing from context")
-		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{
-			"error": http.StatusText(http.StatusInternalServerError),
-		})
+		middleware.AbortWithProblem(c, http.StatusInternalServerError, "")
 		return
 	}
 
-	var userData models.NewUser
+	var userData models.UserSignin
 
-	err := json.NewDecoder(c.Request.Body).Decode(&userData)
+	err := bindJSON(c, &userData)
 	if err != nil {
-		log.Error().Err(err).Str("trace id", traceid)
-		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
-			"error": "please provide valid email and password",
-		})
+		abortWithError(c, err)
 		return
 	}
 
+	tokens, err := h.service.UserSignIn(ctx, userData)
 	if err != nil {
-		log.Error().Err(err).Str("trace id", traceid)
-		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
-			"error": "please provide valid email and password",
-		})
+		abortWithError(c, err)
 		return
 	}
-	token, err := h.service.UserSignIn(ctx, userData)
+	c.JSON(http.StatusOK, tokens)
+
+}
+
+func (h *handler) SignUp(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	_, ok := ctx.Value(middleware.TraceIDKey).(string)
+	if !ok {
+		log.Error().Msg("traceid missing from context")
+		middleware.AbortWithProblem(c, http.StatusInternalServerError, "")
+		return
+	}
+	var userData models.NewUser
+
+	err := bindJSON(c, &userData)
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+
+	userDetails, err := h.service.UserSignup(ctx, userData)
 	if err != nil {
-		log.Error().Err(err).Str("trace id", traceid)
-		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
-			"error": err.Error(),
-		})
+		abortWithError(c, err)
 		return
 	}
-	c.JSON(http.StatusOK, gin.H{
-		"token": token,
-	})
+
+	c.JSON(http.StatusOK, userDetails)
 
 }
 
-func (h *handler) SignUp(c *gin.Context) {
+func (h *handler) RefreshToken(c *gin.Context) {
 	ctx := c.Request.Context()
+	_, ok := ctx.Value(middleware.TraceIDKey).(string)
+	if !ok {
+		log.Error().Msg("traceid missing from context")
+		middleware.AbortWithProblem(c, http.StatusInternalServerError, "")
+		return
+	}
+
+	var refreshData models.RefreshRequest
 
+	err := bindJSON(c, &refreshData)
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+
+	tokens, err := h.service.RefreshToken(ctx, refreshData.RefreshToken)
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, tokens)
+}
+
+func (h *handler) Logout(c *gin.Context) {
+	ctx := c.Request.Context()
 	traceid, ok := ctx.Value(middleware.TraceIDKey).(string)
 	if !ok {
 		log.Error().Msg("traceid missing from context")
-		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{
-			"error": http.StatusText(http.StatusInternalServerError),
-		})
+		middleware.AbortWithProblem(c, http.StatusInternalServerError, "")
 		return
 	}
-	var userData models.NewUser
 
-	err := json.NewDecoder(c.Request.Body).Decode(&userData)
+	claims, ok := ctx.Value(auth.Key).(auth.Claims)
+	if !ok {
+		log.Error().Str("Trace Id", traceid).Msg("login first")
+		middleware.AbortWithProblem(c, http.StatusUnauthorized, "")
+		return
+	}
+
+	// the body is optional, without a refresh token every session of the user is logged out
+	var logoutData models.LogoutRequest
+
+	err := bindJSON(c, &logoutData)
+	if err != nil && !errors.Is(err, errEmptyBody) {
+		abortWithError(c, err)
+		return
+	}
+
+	err = h.service.Logout(ctx, claims, logoutData.RefreshToken)
 	if err != nil {
-		log.Error().Err(err).Str("trace id", traceid)
-		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
-			"error": "please provide valid username, email and password",
-		})
+		abortWithError(c, err)
 		return
 	}
+	c.JSON(http.StatusOK, gin.H{"message": "logged out"})
+}
 
-	validate := validator.New()
-	err = validate.Struct(userData)
+func (h *handler) VerifyEmail(c *gin.Context) {
+	ctx := c.Request.Context()
+	_, ok := ctx.Value(middleware.TraceIDKey).(string)
+	if !ok {
+		log.Error().Msg("traceid missing from context")
+		middleware.AbortWithProblem(c, http.StatusInternalServerError, "")
+		return
+	}
+
+	var verifyData models.VerifyEmailRequest
+
+	err := bindJSON(c, &verifyData)
 	if err != nil {
-		log.Error().Err(err).Str("trace id", traceid)
-		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
-			"error": "please provide valid username, email and password",
-		})
+		abortWithError(c, err)
 		return
 	}
 
-	userDetails, err := h.service.UserSignup(ctx, userData)
+	userDetails, err := h.service.VerifyEmail(ctx, verifyData.Token)
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, userDetails)
+}
+
+func (h *handler) ResendVerification(c *gin.Context) {
+	ctx := c.Request.Context()
+	_, ok := ctx.Value(middleware.TraceIDKey).(string)
+	if !ok {
+		log.Error().Msg("traceid missing from context")
+		middleware.AbortWithProblem(c, http.StatusInternalServerError, "")
+		return
+	}
+
+	var resendData models.ResendVerificationRequest
+
+	err := bindJSON(c, &resendData)
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+
+	err = h.service.ResendVerification(ctx, resendData.Email)
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+	// the same answer whether or not the email belongs to an account
+	c.JSON(http.StatusAccepted, gin.H{"message": "if the email belongs to an unverified account a new link is on its way"})
+}
+
+func (h *handler) ForgotPassword(c *gin.Context) {
+	ctx := c.Request.Context()
+	_, ok := ctx.Value(middleware.TraceIDKey).(string)
+	if !ok {
+		log.Error().Msg("traceid missing from context")
+		middleware.AbortWithProblem(c, http.StatusInternalServerError, "")
+		return
+	}
+
+	var forgotData models.ForgotPasswordRequest
+
+	err := bindJSON(c, &forgotData)
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+
+	err = h.service.ForgotPassword(ctx, forgotData.Email)
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+	// the same answer whether or not the email belongs to an account
+	c.JSON(http.StatusAccepted, gin.H{"message": "if the email belongs to an account a reset link is on its way"})
+}
+
+func (h *handler) ResetPassword(c *gin.Context) {
+	ctx := c.Request.Context()
+	_, ok := ctx.Value(middleware.TraceIDKey).(string)
+	if !ok {
+		log.Error().Msg("traceid missing from context")
+		middleware.AbortWithProblem(c, http.StatusInternalServerError, "")
+		return
+	}
+
+	var resetData models.ResetPasswordRequest
+
+	err := bindJSON(c, &resetData)
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+
+	err = h.service.ResetPassword(ctx, resetData.Token, resetData.Password)
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "password reset, sign in with the new password"})
+}
+
+func (h *handler) ViewProfile(c *gin.Context) {
+	ctx := c.Request.Context()
+	traceid, ok := ctx.Value(middleware.TraceIDKey).(string)
+	if !ok {
+		log.Error().Msg("traceid missing from context")
+		middleware.AbortWithProblem(c, http.StatusInternalServerError, "")
+		return
+	}
+	claims, ok := ctx.Value(auth.Key).(auth.Claims)
+	if !ok {
+		log.Error().Str("Trace Id", traceid).Msg("login first")
+		middleware.AbortWithProblem(c, http.StatusUnauthorized, "")
+		return
+	}
+
+	userDetails, err := h.service.ViewProfile(ctx, claims)
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, userDetails)
+}
+
+func (h *handler) UpdateProfile(c *gin.Context) {
+	ctx := c.Request.Context()
+	traceid, ok := ctx.Value(middleware.TraceIDKey).(string)
+	if !ok {
+		log.Error().Msg("traceid missing from context")
+		middleware.AbortWithProblem(c, http.StatusInternalServerError, "")
+		return
+	}
+	claims, ok := ctx.Value(auth.Key).(auth.Claims)
+	if !ok {
+		log.Error().Str("Trace Id", traceid).Msg("login first")
+		middleware.AbortWithProblem(c, http.StatusUnauthorized, "")
+		return
+	}
+
+	var profileData models.UpdateProfile
+
+	err := bindJSON(c, &profileData)
 	if err != nil {
-		log.Error().Err(err).Str("trace id", traceid)
-		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
-			"error": err.Error(),
-		})
+		abortWithError(c, err)
 		return
 	}
 
+	userDetails, err := h.service.UpdateProfile(ctx, claims, profileData)
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
 	c.JSON(http.StatusOK, userDetails)
+}
 
+func (h *handler) ChangePassword(c *gin.Context) {
+	ctx := c.Request.Context()
+	traceid, ok := ctx.Value(middleware.TraceIDKey).(string)
+	if !ok {
+		log.Error().Msg("traceid missing from context")
+		middleware.AbortWithProblem(c, http.StatusInternalServerError, "")
+		return
+	}
+	claims, ok := ctx.Value(auth.Key).(auth.Claims)
+	if !ok {
+		log.Error().Str("Trace Id", traceid).Msg("login first")
+		middleware.AbortWithProblem(c, http.StatusUnauthorized, "")
+		return
+	}
+
+	var passwordData models.ChangePasswordRequest
+
+	err := bindJSON(c, &passwordData)
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+
+	err = h.service.ChangePassword(ctx, claims, passwordData)
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "password changed, sign in again with the new password"})
+}
+
+func (h *handler) ChangeEmail(c *gin.Context) {
+	ctx := c.Request.Context()
+	traceid, ok := ctx.Value(middleware.TraceIDKey).(string)
+	if !ok {
+		log.Error().Msg("traceid missing from context")
+		middleware.AbortWithProblem(c, http.StatusInternalServerError, "")
+		return
+	}
+	claims, ok := ctx.Value(auth.Key).(auth.Claims)
+	if !ok {
+		log.Error().Str("Trace Id", traceid).Msg("login first")
+		middleware.AbortWithProblem(c, http.StatusUnauthorized, "")
+		return
+	}
+
+	var emailData models.ChangeEmailRequest
+
+	err := bindJSON(c, &emailData)
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+
+	err = h.service.ChangeEmail(ctx, claims, emailData)
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+	// nothing changed yet, the account moves to the new address once the mailed link is followed
+	c.JSON(http.StatusAccepted, gin.H{"message": "follow the link mailed to the new address to finish the change"})
+}
+
+func (h *handler) DeleteAccount(c *gin.Context) {
+	ctx := c.Request.Context()
+	traceid, ok := ctx.Value(middleware.TraceIDKey).(string)
+	if !ok {
+		log.Error().Msg("traceid missing from context")
+		middleware.AbortWithProblem(c, http.StatusInternalServerError, "")
+		return
+	}
+	claims, ok := ctx.Value(auth.Key).(auth.Claims)
+	if !ok {
+		log.Error().Str("Trace Id", traceid).Msg("login first")
+		middleware.AbortWithProblem(c, http.StatusUnauthorized, "")
+		return
+	}
+
+	var deleteData models.DeleteAccountRequest
+
+	err := bindJSON(c, &deleteData)
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+
+	err = h.service.DeleteAccount(ctx, claims, deleteData.CurrentPassword)
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "account deleted"})
 }
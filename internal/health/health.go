@@ -0,0 +1,158 @@
+package health
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/afthaab/job-portal/internal/auth"
+	"github.com/afthaab/job-portal/internal/database"
+	"github.com/rs/zerolog/log"
+)
+
+// statuses of a readiness report and of its checks
+const (
+	StatusReady        = "ready"
+	StatusNotReady     = "not_ready"
+	StatusShuttingDown = "shutting_down"
+	StatusOK           = "ok"
+	StatusFailed       = "failed"
+)
+
+// CheckFunc checks one dependency, the detail is shown in the report when the check passes
+type CheckFunc func(ctx context.Context) (detail string, err error)
+
+// CheckResult is the outcome of one check, the error itself is only logged because the probes are public
+type CheckResult struct {
+	Status    string  `json:"status"`
+	LatencyMs float64 `json:"latency_ms"`
+	Detail    string  `json:"detail,omitempty"`
+	Error     string  `json:"error,omitempty"`
+}
+
+// Report is what /readyz returns
+type Report struct {
+	Status string                 `json:"status"`
+	Checks map[string]CheckResult `json:"checks,omitempty"`
+}
+
+type check struct {
+	name string
+	run  CheckFunc
+}
+
+// Checker runs the readiness checks, it reports not ready from the moment Shutdown is called
+type Checker struct {
+	timeout      time.Duration
+	checks       []check
+	shuttingDown int32
+}
+
+// NewChecker returns a checker that gives every check at most timeout to finish
+func NewChecker(timeout time.Duration) *Checker {
+	return &Checker{timeout: timeout}
+}
+
+// Add registers a check, it is not safe to call once the server is running
+func (c *Checker) Add(name string, run CheckFunc) {
+	c.checks = append(c.checks, check{name: name, run: run})
+}
+
+// Shutdown makes every later report not ready so the load balancer stops sending requests
+func (c *Checker) Shutdown() {
+	atomic.StoreInt32(&c.shuttingDown, 1)
+}
+
+// Ready runs the checks concurrently, the instance is ready when all of them pass
+func (c *Checker) Ready(ctx context.Context) Report {
+	if atomic.LoadInt32(&c.shuttingDown) == 1 {
+		return Report{Status: StatusShuttingDown}
+	}
+
+	results := make([]CheckResult, len(c.checks))
+	var wg sync.WaitGroup
+	for i, chk := range c.checks {
+		wg.Add(1)
+		go func(i int, chk check) {
+			defer wg.Done()
+			results[i] = c.run(ctx, chk)
+		}(i, chk)
+	}
+	wg.Wait()
+
+	report := Report{Status: StatusReady, Checks: make(map[string]CheckResult, len(c.checks))}
+	for i, chk := range c.checks {
+		if results[i].Status != StatusOK {
+			report.Status = StatusNotReady
+		}
+		report.Checks[chk.name] = results[i]
+	}
+	return report
+}
+
+func (c *Checker) run(ctx context.Context, chk check) CheckResult {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	start := time.Now()
+	detail, err := chk.run(ctx)
+	result := CheckResult{
+		Status:    StatusOK,
+		LatencyMs: float64(time.Since(start).Microseconds()) / 1000,
+		Detail:    detail,
+	}
+	if err != nil {
+		log.Error().Err(err).Str("check", chk.name).Msg("readiness check failed")
+		result.Status, result.Detail, result.Error = StatusFailed, "", "check failed"
+		if errors.Is(err, context.DeadlineExceeded) || errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			result.Error = "timed out"
+		}
+	}
+	return result
+}
+
+// Database pings the connection pool
+func Database(db *sql.DB) CheckFunc {
+	return func(ctx context.Context) (string, error) {
+		return "", db.PingContext(ctx)
+	}
+}
+
+// SigningKeys checks that the key ring has a private key to sign the tokens with
+func SigningKeys(keys *auth.KeyRing) CheckFunc {
+	return func(ctx context.Context) (string, error) {
+		current := keys.Current()
+		if current.PrivateKey == nil {
+			return "", errors.New("no signing key loaded")
+		}
+		return "kid " + current.ID, nil
+	}
+}
+
+// Migrations checks that the database has every migration of the binary applied,
+// migrations the database has and the binary does not are fine while a newer version rolls out
+func Migrations(migrator *database.Migrator) CheckFunc {
+	return func(ctx context.Context) (string, error) {
+		statuses, err := migrator.Status(ctx)
+		if err != nil {
+			return "", err
+		}
+		var version int64
+		pending := 0
+		for _, status := range statuses {
+			if status.AppliedAt == nil {
+				pending++
+				continue
+			}
+			version = status.Version
+		}
+		if pending > 0 {
+			return "", fmt.Errorf("%w, %d pending migrations", database.ErrSchemaBehind, pending)
+		}
+		return fmt.Sprintf("schema at version %d", version), nil
+	}
+}
@@ -0,0 +1,103 @@
+package health
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/afthaab/job-portal/internal/auth"
+)
+
+func passing(detail string) CheckFunc {
+	return func(ctx context.Context) (string, error) {
+		return detail, nil
+	}
+}
+
+func TestChecker_Ready(t *testing.T) {
+	failing := func(ctx context.Context) (string, error) {
+		return "", errors.New("connection refused")
+	}
+
+	tests := []struct {
+		name       string
+		checks     map[string]CheckFunc
+		shutdown   bool
+		wantStatus string
+		wantChecks map[string]string
+	}{
+		{
+			name:       "every check passes",
+			checks:     map[string]CheckFunc{"database": passing(""), "signing_keys": passing("kid main")},
+			wantStatus: StatusReady,
+			wantChecks: map[string]string{"database": StatusOK, "signing_keys": StatusOK},
+		},
+		{
+			name:       "one check fails",
+			checks:     map[string]CheckFunc{"database": failing, "signing_keys": passing("kid main")},
+			wantStatus: StatusNotReady,
+			wantChecks: map[string]string{"database": StatusFailed, "signing_keys": StatusOK},
+		},
+		{
+			name:       "shutting down",
+			checks:     map[string]CheckFunc{"database": passing("")},
+			shutdown:   true,
+			wantStatus: StatusShuttingDown,
+			wantChecks: map[string]string{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := NewChecker(20 * time.Millisecond)
+			for name, run := range tt.checks {
+				c.Add(name, run)
+			}
+			if tt.shutdown {
+				c.Shutdown()
+			}
+
+			got := c.Ready(context.Background())
+			if got.Status != tt.wantStatus {
+				t.Errorf("Checker.Ready() status = %v, want %v", got.Status, tt.wantStatus)
+			}
+			if len(got.Checks) != len(tt.wantChecks) {
+				t.Errorf("Checker.Ready() checks = %v, want %v", got.Checks, tt.wantChecks)
+			}
+			for name, want := range tt.wantChecks {
+				if got.Checks[name].Status != want {
+					t.Errorf("Checker.Ready() check %s = %v, want %v", name, got.Checks[name].Status, want)
+				}
+			}
+		})
+	}
+}
+
+func TestChecker_ReadyTimeout(t *testing.T) {
+	c := NewChecker(10 * time.Millisecond)
+	c.Add("database", func(ctx context.Context) (string, error) {
+		<-ctx.Done()
+		return "", ctx.Err()
+	})
+	got := c.Ready(context.Background()).Checks["database"]
+	if got.Status != StatusFailed || got.Error != "timed out" {
+		t.Errorf("Checker.Ready() = %+v, want a timed out check", got)
+	}
+}
+
+func TestSigningKeys(t *testing.T) {
+	privateKey, err := rsa.GenerateKey(rand.Reader, 1024)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ring, err := auth.NewKeyRing(auth.SigningKey{ID: "main", PrivateKey: privateKey})
+	if err != nil {
+		t.Fatal(err)
+	}
+	detail, err := SigningKeys(ring)(context.Background())
+	if err != nil || detail != "kid main" {
+		t.Errorf("SigningKeys() = %v, %v, want kid main", detail, err)
+	}
+}
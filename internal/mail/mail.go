@@ -0,0 +1,94 @@
+// Package mail sends the emails of the portal, the service only sees the Mailer interface
+// so development and the tests can write the messages to disk instead of a mail server.
+package mail
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// Message is a plain text email
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Mailer sends one message, it returns once the message was handed over
+type Mailer interface {
+	Send(ctx context.Context, msg Message) error
+}
+
+// format writes the message the way it goes over the wire, headers then a blank line then the body
+func format(from string, msg Message, date time.Time) []byte {
+	var b strings.Builder
+	fmt.Fprintf(&b, "From: %s\r\n", from)
+	fmt.Fprintf(&b, "To: %s\r\n", msg.To)
+	fmt.Fprintf(&b, "Subject: %s\r\n", msg.Subject)
+	fmt.Fprintf(&b, "Date: %s\r\n", date.Format(time.RFC1123Z))
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	b.WriteString("\r\n")
+	b.WriteString(strings.ReplaceAll(msg.Body, "\n", "\r\n"))
+	return []byte(b.String())
+}
+
+// validate refuses header values with line breaks, they would let a recipient inject headers
+func validate(msg Message) error {
+	if strings.ContainsAny(msg.To, "\r\n") || strings.ContainsAny(msg.Subject, "\r\n") {
+		return fmt.Errorf("mail header contains a line break")
+	}
+	if msg.To == "" {
+		return fmt.Errorf("mail has no recipient")
+	}
+	return nil
+}
+
+// FileMailer writes every message to its own .eml file in a directory, for development and tests
+type FileMailer struct {
+	dir  string
+	from string
+	seq  uint64
+}
+
+func NewFileMailer(dir string, from string) (*FileMailer, error) {
+	err := os.MkdirAll(dir, 0o700)
+	if err != nil {
+		return nil, fmt.Errorf("error in creating the mail dir : %w", err)
+	}
+	return &FileMailer{dir: dir, from: from}, nil
+}
+
+func (m *FileMailer) Send(ctx context.Context, msg Message) error {
+	err := validate(msg)
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	name := fmt.Sprintf("%s-%d.eml", now.Format("20060102T150405.000000000"), atomic.AddUint64(&m.seq, 1))
+	// the messages hold single use tokens so only the owner of the process may read them
+	err = os.WriteFile(filepath.Join(m.dir, name), format(m.from, msg, now), 0o600)
+	if err != nil {
+		return fmt.Errorf("error in writing the mail : %w", err)
+	}
+	return nil
+}
+
+// LogMailer logs the messages instead of sending them, the body is logged too so it is for development only
+type LogMailer struct{}
+
+func (LogMailer) Send(ctx context.Context, msg Message) error {
+	err := validate(msg)
+	if err != nil {
+		return err
+	}
+	log.Info().Str("to", msg.To).Str("subject", msg.Subject).Str("body", msg.Body).Msg("mail not sent, logged instead")
+	return nil
+}
@@ -0,0 +1,99 @@
+package mail
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/smtp"
+	"strconv"
+	"time"
+)
+
+// SMTPConfig is the mail server the SMTPMailer relays through
+type SMTPConfig struct {
+	Host     string
+	Port     int
+	Username string
+	Password string
+	From     string
+}
+
+// SMTPMailer sends every message over its own connection, the volume of the portal does not call for a pool.
+// The connection is upgraded with STARTTLS when the server offers it and the credentials are never sent without it
+type SMTPMailer struct {
+	cfg SMTPConfig
+}
+
+func NewSMTPMailer(cfg SMTPConfig) (*SMTPMailer, error) {
+	if cfg.Host == "" || cfg.From == "" {
+		return nil, fmt.Errorf("smtp host and from address are required")
+	}
+	return &SMTPMailer{cfg: cfg}, nil
+}
+
+func (m *SMTPMailer) Send(ctx context.Context, msg Message) error {
+	err := validate(msg)
+	if err != nil {
+		return err
+	}
+
+	addr := net.JoinHostPort(m.cfg.Host, strconv.Itoa(m.cfg.Port))
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return fmt.Errorf("error in connecting to the mail server : %w", err)
+	}
+	defer conn.Close()
+	// net/smtp does not take a context, the deadline of the connection stands in for it
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		deadline = time.Now().Add(30 * time.Second)
+	}
+	err = conn.SetDeadline(deadline)
+	if err != nil {
+		return err
+	}
+
+	client, err := smtp.NewClient(conn, m.cfg.Host)
+	if err != nil {
+		return fmt.Errorf("error in greeting the mail server : %w", err)
+	}
+	defer client.Close()
+
+	if ok, _ := client.Extension("STARTTLS"); ok {
+		err = client.StartTLS(&tls.Config{ServerName: m.cfg.Host, MinVersion: tls.VersionTLS12})
+		if err != nil {
+			return fmt.Errorf("error in starting tls with the mail server : %w", err)
+		}
+	}
+	if m.cfg.Username != "" {
+		// PlainAuth refuses to send the password over a connection that is not encrypted, except to localhost
+		err = client.Auth(smtp.PlainAuth("", m.cfg.Username, m.cfg.Password, m.cfg.Host))
+		if err != nil {
+			return fmt.Errorf("error in authenticating with the mail server : %w", err)
+		}
+	}
+
+	err = client.Mail(m.cfg.From)
+	if err != nil {
+		return fmt.Errorf("error in sending the mail : %w", err)
+	}
+	err = client.Rcpt(msg.To)
+	if err != nil {
+		return fmt.Errorf("error in sending the mail : %w", err)
+	}
+	w, err := client.Data()
+	if err != nil {
+		return fmt.Errorf("error in sending the mail : %w", err)
+	}
+	_, err = w.Write(format(m.cfg.From, msg, time.Now()))
+	if err != nil {
+		return fmt.Errorf("error in sending the mail : %w", err)
+	}
+	err = w.Close()
+	if err != nil {
+		return fmt.Errorf("error in sending the mail : %w", err)
+	}
+	return client.Quit()
+}
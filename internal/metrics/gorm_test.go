@@ -0,0 +1,48 @@
+package metrics
+
+import (
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+type widget struct {
+	ID   uint
+	Name string
+}
+
+func TestGormPlugin(t *testing.T) {
+	// a dry run builds the statements and runs the callbacks without a database
+	db, err := gorm.Open(postgres.New(postgres.Config{DSN: "host=localhost"}), &gorm.Config{DryRun: true, DisableAutomaticPing: true, SkipDefaultTransaction: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = db.Use(GormPlugin{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	db.Create(&widget{Name: "first"})
+	db.Find(&[]widget{})
+	db.Model(&widget{ID: 1}).Update("name", "second")
+
+	for _, operation := range []string{"create", "query", "update"} {
+		count := histogramCount(t, operation)
+		if count != 1 {
+			t.Errorf("GormPlugin observed %d %s queries, want 1", count, operation)
+		}
+	}
+}
+
+func histogramCount(t *testing.T, operation string) uint64 {
+	t.Helper()
+	metric := &dto.Metric{}
+	err := DBQueryDuration.WithLabelValues(operation, "ok").(prometheus.Histogram).Write(metric)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return metric.GetHistogram().GetSampleCount()
+}
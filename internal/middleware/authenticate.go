@@ -7,7 +7,9 @@ import (
 	"strings"
 
 	"github.com/afthaab/job-portal/internal/auth"
+	"github.com/afthaab/job-portal/internal/metrics"
 	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
 	"github.com/rs/zerolog/log"
 )
 
@@ -19,9 +21,7 @@ func (m *Mid) Authenticate(next gin.HandlerFunc) gin.HandlerFunc {
 		if !ok {
 			log.Error().Msg("trace id not present in the context")
 
-			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{
-				"error": http.StatusText(http.StatusInternalServerError),
-			})
+			AbortWithProblem(c, http.StatusInternalServerError, "")
 			return
 		}
 
@@ -35,15 +35,16 @@ func (m *Mid) Authenticate(next gin.HandlerFunc) gin.HandlerFunc {
 			// If the header format doesn't match required format, log and send an error
 			err := errors.New("expected authorization header format: Bearer <token>")
 			log.Error().Err(err).Str("Trace Id", traceID).Send()
-			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+			metrics.TokenValidationFailures.WithLabelValues(metrics.ReasonMissing).Inc()
+			AbortWithProblem(c, http.StatusUnauthorized, err.Error())
 			return
 		}
+		// an expired, revoked or forged token is a problem with the credentials and not with the server
 		claims, err := m.auth.ValidateToken(parts[1])
 		if err != nil {
 			log.Error().Err(err).Str("trace id", traceID).Send()
-			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{
-				"error": http.StatusText(http.StatusInternalServerError),
-			})
+			metrics.TokenValidationFailures.WithLabelValues(failureReason(err)).Inc()
+			AbortWithProblem(c, http.StatusUnauthorized, "invalid or expired token")
 			return
 		}
 
@@ -58,3 +59,15 @@ func (m *Mid) Authenticate(next gin.HandlerFunc) gin.HandlerFunc {
 
 	}
 }
+
+// failureReason sorts the validation errors into the few reasons the metrics are labelled with
+func failureReason(err error) string {
+	switch {
+	case errors.Is(err, jwt.ErrTokenExpired):
+		return metrics.ReasonExpired
+	case errors.Is(err, auth.ErrTokenRevoked):
+		return metrics.ReasonRevoked
+	default:
+		return metrics.ReasonInvalid
+	}
+}
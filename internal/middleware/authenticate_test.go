@@ -0,0 +1,77 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/afthaab/job-portal/internal/apperr"
+	"github.com/afthaab/job-portal/internal/auth"
+	mockauth "github.com/afthaab/job-portal/internal/auth/mockModels"
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/assert/v2"
+	"go.uber.org/mock/gomock"
+)
+
+func TestMid_Authenticate(t *testing.T) {
+	tests := []struct {
+		name               string
+		header             string
+		mockAuth           func(m *mockauth.MockAuthentication)
+		expectedStatusCode int
+		expectedResponse   string
+	}{
+		{
+			name:               "missing bearer token",
+			header:             "",
+			mockAuth:           func(m *mockauth.MockAuthentication) {},
+			expectedStatusCode: http.StatusUnauthorized,
+			expectedResponse:   `{"type":"about:blank","title":"Unauthorized","status":401,"detail":"expected authorization header format: Bearer \u003ctoken\u003e","instance":"/jobs","trace_id":"123"}`,
+		},
+		{
+			name:   "expired token",
+			header: "Bearer expired",
+			mockAuth: func(m *mockauth.MockAuthentication) {
+				m.EXPECT().ValidateToken("expired").Return(auth.Claims{}, errors.New("token has expired"))
+			},
+			expectedStatusCode: http.StatusUnauthorized,
+			expectedResponse:   `{"type":"about:blank","title":"Unauthorized","status":401,"detail":"invalid or expired token","instance":"/jobs","trace_id":"123"}`,
+		},
+		{
+			name:   "valid token",
+			header: "Bearer valid",
+			mockAuth: func(m *mockauth.MockAuthentication) {
+				m.EXPECT().ValidateToken("valid").Return(auth.Claims{Role: "candidate"}, nil)
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedResponse:   `{"Message":"ok"}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gin.SetMode(gin.TestMode)
+			mc := gomock.NewController(t)
+			ma := mockauth.NewMockAuthentication(mc)
+			tt.mockAuth(ma)
+
+			rr := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(rr)
+			httpRequest, _ := http.NewRequest(http.MethodGet, "http://test.com/jobs", nil)
+			httpRequest.Header.Set("Authorization", tt.header)
+			c.Request = httpRequest.WithContext(context.WithValue(httpRequest.Context(), TraceIDKey, "123"))
+
+			m := &Mid{auth: ma}
+			m.Authenticate(func(c *gin.Context) {
+				c.JSON(http.StatusOK, gin.H{"Message": "ok"})
+			})(c)
+
+			assert.Equal(t, tt.expectedStatusCode, rr.Code)
+			assert.Equal(t, tt.expectedResponse, rr.Body.String())
+			if tt.expectedStatusCode != http.StatusOK {
+				assert.Equal(t, apperr.ProblemContentType, rr.Header().Get("Content-Type"))
+			}
+		})
+	}
+}
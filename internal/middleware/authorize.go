@@ -0,0 +1,41 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/afthaab/job-portal/internal/auth"
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog/log"
+)
+
+// Authorize lets the request through only when the role in the claims is one of the allowed roles,
+// it has to be wrapped by Authenticate so the claims are already present in the context
+func (m *Mid) Authorize(next gin.HandlerFunc, roles ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx := c.Request.Context()
+
+		traceID, ok := ctx.Value(TraceIDKey).(string)
+		if !ok {
+			log.Error().Msg("trace id not present in the context")
+			AbortWithProblem(c, http.StatusInternalServerError, "")
+			return
+		}
+
+		claims, ok := ctx.Value(auth.Key).(auth.Claims)
+		if !ok {
+			log.Error().Str("trace id", traceID).Msg("claims not present in the context")
+			AbortWithProblem(c, http.StatusUnauthorized, "please sign in first")
+			return
+		}
+
+		for _, role := range roles {
+			if claims.Role == role {
+				next(c)
+				return
+			}
+		}
+
+		log.Error().Str("trace id", traceID).Str("role", claims.Role).Msg("role is not allowed to access the route")
+		AbortWithProblem(c, http.StatusForbidden, "your role is not allowed to access this route")
+	}
+}
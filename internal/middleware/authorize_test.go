@@ -0,0 +1,75 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/afthaab/job-portal/internal/auth"
+	"github.com/afthaab/job-portal/internal/models"
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/assert/v2"
+)
+
+func TestMid_Authorize(t *testing.T) {
+	tests := []struct {
+		name               string
+		ctx                func(ctx context.Context) context.Context
+		expectedStatusCode int
+		expectedResponse   string
+	}{
+		{
+			name: "missing trace id",
+			ctx: func(ctx context.Context) context.Context {
+				return ctx
+			},
+			expectedStatusCode: http.StatusInternalServerError,
+			expectedResponse:   `{"type":"about:blank","title":"Internal Server Error","status":500}`,
+		},
+		{
+			name: "missing claims",
+			ctx: func(ctx context.Context) context.Context {
+				return context.WithValue(ctx, TraceIDKey, "123")
+			},
+			expectedStatusCode: http.StatusUnauthorized,
+			expectedResponse:   `{"type":"about:blank","title":"Unauthorized","status":401,"detail":"please sign in first","trace_id":"123"}`,
+		},
+		{
+			name: "role not allowed",
+			ctx: func(ctx context.Context) context.Context {
+				ctx = context.WithValue(ctx, TraceIDKey, "123")
+				return context.WithValue(ctx, auth.Key, auth.Claims{Role: models.RoleCandidate})
+			},
+			expectedStatusCode: http.StatusForbidden,
+			expectedResponse:   `{"type":"about:blank","title":"Forbidden","status":403,"detail":"your role is not allowed to access this route","trace_id":"123"}`,
+		},
+		{
+			name: "role allowed",
+			ctx: func(ctx context.Context) context.Context {
+				ctx = context.WithValue(ctx, TraceIDKey, "123")
+				return context.WithValue(ctx, auth.Key, auth.Claims{Role: models.RoleRecruiter})
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedResponse:   `{"Message":"ok"}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gin.SetMode(gin.TestMode)
+
+			rr := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(rr)
+			httpRequest, _ := http.NewRequest(http.MethodPost, "http://test.com", nil)
+			c.Request = httpRequest.WithContext(tt.ctx(httpRequest.Context()))
+
+			m := &Mid{}
+			m.Authorize(func(c *gin.Context) {
+				c.JSON(http.StatusOK, gin.H{"Message": "ok"})
+			}, models.RoleRecruiter, models.RoleAdmin)(c)
+
+			assert.Equal(t, tt.expectedStatusCode, rr.Code)
+			assert.Equal(t, tt.expectedResponse, rr.Body.String())
+		})
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 	"github.com/rs/zerolog/log"
+	"go.opentelemetry.io/otel/trace"
 )
 
 type key string
@@ -14,10 +15,14 @@ const TraceIDKey key = "1"
 
 func (m *Mid) Log() gin.HandlerFunc {
 	return func(c *gin.Context) {
-		uuidStr := uuid.NewString()
-
 		ctx := c.Request.Context()
 
+		// the trace id of the span started by Trace ties the log lines to the exported trace
+		uuidStr := uuid.NewString()
+		if spanContext := trace.SpanContextFromContext(ctx); spanContext.HasTraceID() {
+			uuidStr = spanContext.TraceID().String()
+		}
+
 		ctx = context.WithValue(ctx, TraceIDKey, uuidStr)
 
 		c.Request = c.Request.WithContext(ctx)
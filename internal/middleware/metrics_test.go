@@ -0,0 +1,32 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/afthaab/job-portal/internal/metrics"
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/assert/v2"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestMid_Metrics(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	m := Mid{}
+	r := gin.New()
+	r.Use(m.Metrics())
+	r.GET("/admin/jobs/view/:id", func(c *gin.Context) {
+		assert.Equal(t, float64(1), testutil.ToFloat64(metrics.HTTPRequestsInFlight))
+		c.Status(http.StatusNotFound)
+	})
+
+	for _, path := range []string{"/admin/jobs/view/1", "/admin/jobs/view/2", "/wp-login.php"} {
+		r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+
+	// the ids share the series of the route template
+	assert.Equal(t, float64(2), testutil.ToFloat64(metrics.HTTPRequests.WithLabelValues(http.MethodGet, "/admin/jobs/view/:id", "404")))
+	assert.Equal(t, float64(1), testutil.ToFloat64(metrics.HTTPRequests.WithLabelValues(http.MethodGet, unmatchedRoute, "404")))
+	assert.Equal(t, float64(0), testutil.ToFloat64(metrics.HTTPRequestsInFlight))
+}
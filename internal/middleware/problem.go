@@ -0,0 +1,45 @@
+package middleware
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/afthaab/job-portal/internal/apperr"
+	"github.com/gin-gonic/gin"
+)
+
+// StatusClientClosedRequest is the nginx status for a request the client hung up on before the answer,
+// nobody reads the body but the logs and the metrics tell these apart from server errors
+const StatusClientClosedRequest = 499
+
+func statusText(status int) string {
+	if status == StatusClientClosedRequest {
+		return "Client Closed Request"
+	}
+	return http.StatusText(status)
+}
+
+// AbortWithProblem ends the request with an RFC 7807 problem body that carries the trace id of the request
+// and the details of the invalid fields if there are any
+func AbortWithProblem(c *gin.Context, status int, detail string, fields ...apperr.FieldError) {
+	traceID, _ := c.Request.Context().Value(TraceIDKey).(string)
+
+	// gin keeps a content type that is already set instead of its json default
+	c.Header("Content-Type", apperr.ProblemContentType)
+	c.AbortWithStatusJSON(status, apperr.Problem{
+		Type:     "about:blank",
+		Title:    statusText(status),
+		Status:   status,
+		Detail:   detail,
+		Instance: c.Request.URL.Path,
+		TraceID:  traceID,
+		Errors:   fields,
+	})
+}
+
+// SetRetryAfter tells a throttled client how many whole seconds to wait before trying again
+func SetRetryAfter(c *gin.Context, wait time.Duration) {
+	c.Header("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+}
@@ -0,0 +1,74 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"strings"
+
+	"github.com/afthaab/job-portal/internal/ratelimit"
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog/log"
+)
+
+// maxPeekBytes is how much of the body ByAccount reads, the same limit the handlers bind with
+const maxPeekBytes = 1 << 20
+
+// KeyFunc picks what a limit counts the requests by, an empty key lets the request through
+type KeyFunc func(c *gin.Context) string
+
+// ByIP counts the requests of a client address, behind a proxy it is only right when the proxy is trusted
+func ByIP(c *gin.Context) string {
+	return c.ClientIP()
+}
+
+// ByAccount counts the requests made for the email in the json body, whichever address they come from,
+// the body is put back untouched for the handler
+func ByAccount(c *gin.Context) string {
+	if c.Request.Body == nil {
+		return ""
+	}
+	data, err := io.ReadAll(io.LimitReader(c.Request.Body, maxPeekBytes))
+	c.Request.Body = io.NopCloser(io.MultiReader(bytes.NewReader(data), c.Request.Body))
+	if err != nil {
+		return ""
+	}
+
+	var body struct {
+		Email string `json:"email"`
+	}
+	err = json.Unmarshal(data, &body)
+	if err != nil {
+		return ""
+	}
+	return strings.ToLower(strings.TrimSpace(body.Email))
+}
+
+// RateLimit lets a request through while the bucket of its key has tokens left and answers 429 otherwise,
+// a store that fails lets the requests through so an outage of a shared store does not lock everyone out
+func (m *Mid) RateLimit(store ratelimit.Store, name string, limit ratelimit.Limit, key KeyFunc, next gin.HandlerFunc) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		k := key(c)
+		if k == "" {
+			next(c)
+			return
+		}
+
+		ok, wait, err := store.Take(c.Request.Context(), name+":"+k, limit)
+		if err != nil {
+			traceID, _ := c.Request.Context().Value(TraceIDKey).(string)
+			log.Error().Err(err).Str("trace id", traceID).Str("limit", name).Msg("rate limit store failed")
+			next(c)
+			return
+		}
+		if !ok {
+			SetRetryAfter(c, wait)
+			AbortWithProblem(c, http.StatusTooManyRequests, fmt.Sprintf("too many requests, try again in %d seconds", int(math.Ceil(wait.Seconds()))))
+			return
+		}
+		next(c)
+	}
+}
@@ -0,0 +1,68 @@
+package middleware
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/afthaab/job-portal/internal/ratelimit"
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/assert/v2"
+)
+
+func TestMid_RateLimit(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	m := Mid{}
+	store := ratelimit.NewMemoryStore()
+	limit := ratelimit.Limit{Burst: 2, Every: time.Minute}
+
+	var bodies []string
+	r := gin.New()
+	r.POST("/signin", m.RateLimit(store, "signin-account", limit, ByAccount, func(c *gin.Context) {
+		body, _ := io.ReadAll(c.Request.Body)
+		bodies = append(bodies, string(body))
+		c.Status(http.StatusOK)
+	}))
+
+	signin := func(body string) *httptest.ResponseRecorder {
+		rr := httptest.NewRecorder()
+		r.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/signin", strings.NewReader(body)))
+		return rr
+	}
+
+	assert.Equal(t, http.StatusOK, signin(`{"email":"a@example.com","password":"x"}`).Code)
+	assert.Equal(t, http.StatusOK, signin(`{"email":" A@Example.com ","password":"y"}`).Code)
+
+	// the third request for the same account is refused whichever way the email is written
+	rr := signin(`{"email":"a@EXAMPLE.com","password":"z"}`)
+	assert.Equal(t, http.StatusTooManyRequests, rr.Code)
+	assert.Equal(t, "60", rr.Header().Get("Retry-After"))
+
+	// another account has its own bucket and a body without an email is left to the handler
+	assert.Equal(t, http.StatusOK, signin(`{"email":"b@example.com"}`).Code)
+	assert.Equal(t, http.StatusOK, signin(`not json`).Code)
+
+	// the handler reads the body the limiter peeked at
+	assert.Equal(t, []string{`{"email":"a@example.com","password":"x"}`, `{"email":" A@Example.com ","password":"y"}`, `{"email":"b@example.com"}`, `not json`}, bodies)
+}
+
+func TestByIP(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	err := r.SetTrustedProxies(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got string
+	r.GET("/", func(c *gin.Context) { got = ByIP(c) })
+
+	// a forwarded header from an untrusted client does not change its key
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.RemoteAddr = "203.0.113.7:4242"
+	req.Header.Set("X-Forwarded-For", "198.51.100.1")
+	r.ServeHTTP(httptest.NewRecorder(), req)
+	assert.Equal(t, "203.0.113.7", got)
+}
@@ -0,0 +1,67 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/assert/v2"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestMid_Trace(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+
+	gin.SetMode(gin.TestMode)
+	m := Mid{}
+	r := gin.New()
+	r.Use(m.Trace(), m.Log())
+	var traceID string
+	r.GET("/admin/jobs/view/:id", func(c *gin.Context) {
+		traceID, _ = c.Request.Context().Value(TraceIDKey).(string)
+		c.Status(http.StatusOK)
+	})
+
+	tests := []struct {
+		name        string
+		traceparent string
+		wantTraceID string
+	}{
+		{
+			name:        "continues the caller trace",
+			traceparent: "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+			wantTraceID: "4bf92f3577b34da6a3ce929d0e0e4736",
+		},
+		{
+			name: "starts a new trace",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/admin/jobs/view/1", nil)
+			if tt.traceparent != "" {
+				req.Header.Set("traceparent", tt.traceparent)
+			}
+			rec := httptest.NewRecorder()
+			r.ServeHTTP(rec, req)
+
+			header := rec.Header().Get(TraceIDHeader)
+			assert.Equal(t, 32, len(header))
+			assert.Equal(t, header, traceID)
+			if tt.wantTraceID != "" {
+				assert.Equal(t, tt.wantTraceID, header)
+			}
+
+			spans := recorder.Ended()
+			span := spans[len(spans)-1]
+			assert.Equal(t, "GET /admin/jobs/view/:id", span.Name())
+			assert.Equal(t, header, span.SpanContext().TraceID().String())
+		})
+	}
+}
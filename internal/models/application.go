@@ -0,0 +1,55 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// lifecycle of an application, a candidate can withdraw at any point before it is rejected
+const (
+	ApplicationSubmitted = "submitted"
+	ApplicationScreening = "screening"
+	ApplicationInterview = "interview"
+	ApplicationOffer     = "offer"
+	ApplicationRejected  = "rejected"
+	ApplicationWithdrawn = "withdrawn"
+)
+
+type NewApplication struct {
+	CoverLetter string `json:"cover_letter" validate:"max=5000"`
+}
+
+type ApplicationStatus struct {
+	Status string `json:"status" validate:"required,oneof=screening interview offer rejected"`
+}
+
+type Application struct {
+	gorm.Model
+	User        User   `json:"-" gorm:"ForeignKey:uid"`
+	Uid         uint   `json:"uid" gorm:"uniqueIndex:idx_application_user_job"`
+	Job         Jobs   `json:"-" gorm:"ForeignKey:jid"`
+	Jid         uint   `json:"jid" gorm:"uniqueIndex:idx_application_user_job"`
+	CoverLetter string `json:"cover_letter"`
+	Status      string `json:"status" gorm:"not null;default:submitted"`
+}
+
+type ApplicationResponse struct {
+	ID          uint      `json:"id"`
+	Uid         uint      `json:"uid"`
+	Jid         uint      `json:"jid"`
+	CoverLetter string    `json:"cover_letter"`
+	Status      string    `json:"status"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// ApplicationAudit records every status change of an application
+type ApplicationAudit struct {
+	gorm.Model
+	Application Application `json:"-" gorm:"ForeignKey:aid"`
+	Aid         uint        `json:"aid" gorm:"index"`
+	FromStatus  string      `json:"from_status"`
+	ToStatus    string      `json:"to_status"`
+	ChangedBy   uint        `json:"changed_by"`
+}
@@ -1,19 +1,108 @@
 package models
 
-import "gorm.io/gorm"
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
 
 type Company struct {
 	gorm.Model
-	Name     string `json:"name" gorm:"unique" validate:"required"`
+	Name     string `json:"name" gorm:"unique"`
+	Location string `json:"location"`
+	Field    string `json:"field"`
+	// Members is only used to store the owner along with a new company
+	Members []CompanyMember `json:"-" gorm:"ForeignKey:cid"`
+}
+
+// NewCompany is the body of a request that creates or replaces a company
+type NewCompany struct {
+	Name     string `json:"name" validate:"required"`
 	Location string `json:"location" validate:"required"`
 	Field    string `json:"field" validate:"required"`
 }
 
+// CompanyResponse is a company as the api returns it
+type CompanyResponse struct {
+	ID        uint      `json:"id"`
+	Name      string    `json:"name"`
+	Location  string    `json:"location"`
+	Field     string    `json:"field"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// UpdateCompany holds the fields of a partial update, empty fields are left unchanged
+type UpdateCompany struct {
+	Name     string `json:"name"`
+	Location string `json:"location"`
+	Field    string `json:"field"`
+}
+
+// status of a job posting, only open postings are shown to candidates
+const (
+	JobOpen   = "open"
+	JobClosed = "closed"
+	JobDraft  = "draft"
+)
+
+// pay periods of a compensation
+const (
+	PayHourly  = "hourly"
+	PayMonthly = "monthly"
+	PayYearly  = "yearly"
+)
+
+// Compensation of a job, the amounts are in whole units of the currency for every pay period
+type Compensation struct {
+	MinAmount int64  `json:"min_amount" validate:"gte=0"`
+	MaxAmount int64  `json:"max_amount" validate:"gtefield=MinAmount"`
+	Currency  string `json:"currency" validate:"required,iso4217"`
+	PayPeriod string `json:"pay_period" validate:"required,oneof=hourly monthly yearly"`
+	Equity    bool   `json:"equity"`
+}
+
 type Jobs struct {
 	gorm.Model
-	Company      Company `json:"-" gorm:"ForeignKey:cid"`
-	Cid          uint    `json:"cid"`
-	Name         string  `json:"name"`
-	Salary       string  `json:"salary"`
-	NoticePeriod string  `json:"notice_period"`
+	Company          Company      `json:"-" gorm:"ForeignKey:cid"`
+	Cid              uint         `json:"cid"`
+	Name             string       `json:"name"`
+	Compensation     Compensation `json:"compensation" gorm:"embedded;embeddedPrefix:salary_"`
+	NoticePeriodDays int          `json:"notice_period_days"`
+	Description      string       `json:"description"`
+	Status           string       `json:"status" gorm:"not null;default:open"`
+	// NeedsReview is set on the jobs whose old free text salary or notice period could not be parsed
+	NeedsReview bool `json:"needs_review" gorm:"not null;default:false"`
+}
+
+// NewJob is the body of a request that posts or replaces a job, the company comes from the path
+type NewJob struct {
+	Name             string       `json:"name" validate:"required"`
+	Compensation     Compensation `json:"compensation"`
+	NoticePeriodDays int          `json:"notice_period_days" validate:"gte=0"`
+	Description      string       `json:"description"`
+	Status           string       `json:"status" validate:"omitempty,oneof=open closed draft"`
+}
+
+// JobResponse is a job as the api returns it
+type JobResponse struct {
+	ID               uint         `json:"id"`
+	Cid              uint         `json:"cid"`
+	Name             string       `json:"name"`
+	Compensation     Compensation `json:"compensation"`
+	NoticePeriodDays int          `json:"notice_period_days"`
+	Description      string       `json:"description"`
+	Status           string       `json:"status"`
+	NeedsReview      bool         `json:"needs_review"`
+	CreatedAt        time.Time    `json:"created_at"`
+	UpdatedAt        time.Time    `json:"updated_at"`
+}
+
+// UpdateJob holds the fields of a partial update, empty fields are left unchanged
+type UpdateJob struct {
+	Name             string        `json:"name"`
+	Compensation     *Compensation `json:"compensation"`
+	NoticePeriodDays *int          `json:"notice_period_days" validate:"omitempty,gte=0"`
+	Description      string        `json:"description"`
+	Status           string        `json:"status" validate:"omitempty,oneof=open closed draft"`
 }
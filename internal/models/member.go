@@ -0,0 +1,67 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// roles a user can have inside a company, owners manage the company and its members,
+// recruiters manage its jobs and applicants and viewers can only look at them
+const (
+	MemberOwner     = "owner"
+	MemberRecruiter = "recruiter"
+	MemberViewer    = "viewer"
+)
+
+type CompanyMember struct {
+	gorm.Model
+	Company Company `json:"-" gorm:"ForeignKey:cid"`
+	Cid     uint    `json:"cid" gorm:"uniqueIndex:idx_company_member"`
+	User    User    `json:"-" gorm:"ForeignKey:uid"`
+	Uid     uint    `json:"uid" gorm:"uniqueIndex:idx_company_member"`
+	Role    string  `json:"role" gorm:"not null"`
+}
+
+// CompanyInvitation lets an owner add a member by email, the user signed in with that email accepts it
+type CompanyInvitation struct {
+	gorm.Model
+	Company    Company    `json:"-" gorm:"ForeignKey:cid"`
+	Cid        uint       `json:"cid" gorm:"index"`
+	Email      string     `json:"email" gorm:"index;not null"`
+	Role       string     `json:"role" gorm:"not null"`
+	InvitedBy  uint       `json:"invited_by"`
+	ExpiresAt  time.Time  `json:"expires_at"`
+	AcceptedAt *time.Time `json:"accepted_at"`
+}
+
+type MemberResponse struct {
+	Cid       uint      `json:"cid"`
+	Uid       uint      `json:"uid"`
+	Role      string    `json:"role"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type InvitationResponse struct {
+	ID         uint       `json:"id"`
+	Cid        uint       `json:"cid"`
+	Email      string     `json:"email"`
+	Role       string     `json:"role"`
+	InvitedBy  uint       `json:"invited_by"`
+	ExpiresAt  time.Time  `json:"expires_at"`
+	AcceptedAt *time.Time `json:"accepted_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+// AcceptedInvitation is the membership an invitation gave. A candidate it made a recruiter gets a new access
+// token with the new role in Token, the token the invitation was accepted with still says candidate
+type AcceptedInvitation struct {
+	Member    MemberResponse `json:"member"`
+	Token     string         `json:"token,omitempty"`
+	ExpiresIn int64          `json:"expires_in,omitempty"`
+}
+
+type NewInvitation struct {
+	Email string `json:"email" validate:"required,email"`
+	Role  string `json:"role" validate:"required,oneof=owner recruiter viewer"`
+}
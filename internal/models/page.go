@@ -0,0 +1,35 @@
+package models
+
+// PageQuery is the page of a list endpoint asked for by the client
+type PageQuery struct {
+	Limit  int
+	Offset int
+	Sort   string
+	Desc   bool
+}
+
+// Page is the envelope every list endpoint responds with
+type Page[T any] struct {
+	Items      []T    `json:"items"`
+	Total      int64  `json:"total"`
+	NextCursor string `json:"next_cursor,omitempty"`
+}
+
+type JobFilter struct {
+	Cid       uint64
+	Location  string
+	Field     string
+	MinSalary *int64
+	MaxSalary *int64
+	Currency  string
+	PayPeriod string
+	OpenOnly  bool
+	// MemberOf are the companies whose closed and draft jobs are still shown when OpenOnly is set
+	MemberOf []uint
+}
+
+type CompanyFilter struct {
+	Name     string
+	Location string
+	Field    string
+}
@@ -0,0 +1,28 @@
+package models
+
+type JobSearch struct {
+	Query    string
+	OpenOnly bool
+	// MemberOf are the companies whose closed and draft jobs are still found when OpenOnly is set
+	MemberOf []uint
+}
+
+// JobSearchResult is a job matching a search, the highlights are html escaped text with the matched terms in <mark> tags
+type JobSearchResult struct {
+	Job        Jobs          `json:"job"`
+	Rank       float64       `json:"rank"`
+	Highlights JobHighlights `json:"highlights"`
+}
+
+// JobSearchResponse is a search result as the api returns it
+type JobSearchResponse struct {
+	Job        JobResponse   `json:"job"`
+	Rank       float64       `json:"rank"`
+	Highlights JobHighlights `json:"highlights"`
+}
+
+type JobHighlights struct {
+	Name        string `json:"name"`
+	Company     string `json:"company"`
+	Description string `json:"description"`
+}
@@ -0,0 +1,69 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// RefreshToken is a long lived opaque token, only the sha256 of the token is stored.
+// Every sign in starts a new family and each refresh replaces the token with the next one in the same family
+type RefreshToken struct {
+	gorm.Model
+	User       User       `json:"-" gorm:"ForeignKey:uid"`
+	Uid        uint       `json:"uid" gorm:"index"`
+	TokenHash  string     `json:"-" gorm:"uniqueIndex;not null"`
+	Family     string     `json:"-" gorm:"index;not null"`
+	ExpiresAt  time.Time  `json:"expires_at"`
+	RevokedAt  *time.Time `json:"revoked_at"`
+	ReplacedBy *uint      `json:"-"`
+}
+
+// RevokedToken is the jti of an access token that was revoked before it expired
+type RevokedToken struct {
+	Jti       string    `gorm:"primaryKey"`
+	ExpiresAt time.Time `gorm:"index"`
+}
+
+// RevokedSubject revokes every access token of the subject issued up to IssuedBefore, it is kept until
+// the last of them would have expired
+type RevokedSubject struct {
+	Subject      string `gorm:"primaryKey"`
+	IssuedBefore time.Time
+	ExpiresAt    time.Time `gorm:"index"`
+}
+
+// purposes of a user token, a token only works for the purpose it was issued for
+const (
+	TokenPurposeVerifyEmail   = "verify_email"
+	TokenPurposeResetPassword = "reset_password"
+	// TokenPurposeChangeEmail is mailed to the new address of a user, the address only changes once it is followed
+	TokenPurposeChangeEmail = "change_email"
+)
+
+// UserToken is a single use token mailed to a user, like the refresh tokens only its sha256 is stored.
+// Email is the address the token was sent to so a token can not verify an address changed after it was sent
+type UserToken struct {
+	gorm.Model
+	User      User       `json:"-" gorm:"ForeignKey:uid"`
+	Uid       uint       `json:"uid" gorm:"index"`
+	Purpose   string     `json:"purpose" gorm:"not null"`
+	TokenHash string     `json:"-" gorm:"uniqueIndex;not null"`
+	Email     string     `json:"-"`
+	ExpiresAt time.Time  `json:"expires_at"`
+	UsedAt    *time.Time `json:"used_at"`
+}
+
+type TokenPair struct {
+	AccessToken  string `json:"token"`
+	RefreshToken string `json:"refresh_token"`
+	ExpiresIn    int64  `json:"expires_in"`
+}
+
+type RefreshRequest struct {
+	RefreshToken string `json:"refresh_token" validate:"required"`
+}
+
+type LogoutRequest struct {
+	RefreshToken string `json:"refresh_token"`
+}
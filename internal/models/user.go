@@ -1,10 +1,26 @@
 package models
 
-import "gorm.io/gorm"
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// roles a user of the portal can have
+const (
+	RoleCandidate = "candidate"
+	RoleRecruiter = "recruiter"
+	RoleAdmin     = "admin"
+)
 
 type NewUser struct {
 	Username string `json:"username" validate:"required"`
-	Email    string `json:"email" validate:"required"`
+	Email    string `json:"email" validate:"required,email"`
+	Password string `json:"password" validate:"required"`
+}
+
+type UserSignin struct {
+	Email    string `json:"email" validate:"required,email"`
 	Password string `json:"password" validate:"required"`
 }
 
@@ -13,4 +29,73 @@ type User struct {
 	Username     string `json:"username" gorm:"unique"`
 	Email        string `json:"email" gorm:"unique"`
 	PasswordHash string `json:"-"`
+	Role         string `json:"role" gorm:"not null;default:candidate"`
+	// EmailVerifiedAt is set once the user follows the link mailed to the address, nil until then
+	EmailVerifiedAt *time.Time `json:"email_verified_at"`
+	// PasswordChangedAt is the last password change, the access tokens issued before it are revoked
+	PasswordChangedAt *time.Time `json:"-"`
+	FullName          string     `json:"full_name" gorm:"not null;default:''"`
+	Headline          string     `json:"headline" gorm:"not null;default:''"`
+	Location          string     `json:"location" gorm:"not null;default:''"`
+}
+
+// EmailVerified reports whether the current address of the user was verified
+func (u User) EmailVerified() bool {
+	return u.EmailVerifiedAt != nil
+}
+
+// UserResponse is a user as the api returns it, the password hash never leaves the service
+type UserResponse struct {
+	ID            uint      `json:"id"`
+	Username      string    `json:"username"`
+	Email         string    `json:"email"`
+	Role          string    `json:"role"`
+	EmailVerified bool      `json:"email_verified"`
+	FullName      string    `json:"full_name"`
+	Headline      string    `json:"headline"`
+	Location      string    `json:"location"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+// UpdateProfile changes only the fields that are sent, an empty string clears a profile field
+// but the username can not be cleared
+type UpdateProfile struct {
+	Username *string `json:"username" validate:"omitempty,min=3,max=50"`
+	FullName *string `json:"full_name" validate:"omitempty,max=100"`
+	Headline *string `json:"headline" validate:"omitempty,max=200"`
+	Location *string `json:"location" validate:"omitempty,max=100"`
+}
+
+// the requests of a signed in user that change the account ask for the current password again,
+// a stolen access token alone can not take the account over
+
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"current_password" validate:"required"`
+	NewPassword     string `json:"new_password" validate:"required"`
+}
+
+type ChangeEmailRequest struct {
+	Email           string `json:"email" validate:"required,email"`
+	CurrentPassword string `json:"current_password" validate:"required"`
+}
+
+type DeleteAccountRequest struct {
+	CurrentPassword string `json:"current_password" validate:"required"`
+}
+
+type VerifyEmailRequest struct {
+	Token string `json:"token" validate:"required"`
+}
+
+type ResendVerificationRequest struct {
+	Email string `json:"email" validate:"required,email"`
+}
+
+type ForgotPasswordRequest struct {
+	Email string `json:"email" validate:"required,email"`
+}
+
+type ResetPasswordRequest struct {
+	Token    string `json:"token" validate:"required"`
+	Password string `json:"password" validate:"required"`
 }
@@ -0,0 +1,70 @@
+// Package password hashes and checks the passwords of the users. Hashes are stored with the
+// algorithm and its parameters so the algorithm can change while the old hashes keep working,
+// each one is replaced the next time its owner signs in.
+package password
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrMismatch is returned by Verify when the password does not match the hash
+var ErrMismatch = errors.New("password does not match")
+
+// Algorithm is one way of hashing passwords
+type Algorithm interface {
+	Hash(password string) (string, error)
+	// Verify returns ErrMismatch when the password does not match, the parameters are read from the hash
+	Verify(password string, hash string) error
+	// Owns reports whether the hash was made by this algorithm
+	Owns(hash string) bool
+	// Outdated reports whether a hash of this algorithm uses weaker parameters than the algorithm is set to
+	Outdated(hash string) bool
+}
+
+// Hasher hashes with the current algorithm and verifies the hashes of every algorithm it knows
+type Hasher struct {
+	current Algorithm
+	known   []Algorithm
+}
+
+// NewHasher returns a hasher for the current algorithm, bcrypt and argon2id hashes are always verified
+func NewHasher(current Algorithm) *Hasher {
+	return &Hasher{
+		current: current,
+		known:   []Algorithm{current, Bcrypt{}, Argon2id{}},
+	}
+}
+
+// Limit caps the policy at what the current algorithm can hash, so a password the policy lets through
+// never fails to hash
+func (h *Hasher) Limit(p Policy) Policy {
+	if _, ok := h.current.(Bcrypt); ok && (p.MaxBytes == 0 || p.MaxBytes > BcryptMaxBytes) {
+		p.MaxBytes = BcryptMaxBytes
+	}
+	return p
+}
+
+func (h *Hasher) Hash(password string) (string, error) {
+	hash, err := h.current.Hash(password)
+	if err != nil {
+		return "", fmt.Errorf("error in hashing the password : %w", err)
+	}
+	return hash, nil
+}
+
+// Verify checks the password against the hash, rehash tells the caller to store a new hash
+// of the password because this one was made by another algorithm or with older parameters
+func (h *Hasher) Verify(password string, hash string) (rehash bool, err error) {
+	for _, alg := range h.known {
+		if !alg.Owns(hash) {
+			continue
+		}
+		err = alg.Verify(password, hash)
+		if err != nil {
+			return false, err
+		}
+		return !h.current.Owns(hash) || h.current.Outdated(hash), nil
+	}
+	return false, errors.New("unknown password hash format")
+}
@@ -0,0 +1,155 @@
+package password
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/afthaab/job-portal/internal/apperr"
+)
+
+// cheap parameters keep the tests fast, the rehash rules only compare them
+var (
+	testArgon2   = Argon2id{Time: 1, Memory: 1024, Threads: 1}
+	strongArgon2 = Argon2id{Time: 2, Memory: 1024, Threads: 1}
+)
+
+func mustHash(t *testing.T, alg Algorithm, password string) string {
+	t.Helper()
+	hash, err := alg.Hash(password)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return hash
+}
+
+func TestHasher_Verify(t *testing.T) {
+	bcrypt4 := mustHash(t, Bcrypt{Cost: 4}, "correct horse")
+	bcrypt5 := mustHash(t, Bcrypt{Cost: 5}, "correct horse")
+	argonWeak := mustHash(t, testArgon2, "correct horse")
+	argonStrong := mustHash(t, strongArgon2, "correct horse")
+
+	tests := []struct {
+		name       string
+		current    Algorithm
+		hash       string
+		password   string
+		wantRehash bool
+		wantErr    error
+	}{
+		{name: "bcrypt current", current: Bcrypt{Cost: 4}, hash: bcrypt4, password: "correct horse"},
+		{name: "bcrypt cost raised", current: Bcrypt{Cost: 5}, hash: bcrypt4, password: "correct horse", wantRehash: true},
+		{name: "bcrypt cost lowered", current: Bcrypt{Cost: 4}, hash: bcrypt5, password: "correct horse"},
+		{name: "bcrypt to argon2id", current: testArgon2, hash: bcrypt4, password: "correct horse", wantRehash: true},
+		{name: "argon2id current", current: testArgon2, hash: argonWeak, password: "correct horse"},
+		{name: "argon2id time raised", current: strongArgon2, hash: argonWeak, password: "correct horse", wantRehash: true},
+		{name: "argon2id stronger than current", current: testArgon2, hash: argonStrong, password: "correct horse"},
+		{name: "argon2id to bcrypt", current: Bcrypt{Cost: 4}, hash: argonWeak, password: "correct horse", wantRehash: true},
+		{name: "bcrypt mismatch", current: testArgon2, hash: bcrypt4, password: "wrong horse", wantErr: ErrMismatch},
+		{name: "argon2id mismatch", current: testArgon2, hash: argonWeak, password: "wrong horse", wantErr: ErrMismatch},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rehash, err := NewHasher(tt.current).Verify(tt.password, tt.hash)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Hasher.Verify() error = %v, want %v", err, tt.wantErr)
+			}
+			if rehash != tt.wantRehash {
+				t.Errorf("Hasher.Verify() rehash = %v, want %v", rehash, tt.wantRehash)
+			}
+		})
+	}
+
+	_, err := NewHasher(testArgon2).Verify("correct horse", "plain text")
+	if err == nil || errors.Is(err, ErrMismatch) {
+		t.Errorf("Hasher.Verify() of an unknown format error = %v", err)
+	}
+}
+
+func TestArgon2id_Hash(t *testing.T) {
+	first := mustHash(t, testArgon2, "correct horse")
+	second := mustHash(t, testArgon2, "correct horse")
+	if first == second {
+		t.Errorf("Argon2id.Hash() gave the same hash twice, the salt is not random")
+	}
+	_, err := Argon2id{}.Hash("correct horse")
+	if err == nil {
+		t.Errorf("Argon2id.Hash() with zero parameters did not fail")
+	}
+}
+
+func TestPolicy_Check(t *testing.T) {
+	tests := []struct {
+		name      string
+		password  string
+		wantCodes []string
+	}{
+		{name: "good", password: "purple tractor rain"},
+		{name: "too short", password: "x7#kq", wantCodes: []string{"min"}},
+		{name: "too long", password: string(make([]rune, 129)), wantCodes: []string{"max"}},
+		{name: "common in any case", password: "PassWord123", wantCodes: []string{"common"}},
+		{name: "holds the username", password: "my name is afthab!", wantCodes: []string{"personal"}},
+		{name: "holds the email user", password: "afthab606 rocks", wantCodes: []string{"personal"}},
+		{name: "short and common", password: "1234", wantCodes: []string{"min", "common"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := DefaultPolicy.Check(tt.password, "Afthab", "Afthab606@gmail.com")
+			var codes []string
+			for _, f := range apperr.FieldsOf(err) {
+				codes = append(codes, f.Code)
+			}
+			if len(codes) != len(tt.wantCodes) {
+				t.Fatalf("Policy.Check() codes = %v, want %v", codes, tt.wantCodes)
+			}
+			for i := range codes {
+				if codes[i] != tt.wantCodes[i] {
+					t.Errorf("Policy.Check() codes = %v, want %v", codes, tt.wantCodes)
+				}
+			}
+			if err != nil && apperr.KindOf(err) != apperr.KindValidation {
+				t.Errorf("Policy.Check() kind = %v, want validation", apperr.KindOf(err))
+			}
+		})
+	}
+}
+
+func TestHasher_Limit(t *testing.T) {
+	// 40 characters of three bytes each pass the length in characters but not bcrypt
+	long := strings.Repeat("密", 40)
+	tests := []struct {
+		name      string
+		current   Algorithm
+		wantCodes []string
+	}{
+		{name: "bcrypt caps the bytes", current: Bcrypt{Cost: 4}, wantCodes: []string{"max_bytes"}},
+		{name: "argon2id takes any length", current: testArgon2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hasher := NewHasher(tt.current)
+			err := hasher.Limit(DefaultPolicy).Check(long, "afthab", "afthab606@gmail.com")
+			var codes []string
+			for _, f := range apperr.FieldsOf(err) {
+				codes = append(codes, f.Code)
+			}
+			if strings.Join(codes, ",") != strings.Join(tt.wantCodes, ",") {
+				t.Fatalf("Policy.Check() codes = %v, want %v", codes, tt.wantCodes)
+			}
+			if err == nil {
+				// whatever the limited policy lets through can be hashed
+				_, err = hasher.Hash(long)
+				if err != nil {
+					t.Errorf("Hasher.Hash() error = %v", err)
+				}
+			}
+		})
+	}
+}
+
+func TestBcrypt_HashTooLong(t *testing.T) {
+	_, err := Bcrypt{Cost: 4}.Hash(strings.Repeat("a", 100))
+	if apperr.KindOf(err) != apperr.KindValidation {
+		t.Errorf("Bcrypt.Hash() error = %v, want a validation error", err)
+	}
+}
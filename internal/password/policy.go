@@ -0,0 +1,88 @@
+package password
+
+import (
+	"bufio"
+	_ "embed"
+	"fmt"
+	"strings"
+
+	"github.com/afthaab/job-portal/internal/apperr"
+)
+
+// commonList is a short list of passwords that top every breach, one per line and in lower case
+//
+//go:embed common.txt
+var commonList string
+
+var common = func() map[string]struct{} {
+	set := make(map[string]struct{})
+	s := bufio.NewScanner(strings.NewReader(commonList))
+	for s.Scan() {
+		if line := strings.TrimSpace(s.Text()); line != "" {
+			set[line] = struct{}{}
+		}
+	}
+	return set
+}()
+
+// Policy is what a new password has to satisfy, the lengths count characters
+type Policy struct {
+	MinLength int
+	// MaxLength bounds the work of hashing one request
+	MaxLength int
+	// MaxBytes bounds the length in bytes for an algorithm that can not hash more, zero has no bound
+	MaxBytes int
+}
+
+// DefaultPolicy follows NIST 800-63B, a length and a block list rather than character classes
+var DefaultPolicy = Policy{MinLength: 8, MaxLength: 128}
+
+// Check returns a validation error naming every rule the password breaks,
+// the username and email are those of the account the password is for
+func (p Policy) Check(password string, username string, email string) error {
+	var fields []apperr.FieldError
+	add := func(code string, msg string) {
+		fields = append(fields, apperr.FieldError{Field: "password", Code: code, Message: msg})
+	}
+
+	length := len([]rune(password))
+	if length < p.MinLength {
+		add("min", fmt.Sprintf("password must be at least %d characters long", p.MinLength))
+	}
+	if p.MaxLength > 0 && length > p.MaxLength {
+		add("max", fmt.Sprintf("password must be at most %d characters long", p.MaxLength))
+	}
+
+	if p.MaxBytes > 0 && len(password) > p.MaxBytes {
+		add("max_bytes", fmt.Sprintf("password must be at most %d bytes long, characters outside of ascii take more than one", p.MaxBytes))
+	}
+
+	lower := strings.ToLower(password)
+	if _, ok := common[lower]; ok {
+		add("common", "password is too common, it appears in lists of breached passwords")
+	}
+	if containsIdentity(lower, username, email) {
+		add("personal", "password must not contain the username or the email")
+	}
+
+	if len(fields) > 0 {
+		return apperr.Invalid("password does not meet the password policy", fields...)
+	}
+	return nil
+}
+
+// containsIdentity reports whether the password holds the username, the email or the part of the email
+// before the @, parts shorter than three characters are too likely to show up by chance
+func containsIdentity(password string, username string, email string) bool {
+	email = strings.ToLower(strings.TrimSpace(email))
+	local := email
+	if at := strings.LastIndex(email, "@"); at >= 0 {
+		local = email[:at]
+	}
+	for _, part := range []string{strings.ToLower(strings.TrimSpace(username)), email, local} {
+		if len(part) >= 3 && strings.Contains(password, part) {
+			return true
+		}
+	}
+	return false
+}
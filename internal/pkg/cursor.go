@@ -0,0 +1,27 @@
+package pkg
+
+import (
+	"encoding/base64"
+	"errors"
+	"strconv"
+	"strings"
+)
+
+const cursorPrefix = "offset:"
+
+// EncodeCursor hides the offset of the next page behind an opaque string
+func EncodeCursor(offset int) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(cursorPrefix + strconv.Itoa(offset)))
+}
+
+func DecodeCursor(cursor string) (int, error) {
+	data, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return 0, errors.New("invalid cursor")
+	}
+	offset, err := strconv.Atoi(strings.TrimPrefix(string(data), cursorPrefix))
+	if err != nil || !strings.HasPrefix(string(data), cursorPrefix) || offset < 0 {
+		return 0, errors.New("invalid cursor")
+	}
+	return offset, nil
+}
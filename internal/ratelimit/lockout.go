@@ -0,0 +1,119 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// LockoutPolicy locks an account after Threshold failed passwords in a row, the first lock lasts Base
+// and every further failure doubles it up to Max
+type LockoutPolicy struct {
+	Threshold int
+	Base      time.Duration
+	Max       time.Duration
+}
+
+// lockFor is how long the account stays locked after its nth failure in a row
+func (p LockoutPolicy) lockFor(failures int) time.Duration {
+	if p.Threshold <= 0 || failures < p.Threshold {
+		return 0
+	}
+	lock := p.Base
+	for i := p.Threshold; i < failures; i++ {
+		lock *= 2
+		if lock >= p.Max {
+			return p.Max
+		}
+	}
+	return lock
+}
+
+// Lockout counts the failed sign ins of an account, the key is the normalized email
+// so accounts that do not exist are locked the same way and do not give themselves away
+type Lockout interface {
+	// LockedFor reports how long the account is still locked, zero when it is not
+	LockedFor(ctx context.Context, key string) (time.Duration, error)
+	// Fail records a failed password and returns the lock it started, if any
+	Fail(ctx context.Context, key string) (time.Duration, error)
+	// Reset forgets the failures after a successful sign in
+	Reset(ctx context.Context, key string) error
+}
+
+type account struct {
+	failures    int
+	lockedUntil time.Time
+	lastFailure time.Time
+}
+
+// MemoryLockout keeps the failures of this instance only
+type MemoryLockout struct {
+	policy   LockoutPolicy
+	mu       sync.Mutex
+	accounts map[string]*account
+	now      func() time.Time
+	sweepAt  time.Time
+}
+
+func NewMemoryLockout(policy LockoutPolicy) *MemoryLockout {
+	return &MemoryLockout{
+		policy:   policy,
+		accounts: make(map[string]*account),
+		now:      time.Now,
+	}
+}
+
+func (l *MemoryLockout) LockedFor(ctx context.Context, key string) (time.Duration, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	a, ok := l.accounts[key]
+	if !ok {
+		return 0, nil
+	}
+	wait := a.lockedUntil.Sub(l.now())
+	if wait < 0 {
+		return 0, nil
+	}
+	return wait, nil
+}
+
+func (l *MemoryLockout) Fail(ctx context.Context, key string) (time.Duration, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	now := l.now()
+	l.forgetStale(now)
+
+	a, ok := l.accounts[key]
+	if !ok {
+		a = &account{}
+		l.accounts[key] = a
+	}
+	a.failures++
+	a.lastFailure = now
+	lock := l.policy.lockFor(a.failures)
+	if lock > 0 {
+		a.lockedUntil = now.Add(lock)
+	}
+	return lock, nil
+}
+
+func (l *MemoryLockout) Reset(ctx context.Context, key string) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	delete(l.accounts, key)
+	return nil
+}
+
+// forgetStale drops the accounts whose last failure is older than the longest lock,
+// an attacker who waits that long starts over at the threshold
+func (l *MemoryLockout) forgetStale(now time.Time) {
+	if now.Before(l.sweepAt) {
+		return
+	}
+	l.sweepAt = now.Add(time.Minute)
+	for key, a := range l.accounts {
+		if now.Sub(a.lastFailure) > l.policy.Max && now.After(a.lockedUntil) {
+			delete(l.accounts, key)
+		}
+	}
+}
@@ -0,0 +1,87 @@
+// Package ratelimit holds the token buckets that throttle the public routes and the lockout
+// that slows down password guessing on a single account.
+// Both keep their state behind an interface so several instances can share it in one store.
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+)
+
+// Limit is a token bucket, it holds at most Burst tokens and gets one back every Every
+type Limit struct {
+	Burst int
+	Every time.Duration
+}
+
+// Store keeps the token buckets, a key is the client being limited prefixed with the name of the limit
+type Store interface {
+	// Take removes a token from the bucket of key, when the bucket is empty it reports
+	// how long the caller has to wait for the next one
+	Take(ctx context.Context, key string, limit Limit) (ok bool, retryAfter time.Duration, err error)
+}
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+	// full is when the bucket is back to its burst and can be forgotten
+	full time.Time
+}
+
+// MemoryStore keeps the buckets of this instance only, behind a load balancer every instance
+// grants the full burst so the limits add up
+type MemoryStore struct {
+	mu      sync.Mutex
+	buckets map[string]*bucket
+	now     func() time.Time
+	// sweepAt is when the full buckets are dropped next so the map does not grow with every client ever seen
+	sweepAt time.Time
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		buckets: make(map[string]*bucket),
+		now:     time.Now,
+	}
+}
+
+func (s *MemoryStore) Take(ctx context.Context, key string, limit Limit) (bool, time.Duration, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	s.sweep(now)
+
+	b, ok := s.buckets[key]
+	if !ok {
+		b = &bucket{tokens: float64(limit.Burst), last: now}
+		s.buckets[key] = b
+	}
+
+	// refill what was earned since the last call, never above the burst
+	elapsed := now.Sub(b.last)
+	b.tokens = math.Min(float64(limit.Burst), b.tokens+float64(elapsed)/float64(limit.Every))
+	b.last = now
+
+	if b.tokens < 1 {
+		wait := time.Duration((1 - b.tokens) * float64(limit.Every))
+		return false, wait, nil
+	}
+	b.tokens--
+	b.full = now.Add(time.Duration((float64(limit.Burst) - b.tokens) * float64(limit.Every)))
+	return true, 0, nil
+}
+
+func (s *MemoryStore) sweep(now time.Time) {
+	if now.Before(s.sweepAt) {
+		return
+	}
+	for key, b := range s.buckets {
+		if !now.Before(b.full) {
+			delete(s.buckets, key)
+		}
+	}
+	s.sweepAt = now.Add(time.Minute)
+}
@@ -0,0 +1,115 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// clock is a fake time the tests move forward by hand
+type clock struct {
+	now time.Time
+}
+
+func (c *clock) Now() time.Time {
+	return c.now
+}
+
+func TestMemoryStore_Take(t *testing.T) {
+	c := &clock{now: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+	s := NewMemoryStore()
+	s.now = c.Now
+	limit := Limit{Burst: 2, Every: time.Second}
+	ctx := context.Background()
+
+	take := func(key string) (bool, time.Duration) {
+		ok, wait, err := s.Take(ctx, key, limit)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return ok, wait
+	}
+
+	for i := 0; i < limit.Burst; i++ {
+		if ok, _ := take("ip:10.0.0.1"); !ok {
+			t.Fatalf("Take() refused request %d within the burst", i+1)
+		}
+	}
+	ok, wait := take("ip:10.0.0.1")
+	if ok || wait != time.Second {
+		t.Errorf("Take() = %v, %v, want a refusal with a wait of 1s", ok, wait)
+	}
+	if ok, _ := take("ip:10.0.0.2"); !ok {
+		t.Errorf("Take() shared the bucket between two keys")
+	}
+
+	c.now = c.now.Add(500 * time.Millisecond)
+	if _, wait := take("ip:10.0.0.1"); wait != 500*time.Millisecond {
+		t.Errorf("Take() wait = %v, want 500ms", wait)
+	}
+	c.now = c.now.Add(500 * time.Millisecond)
+	if ok, _ := take("ip:10.0.0.1"); !ok {
+		t.Errorf("Take() did not refill the bucket")
+	}
+
+	// the buckets that are full again are forgotten
+	c.now = c.now.Add(time.Hour)
+	take("ip:10.0.0.3")
+	if len(s.buckets) != 1 {
+		t.Errorf("Take() kept %d buckets, want only the new one", len(s.buckets))
+	}
+}
+
+func TestLockoutPolicy_lockFor(t *testing.T) {
+	p := LockoutPolicy{Threshold: 3, Base: 30 * time.Second, Max: 5 * time.Minute}
+	tests := []struct {
+		failures int
+		want     time.Duration
+	}{
+		{failures: 2, want: 0},
+		{failures: 3, want: 30 * time.Second},
+		{failures: 4, want: time.Minute},
+		{failures: 5, want: 2 * time.Minute},
+		{failures: 7, want: 5 * time.Minute},
+		{failures: 100, want: 5 * time.Minute},
+	}
+	for _, tt := range tests {
+		if got := p.lockFor(tt.failures); got != tt.want {
+			t.Errorf("lockFor(%d) = %v, want %v", tt.failures, got, tt.want)
+		}
+	}
+}
+
+func TestMemoryLockout(t *testing.T) {
+	c := &clock{now: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+	l := NewMemoryLockout(LockoutPolicy{Threshold: 2, Base: time.Minute, Max: time.Hour})
+	l.now = c.Now
+	ctx := context.Background()
+	key := "afthab606@gmail.com"
+
+	lock, _ := l.Fail(ctx, key)
+	if lock != 0 {
+		t.Errorf("Fail() locked below the threshold")
+	}
+	lock, _ = l.Fail(ctx, key)
+	if lock != time.Minute {
+		t.Errorf("Fail() lock = %v, want 1m", lock)
+	}
+	c.now = c.now.Add(20 * time.Second)
+	if wait, _ := l.LockedFor(ctx, key); wait != 40*time.Second {
+		t.Errorf("LockedFor() = %v, want 40s", wait)
+	}
+	c.now = c.now.Add(time.Minute)
+	if wait, _ := l.LockedFor(ctx, key); wait != 0 {
+		t.Errorf("LockedFor() = %v after the lock ran out", wait)
+	}
+	// the next failure doubles the lock
+	if lock, _ := l.Fail(ctx, key); lock != 2*time.Minute {
+		t.Errorf("Fail() lock = %v, want 2m", lock)
+	}
+
+	_ = l.Reset(ctx, key)
+	if wait, _ := l.LockedFor(ctx, key); wait != 0 {
+		t.Errorf("LockedFor() = %v after a reset", wait)
+	}
+}
@@ -0,0 +1,97 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/afthaab/job-portal/internal/apperr"
+	"github.com/afthaab/job-portal/internal/models"
+	"github.com/rs/zerolog/log"
+	"gorm.io/gorm"
+)
+
+func (r *Repo) CreateApplication(ctx context.Context, applicationData models.Application) (models.Application, error) {
+	db, cancel := r.withTimeout(ctx, opWrite)
+	defer cancel()
+	err := db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Create(&applicationData).Error
+		if err != nil {
+			return err
+		}
+		auditData := models.ApplicationAudit{
+			Aid:       applicationData.ID,
+			ToStatus:  applicationData.Status,
+			ChangedBy: applicationData.Uid,
+		}
+		return tx.Create(&auditData).Error
+	})
+	if err != nil {
+		log.Info().Err(err).Send()
+		return models.Application{}, dbError(err, "could not create the application")
+	}
+	return applicationData, nil
+}
+
+func (r *Repo) FindApplication(ctx context.Context, aid uint64) (models.Application, error) {
+	db, cancel := r.withTimeout(ctx, opRead)
+	defer cancel()
+	var applicationData models.Application
+	result := db.Where("id = ?", aid).First(&applicationData)
+	if result.Error != nil {
+		log.Info().Err(result.Error).Send()
+		return models.Application{}, dbError(result.Error, "could not find the application")
+	}
+	return applicationData, nil
+}
+
+func (r *Repo) FindApplicationsByUser(ctx context.Context, uid uint64, page models.PageQuery) ([]models.Application, int64, error) {
+	db, cancel := r.withTimeout(ctx, opRead)
+	defer cancel()
+	query := db.Model(&models.Application{}).Where("applications.uid = ?", uid)
+
+	var applicationDatas []models.Application
+	total, err := paginate(query, page, applicationSortColumns, "applications.id", &applicationDatas)
+	if err != nil {
+		log.Info().Err(err).Send()
+		return nil, 0, dbError(err, "could not find the applications")
+	}
+	return applicationDatas, total, nil
+}
+
+func (r *Repo) FindApplicationsByJob(ctx context.Context, jid uint64, page models.PageQuery) ([]models.Application, int64, error) {
+	db, cancel := r.withTimeout(ctx, opRead)
+	defer cancel()
+	query := db.Model(&models.Application{}).Where("applications.jid = ?", jid)
+
+	var applicationDatas []models.Application
+	total, err := paginate(query, page, applicationSortColumns, "applications.id", &applicationDatas)
+	if err != nil {
+		log.Info().Err(err).Send()
+		return nil, 0, dbError(err, "could not find the applications")
+	}
+	return applicationDatas, total, nil
+}
+
+// UpdateApplicationStatus moves the application to the status in the audit record and stores the audit record
+// in the same transaction, the update only goes through if nobody changed the status in between
+func (r *Repo) UpdateApplicationStatus(ctx context.Context, applicationData models.Application, auditData models.ApplicationAudit) (models.Application, error) {
+	db, cancel := r.withTimeout(ctx, opWrite)
+	defer cancel()
+	err := db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&applicationData).
+			Where("status = ?", auditData.FromStatus).
+			Update("status", auditData.ToStatus)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return apperr.Conflict("application status was changed by someone else")
+		}
+		return tx.Create(&auditData).Error
+	})
+	if err != nil {
+		log.Info().Err(err).Send()
+		return models.Application{}, dbError(err, "could not update the application status")
+	}
+	applicationData.Status = auditData.ToStatus
+	return applicationData, nil
+}
@@ -3,36 +3,131 @@ package repository
 import (
 	"context"
 	"errors"
+	"strings"
+	"time"
 
+	"github.com/afthaab/job-portal/internal/apperr"
 	"github.com/afthaab/job-portal/internal/models"
 	"github.com/rs/zerolog/log"
+	"gorm.io/gorm"
 )
 
 func (r *Repo) CreateCompany(ctx context.Context, companyData models.Company) (models.Company, error) {
-	result := r.db.Create(&companyData)
+	db, cancel := r.withTimeout(ctx, opWrite)
+	defer cancel()
+	result := db.Create(&companyData)
 	if result.Error != nil {
 		log.Info().Err(result.Error).Send()
-		return models.Company{}, errors.New("could not create the company")
+		return models.Company{}, dbError(result.Error, "could not create the company")
 	}
 	return companyData, nil
 }
 
-func (r *Repo) ViewCompanies(ctx context.Context) ([]models.Company, error) {
-	var userDetails []models.Company
-	result := r.db.Find(&userDetails)
+// likeEscaper makes the wildcards of a LIKE pattern match themselves, the name filter matches the text the client sent
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+func (r *Repo) ViewCompanies(ctx context.Context, filter models.CompanyFilter, page models.PageQuery) ([]models.Company, int64, error) {
+	db, cancel := r.withTimeout(ctx, opRead)
+	defer cancel()
+	query := db.Model(&models.Company{})
+	if filter.Name != "" {
+		query = query.Where(`companies.name ILIKE ? ESCAPE '\'`, "%"+likeEscaper.Replace(filter.Name)+"%")
+	}
+	if filter.Location != "" {
+		query = query.Where("lower(companies.location) = lower(?)", filter.Location)
+	}
+	if filter.Field != "" {
+		query = query.Where("lower(companies.field) = lower(?)", filter.Field)
+	}
+
+	var companyDetails []models.Company
+	total, err := paginate(query, page, companySortColumns, "companies.id", &companyDetails)
+	if err != nil {
+		log.Info().Err(err).Send()
+		return nil, 0, dbError(err, "could not find the companies")
+	}
+	return companyDetails, total, nil
+}
+
+func (r *Repo) ViewCompanyById(ctx context.Context, cid uint64) (models.Company, error) {
+	db, cancel := r.withTimeout(ctx, opRead)
+	defer cancel()
+	var companyData models.Company
+	result := db.Where("id = ?", cid).First(&companyData)
 	if result.Error != nil {
 		log.Info().Err(result.Error).Send()
-		return nil, errors.New("could not find the companies")
+		return models.Company{}, dbError(result.Error, "could not find the company")
 	}
-	return userDetails, nil
+	return companyData, nil
 }
 
-func (r *Repo) ViewCompanyById(ctx context.Context, cid uint64) (models.Company, error) {
+func (r *Repo) UpdateCompany(ctx context.Context, cid uint64, companyData models.Company) (models.Company, error) {
+	db, cancel := r.withTimeout(ctx, opWrite)
+	defer cancel()
+	result := db.Model(&models.Company{}).Where("id = ?", cid).Updates(companyData)
+	if result.Error != nil {
+		log.Info().Err(result.Error).Send()
+		return models.Company{}, dbError(result.Error, "could not update the company")
+	}
+	if result.RowsAffected == 0 {
+		return models.Company{}, apperr.NotFound("could not find the company")
+	}
+	return r.ViewCompanyById(ctx, cid)
+}
+
+// DeleteCompany soft deletes the company along with its jobs, all of them get the same deletion time
+// so that RestoreCompany can bring back exactly the jobs that went away with the company
+func (r *Repo) DeleteCompany(ctx context.Context, cid uint64) error {
+	db, cancel := r.withTimeout(ctx, opWrite)
+	defer cancel()
+	now := time.Now()
+	err := db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&models.Company{}).Where("id = ?", cid).Update("deleted_at", now)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		return tx.Model(&models.Jobs{}).Where("cid = ?", cid).Update("deleted_at", now).Error
+	})
+	if err != nil {
+		log.Info().Err(err).Send()
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return dbError(err, "could not find the company")
+		}
+		return dbError(err, "could not delete the company")
+	}
+	return nil
+}
+
+func (r *Repo) RestoreCompany(ctx context.Context, cid uint64) (models.Company, error) {
+	db, cancel := r.withTimeout(ctx, opWrite)
+	defer cancel()
 	var companyData models.Company
-	result := r.db.Where("id = ?", cid).First(&companyData)
+	result := db.Unscoped().Where("id = ?", cid).First(&companyData)
 	if result.Error != nil {
 		log.Info().Err(result.Error).Send()
-		return models.Company{}, errors.New("could not find the company")
+		return models.Company{}, dbError(result.Error, "could not find the company")
+	}
+	if !companyData.DeletedAt.Valid {
+		return models.Company{}, apperr.Conflict("company is not deleted")
 	}
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Unscoped().Model(&models.Company{}).Where("id = ?", cid).Update("deleted_at", nil).Error
+		if err != nil {
+			return err
+		}
+		return tx.Unscoped().Model(&models.Jobs{}).
+			Where("cid = ? AND deleted_at = ?", cid, companyData.DeletedAt.Time).
+			Update("deleted_at", nil).Error
+	})
+	if err != nil {
+		log.Info().Err(err).Send()
+		return models.Company{}, dbError(err, "could not restore the company")
+	}
+
+	companyData.DeletedAt = gorm.DeletedAt{}
 	return companyData, nil
 }
@@ -0,0 +1,23 @@
+package repository
+
+import "testing"
+
+func Test_likeEscaper(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want string
+	}{
+		{name: "plain text", text: "tek", want: "tek"},
+		{name: "percent", text: "%", want: `\%`},
+		{name: "underscore", text: "a_b", want: `a\_b`},
+		{name: "backslash first", text: `\%`, want: `\\\%`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := likeEscaper.Replace(tt.text); got != tt.want {
+				t.Errorf("likeEscaper.Replace(%q) = %q, want %q", tt.text, got, tt.want)
+			}
+		})
+	}
+}
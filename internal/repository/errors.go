@@ -0,0 +1,31 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/afthaab/job-portal/internal/apperr"
+	"gorm.io/gorm"
+)
+
+// dbError turns an error from gorm into a domain error carrying the message for the client,
+// errors that already are domain errors are passed on untouched
+func dbError(err error, msg string) error {
+	var appErr *apperr.Error
+	switch {
+	case errors.As(err, &appErr):
+		return err
+	case errors.Is(err, context.Canceled):
+		return apperr.Wrap(apperr.KindCanceled, "the request was canceled before the database answered", err)
+	case errors.Is(err, context.DeadlineExceeded):
+		return apperr.Wrap(apperr.KindTimeout, "the database took too long to answer, please try again", err)
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return apperr.Wrap(apperr.KindNotFound, msg, err)
+	case errors.Is(err, gorm.ErrDuplicatedKey):
+		return apperr.Wrap(apperr.KindConflict, msg+", it already exists", err)
+	case errors.Is(err, gorm.ErrForeignKeyViolated):
+		return apperr.Wrap(apperr.KindConflict, msg+", it is still referenced or refers to a missing record", err)
+	default:
+		return apperr.Internal(msg, err)
+	}
+}
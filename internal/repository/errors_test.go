@@ -0,0 +1,76 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/afthaab/job-portal/internal/apperr"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+func Test_dbError(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want apperr.Kind
+	}{
+		{name: "not found", err: gorm.ErrRecordNotFound, want: apperr.KindNotFound},
+		{name: "duplicate", err: gorm.ErrDuplicatedKey, want: apperr.KindConflict},
+		{name: "client went away", err: fmt.Errorf("timeout: %w", context.Canceled), want: apperr.KindCanceled},
+		{name: "deadline", err: fmt.Errorf("timeout: %w", context.DeadlineExceeded), want: apperr.KindTimeout},
+		{name: "domain error", err: apperr.Forbidden("not your company"), want: apperr.KindForbidden},
+		{name: "anything else", err: errors.New("connection refused"), want: apperr.KindInternal},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := apperr.KindOf(dbError(tt.err, "could not find the job")); got != tt.want {
+				t.Errorf("dbError() kind = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRepo_withTimeout(t *testing.T) {
+	db, err := gorm.Open(postgres.New(postgres.Config{DSN: "host=localhost"}), &gorm.Config{DryRun: true, DisableAutomaticPing: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := &Repo{db: db, timeouts: Timeouts{Read: time.Second, Search: time.Minute}}
+
+	tests := []struct {
+		name      string
+		op        operation
+		wantLimit time.Duration
+	}{
+		{name: "read", op: opRead, wantLimit: time.Second},
+		{name: "search", op: opSearch, wantLimit: time.Minute},
+		{name: "no write timeout", op: opWrite},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conn, cancel := r.withTimeout(context.Background(), tt.op)
+			defer cancel()
+			deadline, ok := conn.Statement.Context.Deadline()
+			if ok != (tt.wantLimit > 0) {
+				t.Fatalf("withTimeout() has deadline %v, want %v", ok, tt.wantLimit > 0)
+			}
+			if ok && time.Until(deadline) > tt.wantLimit {
+				t.Errorf("withTimeout() deadline in %v, want at most %v", time.Until(deadline), tt.wantLimit)
+			}
+		})
+	}
+
+	// the request deadline still wins when it is shorter
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+	defer cancel()
+	conn, cancelQuery := r.withTimeout(ctx, opSearch)
+	defer cancelQuery()
+	deadline, _ := conn.Statement.Context.Deadline()
+	if time.Until(deadline) > time.Millisecond {
+		t.Errorf("withTimeout() extended the request deadline")
+	}
+}
@@ -2,48 +2,125 @@ package repository
 
 import (
 	"context"
-	"errors"
 
+	"github.com/afthaab/job-portal/internal/apperr"
 	"github.com/afthaab/job-portal/internal/models"
 	"github.com/rs/zerolog/log"
+	"gorm.io/gorm"
 )
 
 func (r *Repo) ViewJobDetailsBy(ctx context.Context, jid uint64) (models.Jobs, error) {
+	db, cancel := r.withTimeout(ctx, opRead)
+	defer cancel()
 	var jobData models.Jobs
-	result := r.db.Where("id = ?", jid).Find(&jobData)
+	result := db.Where("id = ?", jid).First(&jobData)
 	if result.Error != nil {
 		log.Info().Err(result.Error).Send()
-		return models.Jobs{}, errors.New("could not create the jobs")
+		return models.Jobs{}, dbError(result.Error, "could not find the job")
 	}
 	return jobData, nil
 }
 
 func (r *Repo) CreateJob(ctx context.Context, jobData models.Jobs) (models.Jobs, error) {
-	result := r.db.Create(&jobData)
+	db, cancel := r.withTimeout(ctx, opWrite)
+	defer cancel()
+	result := db.Create(&jobData)
 	if result.Error != nil {
 		log.Info().Err(result.Error).Send()
-		return models.Jobs{}, errors.New("could not create the jobs")
+		return models.Jobs{}, dbError(result.Error, "could not create the jobs")
 	}
 	return jobData, nil
 }
 
-func (r *Repo) FindAllJobs(ctx context.Context) ([]models.Jobs, error) {
+func (r *Repo) FindAllJobs(ctx context.Context, filter models.JobFilter, page models.PageQuery) ([]models.Jobs, int64, error) {
+	db, cancel := r.withTimeout(ctx, opRead)
+	defer cancel()
+	query := db.Model(&models.Jobs{}).
+		Joins("JOIN companies ON companies.id = jobs.cid AND companies.deleted_at IS NULL")
+	if filter.Cid != 0 {
+		query = query.Where("jobs.cid = ?", filter.Cid)
+	}
+	if filter.Location != "" {
+		query = query.Where("lower(companies.location) = lower(?)", filter.Location)
+	}
+	if filter.Field != "" {
+		query = query.Where("lower(companies.field) = lower(?)", filter.Field)
+	}
+	// a job matches the salary range when its own range overlaps it
+	if filter.MinSalary != nil {
+		query = query.Where("jobs.salary_max_amount >= ?", *filter.MinSalary)
+	}
+	if filter.MaxSalary != nil {
+		query = query.Where("jobs.salary_min_amount <= ?", *filter.MaxSalary)
+	}
+	if filter.Currency != "" {
+		query = query.Where("jobs.salary_currency = ?", filter.Currency)
+	}
+	if filter.PayPeriod != "" {
+		query = query.Where("jobs.salary_pay_period = ?", filter.PayPeriod)
+	}
+	if filter.OpenOnly {
+		query = query.Scopes(visibleJobs(filter.MemberOf))
+	}
+
 	var jobDatas []models.Jobs
-	result := r.db.Find(&jobDatas)
+	total, err := paginate(query, page, jobSortColumns, "jobs.id", &jobDatas)
+	if err != nil {
+		log.Info().Err(err).Send()
+		return nil, 0, dbError(err, "could not find the jobs")
+	}
+	return jobDatas, total, nil
+}
+
+// jobUpdateColumns are the editable columns of a job, an update always writes all of them
+// so that zero values like a notice period of 0 days are stored as well
+var jobUpdateColumns = []string{
+	"name",
+	"description",
+	"status",
+	"notice_period_days",
+	"salary_min_amount",
+	"salary_max_amount",
+	"salary_currency",
+	"salary_pay_period",
+	"salary_equity",
+	"needs_review",
+}
+
+func (r *Repo) UpdateJob(ctx context.Context, jid uint64, jobData models.Jobs) (models.Jobs, error) {
+	db, cancel := r.withTimeout(ctx, opWrite)
+	defer cancel()
+	result := db.Model(&models.Jobs{}).Where("id = ?", jid).Select(jobUpdateColumns).Updates(jobData)
 	if result.Error != nil {
 		log.Info().Err(result.Error).Send()
-		return nil, errors.New("could not find the jobs")
+		return models.Jobs{}, dbError(result.Error, "could not update the job")
 	}
-	return jobDatas, nil
-
+	if result.RowsAffected == 0 {
+		return models.Jobs{}, apperr.NotFound("could not find the job")
+	}
+	return r.ViewJobDetailsBy(ctx, jid)
 }
 
-func (r *Repo) FindJob(ctx context.Context, cid uint64) ([]models.Jobs, error) {
-	var jobData []models.Jobs
-	result := r.db.Where("cid = ?", cid).Find(&jobData)
+func (r *Repo) DeleteJob(ctx context.Context, jid uint64) error {
+	db, cancel := r.withTimeout(ctx, opWrite)
+	defer cancel()
+	result := db.Where("id = ?", jid).Delete(&models.Jobs{})
 	if result.Error != nil {
 		log.Info().Err(result.Error).Send()
-		return nil, errors.New("could not find the company")
+		return dbError(result.Error, "could not delete the job")
+	}
+	if result.RowsAffected == 0 {
+		return apperr.NotFound("could not find the job")
+	}
+	return nil
+}
+
+// visibleJobs limits a query to open jobs and to every job of the given companies
+func visibleJobs(memberOf []uint) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if len(memberOf) == 0 {
+			return db.Where("jobs.status = ?", models.JobOpen)
+		}
+		return db.Where("(jobs.status = ? OR jobs.cid IN ?)", models.JobOpen, memberOf)
 	}
-	return jobData, nil
 }
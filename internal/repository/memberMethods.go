@@ -0,0 +1,201 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/afthaab/job-portal/internal/apperr"
+	"github.com/afthaab/job-portal/internal/models"
+	"github.com/rs/zerolog/log"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var memberSortColumns = map[string]string{
+	"role":       "company_members.role",
+	"created_at": "company_members.created_at",
+}
+
+// ErrLastOwner stops a company from being left without an owner
+var ErrLastOwner = apperr.Conflict("the last owner of a company cannot be removed or demoted")
+
+// requireOtherOwner fails with ErrLastOwner when uid is the only owner of the company. The owner rows stay
+// locked until the transaction ends so two requests taking away the last two owners can not both pass
+func requireOtherOwner(tx *gorm.DB, cid uint64, uid uint64) error {
+	var owners []uint64
+	err := tx.Model(&models.CompanyMember{}).
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("cid = ? AND role = ?", cid, models.MemberOwner).
+		Pluck("uid", &owners).Error
+	if err != nil {
+		return err
+	}
+	if onlyOwner(owners, uid) {
+		return ErrLastOwner
+	}
+	return nil
+}
+
+// onlyOwner reports whether uid is the one owner left among owners
+func onlyOwner(owners []uint64, uid uint64) bool {
+	for _, owner := range owners {
+		if owner != uid {
+			return false
+		}
+	}
+	return true
+}
+
+// FindCompanyMember returns the membership of the user in the company, the ID is 0 when the user is not a member
+func (r *Repo) FindCompanyMember(ctx context.Context, cid uint64, uid uint64) (models.CompanyMember, error) {
+	db, cancel := r.withTimeout(ctx, opRead)
+	defer cancel()
+	var memberData models.CompanyMember
+	result := db.Where("cid = ? AND uid = ?", cid, uid).Limit(1).Find(&memberData)
+	if result.Error != nil {
+		log.Info().Err(result.Error).Send()
+		return models.CompanyMember{}, dbError(result.Error, "could not find the membership")
+	}
+	return memberData, nil
+}
+
+// FindMemberCompanies returns the ids of every company the user is a member of
+func (r *Repo) FindMemberCompanies(ctx context.Context, uid uint64) ([]uint, error) {
+	db, cancel := r.withTimeout(ctx, opRead)
+	defer cancel()
+	var cids []uint
+	result := db.Model(&models.CompanyMember{}).Where("uid = ?", uid).Pluck("cid", &cids)
+	if result.Error != nil {
+		log.Info().Err(result.Error).Send()
+		return nil, dbError(result.Error, "could not find the companies of the user")
+	}
+	return cids, nil
+}
+
+func (r *Repo) FindCompanyMembers(ctx context.Context, cid uint64, page models.PageQuery) ([]models.CompanyMember, int64, error) {
+	db, cancel := r.withTimeout(ctx, opRead)
+	defer cancel()
+	query := db.Model(&models.CompanyMember{}).Where("company_members.cid = ?", cid)
+
+	var memberDatas []models.CompanyMember
+	total, err := paginate(query, page, memberSortColumns, "company_members.id", &memberDatas)
+	if err != nil {
+		log.Info().Err(err).Send()
+		return nil, 0, dbError(err, "could not find the members")
+	}
+	return memberDatas, total, nil
+}
+
+// DeleteCompanyMember removes the user from the company, unless the user is the only owner left
+func (r *Repo) DeleteCompanyMember(ctx context.Context, cid uint64, uid uint64) error {
+	db, cancel := r.withTimeout(ctx, opWrite)
+	defer cancel()
+	err := db.Transaction(func(tx *gorm.DB) error {
+		var memberData models.CompanyMember
+		err := tx.Where("cid = ? AND uid = ?", cid, uid).First(&memberData).Error
+		if err != nil {
+			return err
+		}
+		if memberData.Role == models.MemberOwner {
+			err = requireOtherOwner(tx, cid, uid)
+			if err != nil {
+				return err
+			}
+		}
+		// the membership is removed for good so the user can be invited again
+		return tx.Unscoped().Delete(&memberData).Error
+	})
+	if err != nil {
+		log.Info().Err(err).Send()
+		return dbError(err, "could not remove the member")
+	}
+	return nil
+}
+
+func (r *Repo) CreateInvitation(ctx context.Context, invitationData models.CompanyInvitation) (models.CompanyInvitation, error) {
+	db, cancel := r.withTimeout(ctx, opWrite)
+	defer cancel()
+	result := db.Create(&invitationData)
+	if result.Error != nil {
+		log.Info().Err(result.Error).Send()
+		return models.CompanyInvitation{}, dbError(result.Error, "could not create the invitation")
+	}
+	return invitationData, nil
+}
+
+func (r *Repo) FindInvitation(ctx context.Context, iid uint64) (models.CompanyInvitation, error) {
+	db, cancel := r.withTimeout(ctx, opRead)
+	defer cancel()
+	var invitationData models.CompanyInvitation
+	result := db.Where("id = ?", iid).First(&invitationData)
+	if result.Error != nil {
+		log.Info().Err(result.Error).Send()
+		return models.CompanyInvitation{}, dbError(result.Error, "could not find the invitation")
+	}
+	return invitationData, nil
+}
+
+// FindPendingInvitations returns the invitations sent to the email that were not accepted and have not expired
+func (r *Repo) FindPendingInvitations(ctx context.Context, email string) ([]models.CompanyInvitation, error) {
+	db, cancel := r.withTimeout(ctx, opRead)
+	defer cancel()
+	var invitationDatas []models.CompanyInvitation
+	result := db.Where("email = ? AND accepted_at IS NULL AND expires_at > ?", email, time.Now()).
+		Order("id").Find(&invitationDatas)
+	if result.Error != nil {
+		log.Info().Err(result.Error).Send()
+		return nil, dbError(result.Error, "could not find the invitations")
+	}
+	return invitationDatas, nil
+}
+
+// AcceptInvitation marks the invitation accepted and adds the member in the same transaction, an existing
+// membership takes the role of the invitation. Candidates invited to recruit become recruiters
+func (r *Repo) AcceptInvitation(ctx context.Context, invitationData models.CompanyInvitation, memberData models.CompanyMember) (models.CompanyMember, error) {
+	db, cancel := r.withTimeout(ctx, opWrite)
+	defer cancel()
+	err := db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&invitationData).Where("accepted_at IS NULL").Update("accepted_at", time.Now())
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return apperr.Conflict("invitation was already accepted")
+		}
+
+		var existing models.CompanyMember
+		err := tx.Where("cid = ? AND uid = ?", memberData.Cid, memberData.Uid).Limit(1).Find(&existing).Error
+		if err != nil {
+			return err
+		}
+		if existing.ID != 0 {
+			// an invitation to a lesser role does not demote the last owner
+			if existing.Role == models.MemberOwner && memberData.Role != models.MemberOwner {
+				err = requireOtherOwner(tx, uint64(memberData.Cid), uint64(memberData.Uid))
+				if err != nil {
+					return err
+				}
+			}
+			existing.Role = memberData.Role
+			memberData = existing
+			err = tx.Model(&existing).Update("role", memberData.Role).Error
+		} else {
+			err = tx.Create(&memberData).Error
+		}
+		if err != nil {
+			return err
+		}
+
+		if memberData.Role == models.MemberViewer {
+			return nil
+		}
+		return tx.Model(&models.User{}).
+			Where("id = ? AND role = ?", memberData.Uid, models.RoleCandidate).
+			Update("role", models.RoleRecruiter).Error
+	})
+	if err != nil {
+		log.Info().Err(err).Send()
+		return models.CompanyMember{}, dbError(err, "could not accept the invitation")
+	}
+	return memberData, nil
+}
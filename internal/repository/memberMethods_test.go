@@ -0,0 +1,23 @@
+package repository
+
+import "testing"
+
+func Test_onlyOwner(t *testing.T) {
+	tests := []struct {
+		name   string
+		owners []uint64
+		uid    uint64
+		want   bool
+	}{
+		{name: "only owner leaving", owners: []uint64{1}, uid: 1, want: true},
+		{name: "one of two owners leaving", owners: []uint64{1, 2}, uid: 1},
+		{name: "removing the other owner", owners: []uint64{1, 2}, uid: 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := onlyOwner(tt.owners, tt.uid); got != tt.want {
+				t.Errorf("onlyOwner() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
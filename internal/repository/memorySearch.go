@@ -0,0 +1,131 @@
+package repository
+
+import (
+	"context"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/afthaab/job-portal/internal/models"
+)
+
+// weights of the fields, the same ones postgres uses for the A, B, C and D labels of the search vector
+const (
+	weightJobName     = 1.0
+	weightCompany     = 0.4
+	weightDescription = 0.2
+	weightLocation    = 0.1
+)
+
+// MemoryJobSearch is a JobSearcher that keeps the jobs in memory, it matches whole words instead of stems
+// so it is only meant for tests and local runs without postgres
+type MemoryJobSearch struct {
+	companies map[uint]models.Company
+	jobs      []models.Jobs
+}
+
+func NewMemoryJobSearch(companies []models.Company, jobs []models.Jobs) *MemoryJobSearch {
+	companyByID := make(map[uint]models.Company, len(companies))
+	for _, company := range companies {
+		companyByID[company.ID] = company
+	}
+	return &MemoryJobSearch{
+		companies: companyByID,
+		jobs:      jobs,
+	}
+}
+
+func (m *MemoryJobSearch) SearchJobs(ctx context.Context, search models.JobSearch, page models.PageQuery) ([]models.JobSearchResult, int64, error) {
+	terms := strings.FieldsFunc(strings.ToLower(search.Query), func(r rune) bool {
+		return !(r >= 'a' && r <= 'z' || r >= '0' && r <= '9')
+	})
+	if len(terms) == 0 {
+		return []models.JobSearchResult{}, 0, nil
+	}
+
+	termPatterns := make([]*regexp.Regexp, 0, len(terms))
+	quoted := make([]string, 0, len(terms))
+	for _, term := range terms {
+		termPatterns = append(termPatterns, regexp.MustCompile(`(?i)\b`+regexp.QuoteMeta(term)+`\b`))
+		quoted = append(quoted, regexp.QuoteMeta(term))
+	}
+	highlight := regexp.MustCompile(`(?i)\b(` + strings.Join(quoted, "|") + `)\b`)
+
+	var results []models.JobSearchResult
+	for _, job := range m.jobs {
+		company, ok := m.companies[job.Cid]
+		if !ok || job.DeletedAt.Valid || company.DeletedAt.Valid {
+			continue
+		}
+		if search.OpenOnly && job.Status != models.JobOpen && !containsID(search.MemberOf, job.Cid) {
+			continue
+		}
+
+		fields := []struct {
+			text   string
+			weight float64
+		}{
+			{job.Name, weightJobName},
+			{company.Name, weightCompany},
+			{company.Field, weightCompany},
+			{job.Description, weightDescription},
+			{company.Location, weightLocation},
+		}
+
+		// every term has to be found in at least one field
+		var rank float64
+		matched := true
+		for _, pattern := range termPatterns {
+			found := false
+			for _, field := range fields {
+				if pattern.MatchString(field.text) {
+					rank += field.weight
+					found = true
+				}
+			}
+			if !found {
+				matched = false
+				break
+			}
+		}
+		if !matched {
+			continue
+		}
+
+		results = append(results, models.JobSearchResult{
+			Job:  job,
+			Rank: rank,
+			Highlights: models.JobHighlights{
+				Name:        markHighlight(highlight.ReplaceAllString(unmark.Replace(job.Name), markStart+"$1"+markStop)),
+				Company:     markHighlight(highlight.ReplaceAllString(unmark.Replace(company.Name), markStart+"$1"+markStop)),
+				Description: markHighlight(highlight.ReplaceAllString(unmark.Replace(job.Description), markStart+"$1"+markStop)),
+			},
+		})
+	}
+
+	sort.SliceStable(results, func(i, j int) bool {
+		if results[i].Rank != results[j].Rank {
+			return results[i].Rank > results[j].Rank
+		}
+		return results[i].Job.ID < results[j].Job.ID
+	})
+
+	total := int64(len(results))
+	if page.Offset >= len(results) {
+		return []models.JobSearchResult{}, total, nil
+	}
+	results = results[page.Offset:]
+	if page.Limit > 0 && len(results) > page.Limit {
+		results = results[:page.Limit]
+	}
+	return results, total, nil
+}
+
+func containsID(ids []uint, id uint) bool {
+	for _, v := range ids {
+		if v == id {
+			return true
+		}
+	}
+	return false
+}
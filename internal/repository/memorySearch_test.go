@@ -0,0 +1,126 @@
+package repository
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/afthaab/job-portal/internal/models"
+	"gorm.io/gorm"
+)
+
+func TestMemoryJobSearch_SearchJobs(t *testing.T) {
+	companies := []models.Company{
+		{Model: gorm.Model{ID: 1}, Name: "Infosys", Location: "Bangalore", Field: "IT"},
+		{Model: gorm.Model{ID: 2}, Name: "Golang Labs", Location: "Chennai", Field: "Software"},
+	}
+	jobs := []models.Jobs{
+		{Model: gorm.Model{ID: 1}, Cid: 1, Name: "Golang developer", Description: "build apis", Status: models.JobOpen},
+		{Model: gorm.Model{ID: 2}, Cid: 2, Name: "Web developer", Description: "react and golang", Status: models.JobOpen},
+		{Model: gorm.Model{ID: 3}, Cid: 1, Name: "Golang lead", Status: models.JobClosed},
+		{Model: gorm.Model{ID: 4}, Cid: 2, Name: "Java developer", Status: models.JobOpen},
+	}
+
+	tests := []struct {
+		name      string
+		search    models.JobSearch
+		page      models.PageQuery
+		wantIDs   []uint
+		wantTotal int64
+	}{
+		{
+			name:      "ranked by relevance",
+			search:    models.JobSearch{Query: "golang", OpenOnly: true},
+			page:      models.PageQuery{Limit: 10},
+			wantIDs:   []uint{1, 2, 4},
+			wantTotal: 3,
+		},
+		{
+			name:      "closed jobs for recruiters",
+			search:    models.JobSearch{Query: "golang"},
+			page:      models.PageQuery{Limit: 10},
+			wantIDs:   []uint{1, 3, 2, 4},
+			wantTotal: 4,
+		},
+		{
+			name:      "every term has to match",
+			search:    models.JobSearch{Query: "developer chennai", OpenOnly: true},
+			page:      models.PageQuery{Limit: 10},
+			wantIDs:   []uint{2, 4},
+			wantTotal: 2,
+		},
+		{
+			name:      "second page",
+			search:    models.JobSearch{Query: "developer", OpenOnly: true},
+			page:      models.PageQuery{Limit: 2, Offset: 2},
+			wantIDs:   []uint{4},
+			wantTotal: 3,
+		},
+		{
+			name:      "no terms",
+			search:    models.JobSearch{Query: "  "},
+			page:      models.PageQuery{Limit: 10},
+			wantIDs:   []uint{},
+			wantTotal: 0,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := NewMemoryJobSearch(companies, jobs)
+			got, total, err := m.SearchJobs(context.Background(), tt.search, tt.page)
+			if err != nil {
+				t.Errorf("MemoryJobSearch.SearchJobs() error = %v", err)
+				return
+			}
+			gotIDs := []uint{}
+			for _, result := range got {
+				gotIDs = append(gotIDs, result.Job.ID)
+			}
+			if !reflect.DeepEqual(gotIDs, tt.wantIDs) {
+				t.Errorf("MemoryJobSearch.SearchJobs() ids = %v, want %v", gotIDs, tt.wantIDs)
+			}
+			if total != tt.wantTotal {
+				t.Errorf("MemoryJobSearch.SearchJobs() total = %v, want %v", total, tt.wantTotal)
+			}
+		})
+	}
+}
+
+func TestMemoryJobSearch_Highlights(t *testing.T) {
+	m := NewMemoryJobSearch(
+		[]models.Company{{Model: gorm.Model{ID: 1}, Name: "Golang Labs"}},
+		[]models.Jobs{{Model: gorm.Model{ID: 1}, Cid: 1, Name: "Senior GoLang developer", Description: "golang, postgres"}},
+	)
+	got, _, err := m.SearchJobs(context.Background(), models.JobSearch{Query: "golang"}, models.PageQuery{Limit: 10})
+	if err != nil || len(got) != 1 {
+		t.Fatalf("MemoryJobSearch.SearchJobs() = %v, %v", got, err)
+	}
+	want := models.JobHighlights{
+		Name:        "Senior <mark>GoLang</mark> developer",
+		Company:     "<mark>Golang</mark> Labs",
+		Description: "<mark>golang</mark>, postgres",
+	}
+	if got[0].Highlights != want {
+		t.Errorf("MemoryJobSearch.SearchJobs() highlights = %v, want %v", got[0].Highlights, want)
+	}
+}
+
+func TestMemoryJobSearch_HighlightsEscaped(t *testing.T) {
+	m := NewMemoryJobSearch(
+		[]models.Company{{Model: gorm.Model{ID: 1}, Name: "Evil & Co"}},
+		[]models.Jobs{{Model: gorm.Model{ID: 1}, Cid: 1, Name: "<script>alert(1)</script> developer", Description: "developer \x01<b>\x02"}},
+	)
+	got, _, err := m.SearchJobs(context.Background(), models.JobSearch{Query: "developer"}, models.PageQuery{Limit: 10})
+	if err != nil || len(got) != 1 {
+		t.Fatalf("MemoryJobSearch.SearchJobs() = %v, %v", got, err)
+	}
+	// only the marks are markup, the text and any marker characters in it are not
+	want := models.JobHighlights{
+		Name:        "&lt;script&gt;alert(1)&lt;/script&gt; <mark>developer</mark>",
+		Company:     "Evil &amp; Co",
+		Description: "<mark>developer</mark> &lt;b&gt;",
+	}
+	if got[0].Highlights != want {
+		t.Errorf("MemoryJobSearch.SearchJobs() highlights = %v, want %v", got[0].Highlights, want)
+	}
+}
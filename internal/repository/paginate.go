@@ -0,0 +1,60 @@
+package repository
+
+import (
+	"fmt"
+
+	"github.com/afthaab/job-portal/internal/apperr"
+	"github.com/afthaab/job-portal/internal/models"
+	"gorm.io/gorm"
+)
+
+var errInvalidSort = apperr.Validation("invalid sort field")
+
+var (
+	companySortColumns = map[string]string{
+		"name":       "companies.name",
+		"location":   "companies.location",
+		"field":      "companies.field",
+		"created_at": "companies.created_at",
+	}
+	jobSortColumns = map[string]string{
+		"name":       "jobs.name",
+		"salary":     "jobs.salary_max_amount",
+		"notice":     "jobs.notice_period_days",
+		"created_at": "jobs.created_at",
+		"updated_at": "jobs.updated_at",
+	}
+	applicationSortColumns = map[string]string{
+		"status":     "applications.status",
+		"created_at": "applications.created_at",
+		"updated_at": "applications.updated_at",
+	}
+)
+
+// paginate counts the rows matched by the query and then loads the requested page into dest,
+// the sort field has to be one of the columns, the id is used as a tie breaker to keep pages stable
+func paginate(query *gorm.DB, page models.PageQuery, columns map[string]string, idColumn string, dest interface{}) (int64, error) {
+	order := idColumn
+	if page.Sort != "" {
+		column, ok := columns[page.Sort]
+		if !ok {
+			return 0, errInvalidSort
+		}
+		order = fmt.Sprintf("%s, %s", column, idColumn)
+		if page.Desc {
+			order = fmt.Sprintf("%s DESC, %s DESC", column, idColumn)
+		}
+	}
+
+	var total int64
+	err := query.Session(&gorm.Session{}).Count(&total).Error
+	if err != nil {
+		return 0, err
+	}
+
+	err = query.Order(order).Limit(page.Limit).Offset(page.Offset).Find(dest).Error
+	if err != nil {
+		return 0, err
+	}
+	return total, nil
+}
@@ -3,36 +3,133 @@ package repository
 import (
 	"context"
 	"errors"
+	"time"
 
 	"github.com/afthaab/job-portal/internal/models"
 	"gorm.io/gorm"
 )
 
 type Repo struct {
-	db *gorm.DB
+	db       *gorm.DB
+	timeouts Timeouts
+}
+
+// Timeouts bound how long each kind of query may run on top of the deadline of the request,
+// a zero timeout leaves the query to the request context alone
+type Timeouts struct {
+	Read   time.Duration
+	Write  time.Duration
+	Search time.Duration
+}
+
+// Option changes one of the defaults of the repository
+type Option func(*Repo)
+
+// WithTimeouts sets the timeouts of the reads, the writes and the keyword searches
+func WithTimeouts(timeouts Timeouts) Option {
+	return func(r *Repo) {
+		r.timeouts = timeouts
+	}
+}
+
+// operation is the kind of a repository call, it picks the timeout the call runs with
+type operation int
+
+const (
+	opRead operation = iota
+	opWrite
+	opSearch
+)
+
+// withTimeout binds the db to the request context and the timeout of the operation, so a client that
+// goes away or a slow query cancels the statement in postgres instead of leaving it running
+func (r *Repo) withTimeout(ctx context.Context, op operation) (*gorm.DB, context.CancelFunc) {
+	timeout := r.timeouts.Read
+	switch op {
+	case opWrite:
+		timeout = r.timeouts.Write
+	case opSearch:
+		timeout = r.timeouts.Search
+	}
+	if timeout <= 0 {
+		return r.db.WithContext(ctx), func() {}
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	return r.db.WithContext(ctx), cancel
 }
 
 //go:generate mockgen -source=repository.go -destination=repository_mock.go -package=repository
 
 type UserRepo interface {
+	JobSearcher
+
 	CreateUser(ctx context.Context, userData models.User) (models.User, error)
 	CheckEmail(ctx context.Context, email string) (models.User, error)
+	FindUserById(ctx context.Context, uid uint64) (models.User, error)
+	VerifyUserEmail(ctx context.Context, uid uint64, email string) error
+	UpdateUserPassword(ctx context.Context, uid uint64, passwordHash string, changedAt time.Time) error
+	UpdatePasswordHash(ctx context.Context, uid uint64, passwordHash string) error
+	FindPasswordChanges(ctx context.Context, since time.Time) ([]models.User, error)
+	UpdateUserProfile(ctx context.Context, uid uint64, profile models.UpdateProfile) (models.User, error)
+	ChangeUserEmail(ctx context.Context, uid uint64, email string) error
+	EraseUser(ctx context.Context, uid uint64, revoked models.RevokedSubject) error
+
+	CreateRefreshToken(ctx context.Context, tokenData models.RefreshToken) (models.RefreshToken, error)
+	FindRefreshToken(ctx context.Context, tokenHash string) (models.RefreshToken, error)
+	RotateRefreshToken(ctx context.Context, oldToken models.RefreshToken, newToken models.RefreshToken) (models.RefreshToken, error)
+	RevokeRefreshTokenFamily(ctx context.Context, family string) error
+	RevokeUserRefreshTokens(ctx context.Context, uid uint64) error
+	RevokeAccessToken(ctx context.Context, tokenData models.RevokedToken) error
+	FindRevokedTokens(ctx context.Context) ([]models.RevokedToken, error)
+	FindRevokedSubjects(ctx context.Context) ([]models.RevokedSubject, error)
+	CreateUserToken(ctx context.Context, tokenData models.UserToken) (models.UserToken, error)
+	FindUserToken(ctx context.Context, purpose string, tokenHash string) (models.UserToken, error)
+	UseUserToken(ctx context.Context, purpose string, tokenHash string) (models.UserToken, error)
+	RevokeUserTokens(ctx context.Context, uid uint64, purpose string) error
 
 	CreateCompany(ctx context.Context, companyData models.Company) (models.Company, error)
-	ViewCompanies(ctx context.Context) ([]models.Company, error)
+	ViewCompanies(ctx context.Context, filter models.CompanyFilter, page models.PageQuery) ([]models.Company, int64, error)
 	ViewCompanyById(ctx context.Context, cid uint64) (models.Company, error)
+	UpdateCompany(ctx context.Context, cid uint64, companyData models.Company) (models.Company, error)
+	DeleteCompany(ctx context.Context, cid uint64) error
+	RestoreCompany(ctx context.Context, cid uint64) (models.Company, error)
+
+	FindCompanyMember(ctx context.Context, cid uint64, uid uint64) (models.CompanyMember, error)
+	FindMemberCompanies(ctx context.Context, uid uint64) ([]uint, error)
+	FindCompanyMembers(ctx context.Context, cid uint64, page models.PageQuery) ([]models.CompanyMember, int64, error)
+	DeleteCompanyMember(ctx context.Context, cid uint64, uid uint64) error
+	CreateInvitation(ctx context.Context, invitationData models.CompanyInvitation) (models.CompanyInvitation, error)
+	FindInvitation(ctx context.Context, iid uint64) (models.CompanyInvitation, error)
+	FindPendingInvitations(ctx context.Context, email string) ([]models.CompanyInvitation, error)
+	AcceptInvitation(ctx context.Context, invitationData models.CompanyInvitation, memberData models.CompanyMember) (models.CompanyMember, error)
 
 	CreateJob(ctx context.Context, jobData models.Jobs) (models.Jobs, error)
-	FindJob(ctx context.Context, cid uint64) ([]models.Jobs, error)
-	FindAllJobs(ctx context.Context) ([]models.Jobs, error)
+	FindAllJobs(ctx context.Context, filter models.JobFilter, page models.PageQuery) ([]models.Jobs, int64, error)
 	ViewJobDetailsBy(ctx context.Context, jid uint64) (models.Jobs, error)
+	UpdateJob(ctx context.Context, jid uint64, jobData models.Jobs) (models.Jobs, error)
+	DeleteJob(ctx context.Context, jid uint64) error
+
+	CreateApplication(ctx context.Context, applicationData models.Application) (models.Application, error)
+	FindApplication(ctx context.Context, aid uint64) (models.Application, error)
+	FindApplicationsByUser(ctx context.Context, uid uint64, page models.PageQuery) ([]models.Application, int64, error)
+	FindApplicationsByJob(ctx context.Context, jid uint64, page models.PageQuery) ([]models.Application, int64, error)
+	UpdateApplicationStatus(ctx context.Context, applicationData models.Application, auditData models.ApplicationAudit) (models.Application, error)
+}
+
+// JobSearcher finds jobs by keyword, Repo searches with postgres and MemoryJobSearch searches in memory
+type JobSearcher interface {
+	SearchJobs(ctx context.Context, search models.JobSearch, page models.PageQuery) ([]models.JobSearchResult, int64, error)
 }
 
-func NewRepository(db *gorm.DB) (UserRepo, error) {
+func NewRepository(db *gorm.DB, opts ...Option) (UserRepo, error) {
 	if db == nil {
 		return nil, errors.New("db cannot be null")
 	}
-	return &Repo{
+	r := &Repo{
 		db: db,
-	}, nil
+	}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r, nil
 }
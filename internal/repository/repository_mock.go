@@ -11,6 +11,7 @@ package repository
 import (
 	context "context"
 	reflect "reflect"
+	time "time"
 
 	models "github.com/afthaab/job-portal/internal/models"
 	gomock "go.uber.org/mock/gomock"
@@ -39,6 +40,35 @@ func (m *MockUserRepo) EXPECT() *MockUserRepoMockRecorder {
 	return m.recorder
 }
 
+// AcceptInvitation mocks base method.
+func (m *MockUserRepo) AcceptInvitation(ctx context.Context, invitationData models.CompanyInvitation, memberData models.CompanyMember) (models.CompanyMember, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AcceptInvitation", ctx, invitationData, memberData)
+	ret0, _ := ret[0].(models.CompanyMember)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AcceptInvitation indicates an expected call of AcceptInvitation.
+func (mr *MockUserRepoMockRecorder) AcceptInvitation(ctx, invitationData, memberData any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AcceptInvitation", reflect.TypeOf((*MockUserRepo)(nil).AcceptInvitation), ctx, invitationData, memberData)
+}
+
+// ChangeUserEmail mocks base method.
+func (m *MockUserRepo) ChangeUserEmail(ctx context.Context, uid uint64, email string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ChangeUserEmail", ctx, uid, email)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ChangeUserEmail indicates an expected call of ChangeUserEmail.
+func (mr *MockUserRepoMockRecorder) ChangeUserEmail(ctx, uid, email any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ChangeUserEmail", reflect.TypeOf((*MockUserRepo)(nil).ChangeUserEmail), ctx, uid, email)
+}
+
 // CheckEmail mocks base method.
 func (m *MockUserRepo) CheckEmail(ctx context.Context, email string) (models.User, error) {
 	m.ctrl.T.Helper()
@@ -54,6 +84,21 @@ func (mr *MockUserRepoMockRecorder) CheckEmail(ctx, email any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CheckEmail", reflect.TypeOf((*MockUserRepo)(nil).CheckEmail), ctx, email)
 }
 
+// CreateApplication mocks base method.
+func (m *MockUserRepo) CreateApplication(ctx context.Context, applicationData models.Application) (models.Application, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateApplication", ctx, applicationData)
+	ret0, _ := ret[0].(models.Application)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateApplication indicates an expected call of CreateApplication.
+func (mr *MockUserRepoMockRecorder) CreateApplication(ctx, applicationData any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateApplication", reflect.TypeOf((*MockUserRepo)(nil).CreateApplication), ctx, applicationData)
+}
+
 // CreateCompany mocks base method.
 func (m *MockUserRepo) CreateCompany(ctx context.Context, companyData models.Company) (models.Company, error) {
 	m.ctrl.T.Helper()
@@ -69,6 +114,21 @@ func (mr *MockUserRepoMockRecorder) CreateCompany(ctx, companyData any) *gomock.
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateCompany", reflect.TypeOf((*MockUserRepo)(nil).CreateCompany), ctx, companyData)
 }
 
+// CreateInvitation mocks base method.
+func (m *MockUserRepo) CreateInvitation(ctx context.Context, invitationData models.CompanyInvitation) (models.CompanyInvitation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateInvitation", ctx, invitationData)
+	ret0, _ := ret[0].(models.CompanyInvitation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateInvitation indicates an expected call of CreateInvitation.
+func (mr *MockUserRepoMockRecorder) CreateInvitation(ctx, invitationData any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateInvitation", reflect.TypeOf((*MockUserRepo)(nil).CreateInvitation), ctx, invitationData)
+}
+
 // CreateJob mocks base method.
 func (m *MockUserRepo) CreateJob(ctx context.Context, jobData models.Jobs) (models.Jobs, error) {
 	m.ctrl.T.Helper()
@@ -84,6 +144,21 @@ func (mr *MockUserRepoMockRecorder) CreateJob(ctx, jobData any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateJob", reflect.TypeOf((*MockUserRepo)(nil).CreateJob), ctx, jobData)
 }
 
+// CreateRefreshToken mocks base method.
+func (m *MockUserRepo) CreateRefreshToken(ctx context.Context, tokenData models.RefreshToken) (models.RefreshToken, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateRefreshToken", ctx, tokenData)
+	ret0, _ := ret[0].(models.RefreshToken)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateRefreshToken indicates an expected call of CreateRefreshToken.
+func (mr *MockUserRepoMockRecorder) CreateRefreshToken(ctx, tokenData any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateRefreshToken", reflect.TypeOf((*MockUserRepo)(nil).CreateRefreshToken), ctx, tokenData)
+}
+
 // CreateUser mocks base method.
 func (m *MockUserRepo) CreateUser(ctx context.Context, userData models.User) (models.User, error) {
 	m.ctrl.T.Helper()
@@ -99,49 +174,539 @@ func (mr *MockUserRepoMockRecorder) CreateUser(ctx, userData any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateUser", reflect.TypeOf((*MockUserRepo)(nil).CreateUser), ctx, userData)
 }
 
+// CreateUserToken mocks base method.
+func (m *MockUserRepo) CreateUserToken(ctx context.Context, tokenData models.UserToken) (models.UserToken, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateUserToken", ctx, tokenData)
+	ret0, _ := ret[0].(models.UserToken)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateUserToken indicates an expected call of CreateUserToken.
+func (mr *MockUserRepoMockRecorder) CreateUserToken(ctx, tokenData any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateUserToken", reflect.TypeOf((*MockUserRepo)(nil).CreateUserToken), ctx, tokenData)
+}
+
+// DeleteCompany mocks base method.
+func (m *MockUserRepo) DeleteCompany(ctx context.Context, cid uint64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteCompany", ctx, cid)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteCompany indicates an expected call of DeleteCompany.
+func (mr *MockUserRepoMockRecorder) DeleteCompany(ctx, cid any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteCompany", reflect.TypeOf((*MockUserRepo)(nil).DeleteCompany), ctx, cid)
+}
+
+// DeleteCompanyMember mocks base method.
+func (m *MockUserRepo) DeleteCompanyMember(ctx context.Context, cid, uid uint64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteCompanyMember", ctx, cid, uid)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteCompanyMember indicates an expected call of DeleteCompanyMember.
+func (mr *MockUserRepoMockRecorder) DeleteCompanyMember(ctx, cid, uid any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteCompanyMember", reflect.TypeOf((*MockUserRepo)(nil).DeleteCompanyMember), ctx, cid, uid)
+}
+
+// DeleteJob mocks base method.
+func (m *MockUserRepo) DeleteJob(ctx context.Context, jid uint64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteJob", ctx, jid)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteJob indicates an expected call of DeleteJob.
+func (mr *MockUserRepoMockRecorder) DeleteJob(ctx, jid any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteJob", reflect.TypeOf((*MockUserRepo)(nil).DeleteJob), ctx, jid)
+}
+
+// EraseUser mocks base method.
+func (m *MockUserRepo) EraseUser(ctx context.Context, uid uint64, revoked models.RevokedSubject) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "EraseUser", ctx, uid, revoked)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// EraseUser indicates an expected call of EraseUser.
+func (mr *MockUserRepoMockRecorder) EraseUser(ctx, uid, revoked any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EraseUser", reflect.TypeOf((*MockUserRepo)(nil).EraseUser), ctx, uid, revoked)
+}
+
 // FindAllJobs mocks base method.
-func (m *MockUserRepo) FindAllJobs(ctx context.Context) ([]models.Jobs, error) {
+func (m *MockUserRepo) FindAllJobs(ctx context.Context, filter models.JobFilter, page models.PageQuery) ([]models.Jobs, int64, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "FindAllJobs", ctx)
+	ret := m.ctrl.Call(m, "FindAllJobs", ctx, filter, page)
 	ret0, _ := ret[0].([]models.Jobs)
+	ret1, _ := ret[1].(int64)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// FindAllJobs indicates an expected call of FindAllJobs.
+func (mr *MockUserRepoMockRecorder) FindAllJobs(ctx, filter, page any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindAllJobs", reflect.TypeOf((*MockUserRepo)(nil).FindAllJobs), ctx, filter, page)
+}
+
+// FindApplication mocks base method.
+func (m *MockUserRepo) FindApplication(ctx context.Context, aid uint64) (models.Application, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindApplication", ctx, aid)
+	ret0, _ := ret[0].(models.Application)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// FindAllJobs indicates an expected call of FindAllJobs.
-func (mr *MockUserRepoMockRecorder) FindAllJobs(ctx any) *gomock.Call {
+// FindApplication indicates an expected call of FindApplication.
+func (mr *MockUserRepoMockRecorder) FindApplication(ctx, aid any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindAllJobs", reflect.TypeOf((*MockUserRepo)(nil).FindAllJobs), ctx)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindApplication", reflect.TypeOf((*MockUserRepo)(nil).FindApplication), ctx, aid)
 }
 
-// FindJob mocks base method.
-func (m *MockUserRepo) FindJob(ctx context.Context, cid uint64) ([]models.Jobs, error) {
+// FindApplicationsByJob mocks base method.
+func (m *MockUserRepo) FindApplicationsByJob(ctx context.Context, jid uint64, page models.PageQuery) ([]models.Application, int64, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "FindJob", ctx, cid)
-	ret0, _ := ret[0].([]models.Jobs)
+	ret := m.ctrl.Call(m, "FindApplicationsByJob", ctx, jid, page)
+	ret0, _ := ret[0].([]models.Application)
+	ret1, _ := ret[1].(int64)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// FindApplicationsByJob indicates an expected call of FindApplicationsByJob.
+func (mr *MockUserRepoMockRecorder) FindApplicationsByJob(ctx, jid, page any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindApplicationsByJob", reflect.TypeOf((*MockUserRepo)(nil).FindApplicationsByJob), ctx, jid, page)
+}
+
+// FindApplicationsByUser mocks base method.
+func (m *MockUserRepo) FindApplicationsByUser(ctx context.Context, uid uint64, page models.PageQuery) ([]models.Application, int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindApplicationsByUser", ctx, uid, page)
+	ret0, _ := ret[0].([]models.Application)
+	ret1, _ := ret[1].(int64)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// FindApplicationsByUser indicates an expected call of FindApplicationsByUser.
+func (mr *MockUserRepoMockRecorder) FindApplicationsByUser(ctx, uid, page any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindApplicationsByUser", reflect.TypeOf((*MockUserRepo)(nil).FindApplicationsByUser), ctx, uid, page)
+}
+
+// FindCompanyMember mocks base method.
+func (m *MockUserRepo) FindCompanyMember(ctx context.Context, cid, uid uint64) (models.CompanyMember, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindCompanyMember", ctx, cid, uid)
+	ret0, _ := ret[0].(models.CompanyMember)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// FindJob indicates an expected call of FindJob.
-func (mr *MockUserRepoMockRecorder) FindJob(ctx, cid any) *gomock.Call {
+// FindCompanyMember indicates an expected call of FindCompanyMember.
+func (mr *MockUserRepoMockRecorder) FindCompanyMember(ctx, cid, uid any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindJob", reflect.TypeOf((*MockUserRepo)(nil).FindJob), ctx, cid)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindCompanyMember", reflect.TypeOf((*MockUserRepo)(nil).FindCompanyMember), ctx, cid, uid)
 }
 
-// ViewCompanies mocks base method.
-func (m *MockUserRepo) ViewCompanies(ctx context.Context) ([]models.Company, error) {
+// FindCompanyMembers mocks base method.
+func (m *MockUserRepo) FindCompanyMembers(ctx context.Context, cid uint64, page models.PageQuery) ([]models.CompanyMember, int64, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "ViewCompanies", ctx)
-	ret0, _ := ret[0].([]models.Company)
+	ret := m.ctrl.Call(m, "FindCompanyMembers", ctx, cid, page)
+	ret0, _ := ret[0].([]models.CompanyMember)
+	ret1, _ := ret[1].(int64)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// FindCompanyMembers indicates an expected call of FindCompanyMembers.
+func (mr *MockUserRepoMockRecorder) FindCompanyMembers(ctx, cid, page any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindCompanyMembers", reflect.TypeOf((*MockUserRepo)(nil).FindCompanyMembers), ctx, cid, page)
+}
+
+// FindInvitation mocks base method.
+func (m *MockUserRepo) FindInvitation(ctx context.Context, iid uint64) (models.CompanyInvitation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindInvitation", ctx, iid)
+	ret0, _ := ret[0].(models.CompanyInvitation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindInvitation indicates an expected call of FindInvitation.
+func (mr *MockUserRepoMockRecorder) FindInvitation(ctx, iid any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindInvitation", reflect.TypeOf((*MockUserRepo)(nil).FindInvitation), ctx, iid)
+}
+
+// FindMemberCompanies mocks base method.
+func (m *MockUserRepo) FindMemberCompanies(ctx context.Context, uid uint64) ([]uint, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindMemberCompanies", ctx, uid)
+	ret0, _ := ret[0].([]uint)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindMemberCompanies indicates an expected call of FindMemberCompanies.
+func (mr *MockUserRepoMockRecorder) FindMemberCompanies(ctx, uid any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindMemberCompanies", reflect.TypeOf((*MockUserRepo)(nil).FindMemberCompanies), ctx, uid)
+}
+
+// FindPasswordChanges mocks base method.
+func (m *MockUserRepo) FindPasswordChanges(ctx context.Context, since time.Time) ([]models.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindPasswordChanges", ctx, since)
+	ret0, _ := ret[0].([]models.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindPasswordChanges indicates an expected call of FindPasswordChanges.
+func (mr *MockUserRepoMockRecorder) FindPasswordChanges(ctx, since any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindPasswordChanges", reflect.TypeOf((*MockUserRepo)(nil).FindPasswordChanges), ctx, since)
+}
+
+// FindPendingInvitations mocks base method.
+func (m *MockUserRepo) FindPendingInvitations(ctx context.Context, email string) ([]models.CompanyInvitation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindPendingInvitations", ctx, email)
+	ret0, _ := ret[0].([]models.CompanyInvitation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindPendingInvitations indicates an expected call of FindPendingInvitations.
+func (mr *MockUserRepoMockRecorder) FindPendingInvitations(ctx, email any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindPendingInvitations", reflect.TypeOf((*MockUserRepo)(nil).FindPendingInvitations), ctx, email)
+}
+
+// FindRefreshToken mocks base method.
+func (m *MockUserRepo) FindRefreshToken(ctx context.Context, tokenHash string) (models.RefreshToken, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindRefreshToken", ctx, tokenHash)
+	ret0, _ := ret[0].(models.RefreshToken)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindRefreshToken indicates an expected call of FindRefreshToken.
+func (mr *MockUserRepoMockRecorder) FindRefreshToken(ctx, tokenHash any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindRefreshToken", reflect.TypeOf((*MockUserRepo)(nil).FindRefreshToken), ctx, tokenHash)
+}
+
+// FindRevokedSubjects mocks base method.
+func (m *MockUserRepo) FindRevokedSubjects(ctx context.Context) ([]models.RevokedSubject, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindRevokedSubjects", ctx)
+	ret0, _ := ret[0].([]models.RevokedSubject)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindRevokedSubjects indicates an expected call of FindRevokedSubjects.
+func (mr *MockUserRepoMockRecorder) FindRevokedSubjects(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindRevokedSubjects", reflect.TypeOf((*MockUserRepo)(nil).FindRevokedSubjects), ctx)
+}
+
+// FindRevokedTokens mocks base method.
+func (m *MockUserRepo) FindRevokedTokens(ctx context.Context) ([]models.RevokedToken, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindRevokedTokens", ctx)
+	ret0, _ := ret[0].([]models.RevokedToken)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindRevokedTokens indicates an expected call of FindRevokedTokens.
+func (mr *MockUserRepoMockRecorder) FindRevokedTokens(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindRevokedTokens", reflect.TypeOf((*MockUserRepo)(nil).FindRevokedTokens), ctx)
+}
+
+// FindUserById mocks base method.
+func (m *MockUserRepo) FindUserById(ctx context.Context, uid uint64) (models.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindUserById", ctx, uid)
+	ret0, _ := ret[0].(models.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindUserById indicates an expected call of FindUserById.
+func (mr *MockUserRepoMockRecorder) FindUserById(ctx, uid any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindUserById", reflect.TypeOf((*MockUserRepo)(nil).FindUserById), ctx, uid)
+}
+
+// FindUserToken mocks base method.
+func (m *MockUserRepo) FindUserToken(ctx context.Context, purpose, tokenHash string) (models.UserToken, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindUserToken", ctx, purpose, tokenHash)
+	ret0, _ := ret[0].(models.UserToken)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindUserToken indicates an expected call of FindUserToken.
+func (mr *MockUserRepoMockRecorder) FindUserToken(ctx, purpose, tokenHash any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindUserToken", reflect.TypeOf((*MockUserRepo)(nil).FindUserToken), ctx, purpose, tokenHash)
+}
+
+// RestoreCompany mocks base method.
+func (m *MockUserRepo) RestoreCompany(ctx context.Context, cid uint64) (models.Company, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RestoreCompany", ctx, cid)
+	ret0, _ := ret[0].(models.Company)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
+// RestoreCompany indicates an expected call of RestoreCompany.
+func (mr *MockUserRepoMockRecorder) RestoreCompany(ctx, cid any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RestoreCompany", reflect.TypeOf((*MockUserRepo)(nil).RestoreCompany), ctx, cid)
+}
+
+// RevokeAccessToken mocks base method.
+func (m *MockUserRepo) RevokeAccessToken(ctx context.Context, tokenData models.RevokedToken) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeAccessToken", ctx, tokenData)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RevokeAccessToken indicates an expected call of RevokeAccessToken.
+func (mr *MockUserRepoMockRecorder) RevokeAccessToken(ctx, tokenData any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeAccessToken", reflect.TypeOf((*MockUserRepo)(nil).RevokeAccessToken), ctx, tokenData)
+}
+
+// RevokeRefreshTokenFamily mocks base method.
+func (m *MockUserRepo) RevokeRefreshTokenFamily(ctx context.Context, family string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeRefreshTokenFamily", ctx, family)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RevokeRefreshTokenFamily indicates an expected call of RevokeRefreshTokenFamily.
+func (mr *MockUserRepoMockRecorder) RevokeRefreshTokenFamily(ctx, family any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeRefreshTokenFamily", reflect.TypeOf((*MockUserRepo)(nil).RevokeRefreshTokenFamily), ctx, family)
+}
+
+// RevokeUserRefreshTokens mocks base method.
+func (m *MockUserRepo) RevokeUserRefreshTokens(ctx context.Context, uid uint64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeUserRefreshTokens", ctx, uid)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RevokeUserRefreshTokens indicates an expected call of RevokeUserRefreshTokens.
+func (mr *MockUserRepoMockRecorder) RevokeUserRefreshTokens(ctx, uid any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeUserRefreshTokens", reflect.TypeOf((*MockUserRepo)(nil).RevokeUserRefreshTokens), ctx, uid)
+}
+
+// RevokeUserTokens mocks base method.
+func (m *MockUserRepo) RevokeUserTokens(ctx context.Context, uid uint64, purpose string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeUserTokens", ctx, uid, purpose)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RevokeUserTokens indicates an expected call of RevokeUserTokens.
+func (mr *MockUserRepoMockRecorder) RevokeUserTokens(ctx, uid, purpose any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeUserTokens", reflect.TypeOf((*MockUserRepo)(nil).RevokeUserTokens), ctx, uid, purpose)
+}
+
+// RotateRefreshToken mocks base method.
+func (m *MockUserRepo) RotateRefreshToken(ctx context.Context, oldToken, newToken models.RefreshToken) (models.RefreshToken, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RotateRefreshToken", ctx, oldToken, newToken)
+	ret0, _ := ret[0].(models.RefreshToken)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RotateRefreshToken indicates an expected call of RotateRefreshToken.
+func (mr *MockUserRepoMockRecorder) RotateRefreshToken(ctx, oldToken, newToken any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RotateRefreshToken", reflect.TypeOf((*MockUserRepo)(nil).RotateRefreshToken), ctx, oldToken, newToken)
+}
+
+// SearchJobs mocks base method.
+func (m *MockUserRepo) SearchJobs(ctx context.Context, search models.JobSearch, page models.PageQuery) ([]models.JobSearchResult, int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SearchJobs", ctx, search, page)
+	ret0, _ := ret[0].([]models.JobSearchResult)
+	ret1, _ := ret[1].(int64)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// SearchJobs indicates an expected call of SearchJobs.
+func (mr *MockUserRepoMockRecorder) SearchJobs(ctx, search, page any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SearchJobs", reflect.TypeOf((*MockUserRepo)(nil).SearchJobs), ctx, search, page)
+}
+
+// UpdateApplicationStatus mocks base method.
+func (m *MockUserRepo) UpdateApplicationStatus(ctx context.Context, applicationData models.Application, auditData models.ApplicationAudit) (models.Application, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateApplicationStatus", ctx, applicationData, auditData)
+	ret0, _ := ret[0].(models.Application)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateApplicationStatus indicates an expected call of UpdateApplicationStatus.
+func (mr *MockUserRepoMockRecorder) UpdateApplicationStatus(ctx, applicationData, auditData any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateApplicationStatus", reflect.TypeOf((*MockUserRepo)(nil).UpdateApplicationStatus), ctx, applicationData, auditData)
+}
+
+// UpdateCompany mocks base method.
+func (m *MockUserRepo) UpdateCompany(ctx context.Context, cid uint64, companyData models.Company) (models.Company, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateCompany", ctx, cid, companyData)
+	ret0, _ := ret[0].(models.Company)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateCompany indicates an expected call of UpdateCompany.
+func (mr *MockUserRepoMockRecorder) UpdateCompany(ctx, cid, companyData any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateCompany", reflect.TypeOf((*MockUserRepo)(nil).UpdateCompany), ctx, cid, companyData)
+}
+
+// UpdateJob mocks base method.
+func (m *MockUserRepo) UpdateJob(ctx context.Context, jid uint64, jobData models.Jobs) (models.Jobs, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateJob", ctx, jid, jobData)
+	ret0, _ := ret[0].(models.Jobs)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateJob indicates an expected call of UpdateJob.
+func (mr *MockUserRepoMockRecorder) UpdateJob(ctx, jid, jobData any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateJob", reflect.TypeOf((*MockUserRepo)(nil).UpdateJob), ctx, jid, jobData)
+}
+
+// UpdatePasswordHash mocks base method.
+func (m *MockUserRepo) UpdatePasswordHash(ctx context.Context, uid uint64, passwordHash string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdatePasswordHash", ctx, uid, passwordHash)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdatePasswordHash indicates an expected call of UpdatePasswordHash.
+func (mr *MockUserRepoMockRecorder) UpdatePasswordHash(ctx, uid, passwordHash any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdatePasswordHash", reflect.TypeOf((*MockUserRepo)(nil).UpdatePasswordHash), ctx, uid, passwordHash)
+}
+
+// UpdateUserPassword mocks base method.
+func (m *MockUserRepo) UpdateUserPassword(ctx context.Context, uid uint64, passwordHash string, changedAt time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateUserPassword", ctx, uid, passwordHash, changedAt)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateUserPassword indicates an expected call of UpdateUserPassword.
+func (mr *MockUserRepoMockRecorder) UpdateUserPassword(ctx, uid, passwordHash, changedAt any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateUserPassword", reflect.TypeOf((*MockUserRepo)(nil).UpdateUserPassword), ctx, uid, passwordHash, changedAt)
+}
+
+// UpdateUserProfile mocks base method.
+func (m *MockUserRepo) UpdateUserProfile(ctx context.Context, uid uint64, profile models.UpdateProfile) (models.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateUserProfile", ctx, uid, profile)
+	ret0, _ := ret[0].(models.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateUserProfile indicates an expected call of UpdateUserProfile.
+func (mr *MockUserRepoMockRecorder) UpdateUserProfile(ctx, uid, profile any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateUserProfile", reflect.TypeOf((*MockUserRepo)(nil).UpdateUserProfile), ctx, uid, profile)
+}
+
+// UseUserToken mocks base method.
+func (m *MockUserRepo) UseUserToken(ctx context.Context, purpose, tokenHash string) (models.UserToken, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UseUserToken", ctx, purpose, tokenHash)
+	ret0, _ := ret[0].(models.UserToken)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UseUserToken indicates an expected call of UseUserToken.
+func (mr *MockUserRepoMockRecorder) UseUserToken(ctx, purpose, tokenHash any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UseUserToken", reflect.TypeOf((*MockUserRepo)(nil).UseUserToken), ctx, purpose, tokenHash)
+}
+
+// VerifyUserEmail mocks base method.
+func (m *MockUserRepo) VerifyUserEmail(ctx context.Context, uid uint64, email string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "VerifyUserEmail", ctx, uid, email)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// VerifyUserEmail indicates an expected call of VerifyUserEmail.
+func (mr *MockUserRepoMockRecorder) VerifyUserEmail(ctx, uid, email any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "VerifyUserEmail", reflect.TypeOf((*MockUserRepo)(nil).VerifyUserEmail), ctx, uid, email)
+}
+
+// ViewCompanies mocks base method.
+func (m *MockUserRepo) ViewCompanies(ctx context.Context, filter models.CompanyFilter, page models.PageQuery) ([]models.Company, int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ViewCompanies", ctx, filter, page)
+	ret0, _ := ret[0].([]models.Company)
+	ret1, _ := ret[1].(int64)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
 // ViewCompanies indicates an expected call of ViewCompanies.
-func (mr *MockUserRepoMockRecorder) ViewCompanies(ctx any) *gomock.Call {
+func (mr *MockUserRepoMockRecorder) ViewCompanies(ctx, filter, page any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ViewCompanies", reflect.TypeOf((*MockUserRepo)(nil).ViewCompanies), ctx)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ViewCompanies", reflect.TypeOf((*MockUserRepo)(nil).ViewCompanies), ctx, filter, page)
 }
 
 // ViewCompanyById mocks base method.
@@ -173,3 +738,42 @@ func (mr *MockUserRepoMockRecorder) ViewJobDetailsBy(ctx, jid any) *gomock.Call
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ViewJobDetailsBy", reflect.TypeOf((*MockUserRepo)(nil).ViewJobDetailsBy), ctx, jid)
 }
+
+// MockJobSearcher is a mock of JobSearcher interface.
+type MockJobSearcher struct {
+	ctrl     *gomock.Controller
+	recorder *MockJobSearcherMockRecorder
+}
+
+// MockJobSearcherMockRecorder is the mock recorder for MockJobSearcher.
+type MockJobSearcherMockRecorder struct {
+	mock *MockJobSearcher
+}
+
+// NewMockJobSearcher creates a new mock instance.
+func NewMockJobSearcher(ctrl *gomock.Controller) *MockJobSearcher {
+	mock := &MockJobSearcher{ctrl: ctrl}
+	mock.recorder = &MockJobSearcherMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockJobSearcher) EXPECT() *MockJobSearcherMockRecorder {
+	return m.recorder
+}
+
+// SearchJobs mocks base method.
+func (m *MockJobSearcher) SearchJobs(ctx context.Context, search models.JobSearch, page models.PageQuery) ([]models.JobSearchResult, int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SearchJobs", ctx, search, page)
+	ret0, _ := ret[0].([]models.JobSearchResult)
+	ret1, _ := ret[1].(int64)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// SearchJobs indicates an expected call of SearchJobs.
+func (mr *MockJobSearcherMockRecorder) SearchJobs(ctx, search, page any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SearchJobs", reflect.TypeOf((*MockJobSearcher)(nil).SearchJobs), ctx, search, page)
+}
@@ -0,0 +1,97 @@
+package repository
+
+import (
+	"context"
+	"html"
+	"strings"
+
+	"github.com/afthaab/job-portal/internal/models"
+	"github.com/rs/zerolog/log"
+	"gorm.io/gorm"
+)
+
+// the matches are first wrapped in control characters the text can not hold, the text is then html escaped
+// and only the control characters become <mark> tags, so the text recruiters write can not inject markup
+const (
+	markStart = "\x01"
+	markStop  = "\x02"
+)
+
+var (
+	// unmark drops the control characters from the text before the matches are wrapped
+	unmark = strings.NewReplacer(markStart, "", markStop, "")
+	marks  = strings.NewReplacer(markStart, "<mark>", markStop, "</mark>")
+)
+
+// markHighlight turns text with its matches wrapped in markStart and markStop into escaped html
+func markHighlight(text string) string {
+	return marks.Replace(html.EscapeString(text))
+}
+
+const (
+	// short fields are highlighted as a whole, the description is cut down to the fragments around the matches
+	headlineOptions            = "StartSel=" + markStart + ", StopSel=" + markStop + ", HighlightAll=true"
+	descriptionHeadlineOptions = "StartSel=" + markStart + ", StopSel=" + markStop + ", MaxFragments=2, MaxWords=20, MinWords=5"
+)
+
+type jobSearchRow struct {
+	models.Jobs
+	Rank                 float64
+	NameHighlight        string
+	CompanyHighlight     string
+	DescriptionHighlight string
+}
+
+// SearchJobs matches the query against the search vector kept up to date by the triggers set up in the database package,
+// the results are always ordered by relevance
+func (r *Repo) SearchJobs(ctx context.Context, search models.JobSearch, page models.PageQuery) ([]models.JobSearchResult, int64, error) {
+	db, cancel := r.withTimeout(ctx, opSearch)
+	defer cancel()
+	query := db.Model(&models.Jobs{}).
+		Joins("JOIN companies ON companies.id = jobs.cid AND companies.deleted_at IS NULL").
+		Where("jobs.search_vector @@ websearch_to_tsquery('english', ?)", search.Query)
+	if search.OpenOnly {
+		query = query.Scopes(visibleJobs(search.MemberOf))
+	}
+
+	var total int64
+	err := query.Session(&gorm.Session{}).Count(&total).Error
+	if err != nil {
+		log.Info().Err(err).Send()
+		return nil, 0, dbError(err, "could not search the jobs")
+	}
+
+	var rows []jobSearchRow
+	err = query.Select(`jobs.*,
+		ts_rank(jobs.search_vector, websearch_to_tsquery('english', ?)) AS rank,
+		ts_headline('english', translate(jobs.name, chr(1) || chr(2), ''), websearch_to_tsquery('english', ?), ?) AS name_highlight,
+		ts_headline('english', translate(companies.name, chr(1) || chr(2), ''), websearch_to_tsquery('english', ?), ?) AS company_highlight,
+		ts_headline('english', translate(jobs.description, chr(1) || chr(2), ''), websearch_to_tsquery('english', ?), ?) AS description_highlight`,
+		search.Query,
+		search.Query, headlineOptions,
+		search.Query, headlineOptions,
+		search.Query, descriptionHeadlineOptions,
+	).
+		Order("rank DESC, jobs.id").
+		Limit(page.Limit).
+		Offset(page.Offset).
+		Find(&rows).Error
+	if err != nil {
+		log.Info().Err(err).Send()
+		return nil, 0, dbError(err, "could not search the jobs")
+	}
+
+	results := make([]models.JobSearchResult, 0, len(rows))
+	for _, row := range rows {
+		results = append(results, models.JobSearchResult{
+			Job:  row.Jobs,
+			Rank: row.Rank,
+			Highlights: models.JobHighlights{
+				Name:        markHighlight(row.NameHighlight),
+				Company:     markHighlight(row.CompanyHighlight),
+				Description: markHighlight(row.DescriptionHighlight),
+			},
+		})
+	}
+	return results, total, nil
+}
@@ -0,0 +1,12 @@
+package repository
+
+import "testing"
+
+func Test_markHighlight(t *testing.T) {
+	// what ts_headline returns for a job named <script>alert(1)</script> developer searched for developer
+	got := markHighlight("<script>alert(1)</script> " + markStart + "developer" + markStop)
+	want := "&lt;script&gt;alert(1)&lt;/script&gt; <mark>developer</mark>"
+	if got != want {
+		t.Errorf("markHighlight() = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,213 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/afthaab/job-portal/internal/apperr"
+	"github.com/afthaab/job-portal/internal/models"
+	"github.com/rs/zerolog/log"
+	"gorm.io/gorm"
+)
+
+// ErrRefreshTokenUsed is returned when a refresh token that was already rotated or revoked is presented again
+var ErrRefreshTokenUsed = apperr.Unauthorized("refresh token has already been used")
+
+func (r *Repo) CreateRefreshToken(ctx context.Context, tokenData models.RefreshToken) (models.RefreshToken, error) {
+	db, cancel := r.withTimeout(ctx, opWrite)
+	defer cancel()
+	result := db.Create(&tokenData)
+	if result.Error != nil {
+		log.Info().Err(result.Error).Send()
+		return models.RefreshToken{}, dbError(result.Error, "could not create the refresh token")
+	}
+	return tokenData, nil
+}
+
+func (r *Repo) FindRefreshToken(ctx context.Context, tokenHash string) (models.RefreshToken, error) {
+	db, cancel := r.withTimeout(ctx, opRead)
+	defer cancel()
+	var tokenData models.RefreshToken
+	result := db.Where("token_hash = ?", tokenHash).First(&tokenData)
+	if result.Error != nil {
+		log.Info().Err(result.Error).Send()
+		return models.RefreshToken{}, dbError(result.Error, "could not find the refresh token")
+	}
+	return tokenData, nil
+}
+
+// RotateRefreshToken revokes the old token and stores the new one in the same transaction,
+// the old token is only revoked if it was still live so two requests racing with the same token cannot both win
+func (r *Repo) RotateRefreshToken(ctx context.Context, oldToken models.RefreshToken, newToken models.RefreshToken) (models.RefreshToken, error) {
+	db, cancel := r.withTimeout(ctx, opWrite)
+	defer cancel()
+	err := db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Create(&newToken).Error
+		if err != nil {
+			return err
+		}
+		result := tx.Model(&models.RefreshToken{}).
+			Where("id = ? AND revoked_at IS NULL", oldToken.ID).
+			Updates(map[string]interface{}{"revoked_at": time.Now(), "replaced_by": newToken.ID})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrRefreshTokenUsed
+		}
+		return nil
+	})
+	if err != nil {
+		log.Info().Err(err).Send()
+		return models.RefreshToken{}, dbError(err, "could not rotate the refresh token")
+	}
+	return newToken, nil
+}
+
+func (r *Repo) RevokeRefreshTokenFamily(ctx context.Context, family string) error {
+	db, cancel := r.withTimeout(ctx, opWrite)
+	defer cancel()
+	result := db.Model(&models.RefreshToken{}).
+		Where("family = ? AND revoked_at IS NULL", family).
+		Update("revoked_at", time.Now())
+	if result.Error != nil {
+		log.Info().Err(result.Error).Send()
+		return dbError(result.Error, "could not revoke the refresh tokens")
+	}
+	return nil
+}
+
+func (r *Repo) RevokeUserRefreshTokens(ctx context.Context, uid uint64) error {
+	db, cancel := r.withTimeout(ctx, opWrite)
+	defer cancel()
+	result := db.Model(&models.RefreshToken{}).
+		Where("uid = ? AND revoked_at IS NULL", uid).
+		Update("revoked_at", time.Now())
+	if result.Error != nil {
+		log.Info().Err(result.Error).Send()
+		return dbError(result.Error, "could not revoke the refresh tokens")
+	}
+	return nil
+}
+
+func (r *Repo) RevokeAccessToken(ctx context.Context, tokenData models.RevokedToken) error {
+	db, cancel := r.withTimeout(ctx, opWrite)
+	defer cancel()
+	result := db.Create(&tokenData)
+	if result.Error != nil {
+		log.Info().Err(result.Error).Send()
+		return dbError(result.Error, "could not revoke the access token")
+	}
+	return nil
+}
+
+// FindRevokedTokens returns the revoked access tokens that have not expired yet and clears out the rest
+func (r *Repo) FindRevokedTokens(ctx context.Context) ([]models.RevokedToken, error) {
+	db, cancel := r.withTimeout(ctx, opWrite)
+	defer cancel()
+	now := time.Now()
+	result := db.Where("expires_at <= ?", now).Delete(&models.RevokedToken{})
+	if result.Error != nil {
+		log.Info().Err(result.Error).Send()
+		return nil, dbError(result.Error, "could not clear the expired revoked tokens")
+	}
+
+	var tokenDatas []models.RevokedToken
+	result = db.Where("expires_at > ?", now).Find(&tokenDatas)
+	if result.Error != nil {
+		log.Info().Err(result.Error).Send()
+		return nil, dbError(result.Error, "could not find the revoked tokens")
+	}
+	return tokenDatas, nil
+}
+
+// FindRevokedSubjects returns the subjects with access tokens that are revoked and not expired yet, and clears out the rest
+func (r *Repo) FindRevokedSubjects(ctx context.Context) ([]models.RevokedSubject, error) {
+	db, cancel := r.withTimeout(ctx, opWrite)
+	defer cancel()
+	now := time.Now()
+	result := db.Where("expires_at <= ?", now).Delete(&models.RevokedSubject{})
+	if result.Error != nil {
+		log.Info().Err(result.Error).Send()
+		return nil, dbError(result.Error, "could not clear the expired revoked subjects")
+	}
+
+	var subjectDatas []models.RevokedSubject
+	result = db.Where("expires_at > ?", now).Find(&subjectDatas)
+	if result.Error != nil {
+		log.Info().Err(result.Error).Send()
+		return nil, dbError(result.Error, "could not find the revoked subjects")
+	}
+	return subjectDatas, nil
+}
+
+// ErrUserTokenUsed is returned when a single use token is presented a second time
+var ErrUserTokenUsed = apperr.Conflict("token has already been used")
+
+func (r *Repo) CreateUserToken(ctx context.Context, tokenData models.UserToken) (models.UserToken, error) {
+	db, cancel := r.withTimeout(ctx, opWrite)
+	defer cancel()
+	result := db.Create(&tokenData)
+	if result.Error != nil {
+		log.Info().Err(result.Error).Send()
+		return models.UserToken{}, dbError(result.Error, "could not create the token")
+	}
+	return tokenData, nil
+}
+
+func (r *Repo) FindUserToken(ctx context.Context, purpose string, tokenHash string) (models.UserToken, error) {
+	db, cancel := r.withTimeout(ctx, opRead)
+	defer cancel()
+	var tokenData models.UserToken
+	result := db.Where("token_hash = ? AND purpose = ?", tokenHash, purpose).First(&tokenData)
+	if result.Error != nil {
+		log.Info().Err(result.Error).Send()
+		return models.UserToken{}, dbError(result.Error, "could not find the token")
+	}
+	return tokenData, nil
+}
+
+// UseUserToken marks the token used and returns it, the update only matches a token that was not used yet
+// so two requests racing with the same token cannot both win. Expiry is left to the caller
+func (r *Repo) UseUserToken(ctx context.Context, purpose string, tokenHash string) (models.UserToken, error) {
+	db, cancel := r.withTimeout(ctx, opWrite)
+	defer cancel()
+	var tokenData models.UserToken
+	err := db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Where("token_hash = ? AND purpose = ?", tokenHash, purpose).First(&tokenData).Error
+		if err != nil {
+			return err
+		}
+		now := time.Now()
+		result := tx.Model(&models.UserToken{}).
+			Where("id = ? AND used_at IS NULL", tokenData.ID).
+			Update("used_at", now)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrUserTokenUsed
+		}
+		tokenData.UsedAt = &now
+		return nil
+	})
+	if err != nil {
+		log.Info().Err(err).Send()
+		return models.UserToken{}, dbError(err, "could not find the token")
+	}
+	return tokenData, nil
+}
+
+// RevokeUserTokens uses up the live tokens of a user for one purpose, a new token replaces the ones sent before
+func (r *Repo) RevokeUserTokens(ctx context.Context, uid uint64, purpose string) error {
+	db, cancel := r.withTimeout(ctx, opWrite)
+	defer cancel()
+	result := db.Model(&models.UserToken{}).
+		Where("uid = ? AND purpose = ? AND used_at IS NULL", uid, purpose).
+		Update("used_at", time.Now())
+	if result.Error != nil {
+		log.Info().Err(result.Error).Send()
+		return dbError(result.Error, "could not revoke the tokens")
+	}
+	return nil
+}
@@ -2,28 +2,215 @@ package repository
 
 import (
 	"context"
-	"errors"
+	"strings"
+	"time"
 
+	"github.com/afthaab/job-portal/internal/apperr"
 	"github.com/afthaab/job-portal/internal/models"
 	"github.com/rs/zerolog/log"
+	"gorm.io/gorm"
 )
 
 func (r *Repo) CreateUser(ctx context.Context, UserDetails models.User) (models.User, error) {
-	result := r.db.Create(&UserDetails)
+	db, cancel := r.withTimeout(ctx, opWrite)
+	defer cancel()
+	result := db.Create(&UserDetails)
 	if result.Error != nil {
 		log.Info().Err(result.Error).Send()
-		return models.User{}, errors.New("could not create the user")
+		return models.User{}, dbError(result.Error, "could not create the user")
 	}
 	return UserDetails, nil
 }
 
 func (r *Repo) CheckEmail(ctx context.Context, email string) (models.User, error) {
+	db, cancel := r.withTimeout(ctx, opRead)
+	defer cancel()
 	var userDetails models.User
-	result := r.db.Where("email = ?", email).First(&userDetails)
+	result := db.Where("email = ?", email).First(&userDetails)
 	if result.Error != nil {
 		log.Info().Err(result.Error).Send()
-		return models.User{}, errors.New("email not found")
+		return models.User{}, dbError(result.Error, "email not found")
 	}
 	return userDetails, nil
 
 }
+
+func (r *Repo) FindUserById(ctx context.Context, uid uint64) (models.User, error) {
+	db, cancel := r.withTimeout(ctx, opRead)
+	defer cancel()
+	var userDetails models.User
+	result := db.Where("id = ?", uid).First(&userDetails)
+	if result.Error != nil {
+		log.Info().Err(result.Error).Send()
+		return models.User{}, dbError(result.Error, "could not find the user")
+	}
+	return userDetails, nil
+}
+
+// VerifyUserEmail marks the address of the user verified, only while it is still the address the token was sent to.
+// Verifying again keeps the time of the first verification
+func (r *Repo) VerifyUserEmail(ctx context.Context, uid uint64, email string) error {
+	db, cancel := r.withTimeout(ctx, opWrite)
+	defer cancel()
+	result := db.Model(&models.User{}).
+		Where("id = ? AND email = ?", uid, email).
+		Update("email_verified_at", gorm.Expr("COALESCE(email_verified_at, ?)", time.Now()))
+	if result.Error != nil {
+		log.Info().Err(result.Error).Send()
+		return dbError(result.Error, "could not verify the email")
+	}
+	if result.RowsAffected == 0 {
+		return apperr.NotFound("user with this email not found")
+	}
+	return nil
+}
+
+// UpdateUserPassword stores the new password hash and when it changed
+func (r *Repo) UpdateUserPassword(ctx context.Context, uid uint64, passwordHash string, changedAt time.Time) error {
+	db, cancel := r.withTimeout(ctx, opWrite)
+	defer cancel()
+	result := db.Model(&models.User{}).
+		Where("id = ?", uid).
+		Updates(map[string]interface{}{"password_hash": passwordHash, "password_changed_at": changedAt})
+	if result.Error != nil {
+		log.Info().Err(result.Error).Send()
+		return dbError(result.Error, "could not update the password")
+	}
+	if result.RowsAffected == 0 {
+		return apperr.NotFound("user not found")
+	}
+	return nil
+}
+
+// FindPasswordChanges returns the users who changed their password after since
+func (r *Repo) FindPasswordChanges(ctx context.Context, since time.Time) ([]models.User, error) {
+	db, cancel := r.withTimeout(ctx, opRead)
+	defer cancel()
+	var userDetails []models.User
+	result := db.Where("password_changed_at > ?", since).Find(&userDetails)
+	if result.Error != nil {
+		log.Info().Err(result.Error).Send()
+		return nil, dbError(result.Error, "could not find the password changes")
+	}
+	return userDetails, nil
+}
+
+// UpdatePasswordHash replaces the hash of an unchanged password, the sessions of the user stay valid
+func (r *Repo) UpdatePasswordHash(ctx context.Context, uid uint64, passwordHash string) error {
+	db, cancel := r.withTimeout(ctx, opWrite)
+	defer cancel()
+	result := db.Model(&models.User{}).Where("id = ?", uid).Update("password_hash", passwordHash)
+	if result.Error != nil {
+		log.Info().Err(result.Error).Send()
+		return dbError(result.Error, "could not update the password hash")
+	}
+	return nil
+}
+
+// UpdateUserProfile sets the profile fields that are not nil and returns the updated user
+func (r *Repo) UpdateUserProfile(ctx context.Context, uid uint64, profile models.UpdateProfile) (models.User, error) {
+	columns := map[string]interface{}{}
+	if profile.Username != nil {
+		columns["username"] = *profile.Username
+	}
+	if profile.FullName != nil {
+		columns["full_name"] = *profile.FullName
+	}
+	if profile.Headline != nil {
+		columns["headline"] = *profile.Headline
+	}
+	if profile.Location != nil {
+		columns["location"] = *profile.Location
+	}
+
+	db, cancel := r.withTimeout(ctx, opWrite)
+	defer cancel()
+	result := db.Model(&models.User{}).Where("id = ?", uid).Updates(columns)
+	if result.Error != nil {
+		log.Info().Err(result.Error).Send()
+		return models.User{}, dbError(result.Error, "could not update the profile")
+	}
+	if result.RowsAffected == 0 {
+		return models.User{}, apperr.NotFound("user not found")
+	}
+	return r.FindUserById(ctx, uid)
+}
+
+// ChangeUserEmail moves the user to the new address, which the user just proved to own
+func (r *Repo) ChangeUserEmail(ctx context.Context, uid uint64, email string) error {
+	db, cancel := r.withTimeout(ctx, opWrite)
+	defer cancel()
+	result := db.Model(&models.User{}).
+		Where("id = ?", uid).
+		Updates(map[string]interface{}{"email": email, "email_verified_at": time.Now()})
+	if result.Error != nil {
+		log.Info().Err(result.Error).Send()
+		return dbError(result.Error, "could not change the email")
+	}
+	if result.RowsAffected == 0 {
+		return apperr.NotFound("user not found")
+	}
+	return nil
+}
+
+// errSoleOwner keeps an account from being erased while a company would be left without an owner
+var errSoleOwner = apperr.Conflict("the account is the only owner of a company, hand the company over or delete it first")
+
+// EraseUser deletes the user for good along with everything that is about the user: the applications
+// and their history, the memberships, the invitations sent to the email and every token.
+// Nothing is soft deleted so no personal data is left behind, the audits of the applications of
+// other users keep only the id of the user who changed them. The revocation of the access tokens of the user
+// is stored in the same transaction so they can not outlive the account
+func (r *Repo) EraseUser(ctx context.Context, uid uint64, revoked models.RevokedSubject) error {
+	db, cancel := r.withTimeout(ctx, opWrite)
+	defer cancel()
+	err := db.Transaction(func(tx *gorm.DB) error {
+		var userDetails models.User
+		err := tx.Where("id = ?", uid).First(&userDetails).Error
+		if err != nil {
+			return err
+		}
+
+		var soleOwned []uint
+		err = tx.Model(&models.CompanyMember{}).
+			Select("cid").
+			Where("role = ? AND cid IN (?)", models.MemberOwner,
+				tx.Model(&models.CompanyMember{}).Select("cid").Where("uid = ? AND role = ?", uid, models.MemberOwner)).
+			Group("cid").
+			Having("COUNT(*) = 1").
+			Scan(&soleOwned).Error
+		if err != nil {
+			return err
+		}
+		if len(soleOwned) > 0 {
+			return errSoleOwner
+		}
+
+		applications := tx.Unscoped().Model(&models.Application{}).Select("id").Where("uid = ?", uid)
+		err = tx.Unscoped().Where("aid IN (?)", applications).Delete(&models.ApplicationAudit{}).Error
+		if err != nil {
+			return err
+		}
+		for _, model := range []interface{}{&models.Application{}, &models.CompanyMember{}, &models.RefreshToken{}, &models.UserToken{}} {
+			err = tx.Unscoped().Where("uid = ?", uid).Delete(model).Error
+			if err != nil {
+				return err
+			}
+		}
+		// invitations are stored with the email lowercased
+		err = tx.Unscoped().Where("email = ?", strings.ToLower(userDetails.Email)).Delete(&models.CompanyInvitation{}).Error
+		if err != nil {
+			return err
+		}
+		err = tx.Create(&revoked).Error
+		if err != nil {
+			return err
+		}
+		return tx.Unscoped().Delete(&userDetails).Error
+	})
+	if err != nil {
+		log.Info().Err(err).Send()
+		return dbError(err, "could not delete the account")
+	}
+	return nil
+}
@@ -0,0 +1,135 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/afthaab/job-portal/internal/apperr"
+	"github.com/afthaab/job-portal/internal/auth"
+	"github.com/afthaab/job-portal/internal/models"
+)
+
+// applicationTransitions lists the statuses an application can move to from its current status,
+// rejected and withdrawn are final
+var applicationTransitions = map[string][]string{
+	models.ApplicationSubmitted: {models.ApplicationScreening, models.ApplicationRejected, models.ApplicationWithdrawn},
+	models.ApplicationScreening: {models.ApplicationInterview, models.ApplicationRejected, models.ApplicationWithdrawn},
+	models.ApplicationInterview: {models.ApplicationOffer, models.ApplicationRejected, models.ApplicationWithdrawn},
+	models.ApplicationOffer:     {models.ApplicationRejected, models.ApplicationWithdrawn},
+}
+
+func canMoveTo(from string, to string) bool {
+	for _, status := range applicationTransitions[from] {
+		if status == to {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *Service) ApplyForJob(ctx context.Context, uid uint64, jid uint64, applicationData models.NewApplication) (models.ApplicationResponse, error) {
+	jobData, err := s.UserRepo.ViewJobDetailsBy(ctx, jid)
+	if err != nil {
+		return models.ApplicationResponse{}, err
+	}
+	if jobData.ID == 0 {
+		return models.ApplicationResponse{}, apperr.NotFound("job not found")
+	}
+	if jobData.Status != models.JobOpen {
+		return models.ApplicationResponse{}, apperr.Conflict("job is not open for applications")
+	}
+
+	application := models.Application{
+		Uid:         uint(uid),
+		Jid:         jobData.ID,
+		CoverLetter: applicationData.CoverLetter,
+		Status:      models.ApplicationSubmitted,
+	}
+	application, err = s.UserRepo.CreateApplication(ctx, application)
+	if err != nil {
+		return models.ApplicationResponse{}, err
+	}
+	return newApplicationResponse(application), nil
+}
+
+func (s *Service) WithdrawApplication(ctx context.Context, uid uint64, aid uint64) (models.ApplicationResponse, error) {
+	application, err := s.UserRepo.FindApplication(ctx, aid)
+	if err != nil {
+		return models.ApplicationResponse{}, err
+	}
+
+	// only the candidate who applied can withdraw the application
+	if uint64(application.Uid) != uid {
+		return models.ApplicationResponse{}, apperr.NotFound("could not find the application")
+	}
+
+	application, err = s.changeApplicationStatus(ctx, uid, application, models.ApplicationWithdrawn)
+	if err != nil {
+		return models.ApplicationResponse{}, err
+	}
+	return newApplicationResponse(application), nil
+}
+
+func (s *Service) ViewMyApplications(ctx context.Context, uid uint64, page models.PageQuery) (models.Page[models.ApplicationResponse], error) {
+	applicationDatas, total, err := s.UserRepo.FindApplicationsByUser(ctx, uid, page)
+	if err != nil {
+		return models.Page[models.ApplicationResponse]{}, err
+	}
+	return newPage(mapItems(applicationDatas, newApplicationResponse), total, page), nil
+}
+
+func (s *Service) ViewJobApplicants(ctx context.Context, claims auth.Claims, jid uint64, page models.PageQuery) (models.Page[models.ApplicationResponse], error) {
+	_, err := s.findManagedJob(ctx, claims, jid)
+	if err != nil {
+		return models.Page[models.ApplicationResponse]{}, err
+	}
+	applicationDatas, total, err := s.UserRepo.FindApplicationsByJob(ctx, jid, page)
+	if err != nil {
+		return models.Page[models.ApplicationResponse]{}, err
+	}
+	return newPage(mapItems(applicationDatas, newApplicationResponse), total, page), nil
+}
+
+func (s *Service) UpdateApplicationStatus(ctx context.Context, claims auth.Claims, aid uint64, status string) (models.ApplicationResponse, error) {
+	uid, err := claimsUserID(claims)
+	if err != nil {
+		return models.ApplicationResponse{}, err
+	}
+	application, err := s.UserRepo.FindApplication(ctx, aid)
+	if err != nil {
+		return models.ApplicationResponse{}, err
+	}
+	_, err = s.findManagedJob(ctx, claims, uint64(application.Jid))
+	if err != nil {
+		return models.ApplicationResponse{}, err
+	}
+
+	// withdrawing is reserved for the candidate
+	if status == models.ApplicationWithdrawn {
+		return models.ApplicationResponse{}, apperr.Forbidden("only the candidate can withdraw the application")
+	}
+
+	application, err = s.changeApplicationStatus(ctx, uid, application, status)
+	if err != nil {
+		return models.ApplicationResponse{}, err
+	}
+	return newApplicationResponse(application), nil
+}
+
+func (s *Service) changeApplicationStatus(ctx context.Context, uid uint64, application models.Application, status string) (models.Application, error) {
+	if !canMoveTo(application.Status, status) {
+		return models.Application{}, apperr.Conflict(fmt.Sprintf("application cannot move from %s to %s", application.Status, status))
+	}
+
+	auditData := models.ApplicationAudit{
+		Aid:        application.ID,
+		FromStatus: application.Status,
+		ToStatus:   status,
+		ChangedBy:  uint(uid),
+	}
+	application, err := s.UserRepo.UpdateApplicationStatus(ctx, application, auditData)
+	if err != nil {
+		return models.Application{}, err
+	}
+	return application, nil
+}
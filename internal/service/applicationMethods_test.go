@@ -0,0 +1,209 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/afthaab/job-portal/internal/auth"
+	"github.com/afthaab/job-portal/internal/models"
+	"github.com/afthaab/job-portal/internal/repository"
+	"go.uber.org/mock/gomock"
+	"gorm.io/gorm"
+)
+
+func TestService_ApplyForJob(t *testing.T) {
+	type args struct {
+		ctx             context.Context
+		uid             uint64
+		jid             uint64
+		applicationData models.NewApplication
+	}
+	tests := []struct {
+		name               string
+		args               args
+		want               models.ApplicationResponse
+		wantErr            bool
+		mockJobResponse    func() (models.Jobs, error)
+		mockCreateResponse func() (models.Application, error)
+		expectCreateCalled bool
+	}{
+		{
+			name: "job not found",
+			args: args{
+				ctx:             context.Background(),
+				uid:             1,
+				jid:             2,
+				applicationData: models.NewApplication{CoverLetter: "hire me"},
+			},
+			want:    models.ApplicationResponse{},
+			wantErr: true,
+			mockJobResponse: func() (models.Jobs, error) {
+				return models.Jobs{}, nil
+			},
+		},
+		{
+			name: "error from the database",
+			args: args{
+				ctx:             context.Background(),
+				uid:             1,
+				jid:             2,
+				applicationData: models.NewApplication{CoverLetter: "hire me"},
+			},
+			want:    models.ApplicationResponse{},
+			wantErr: true,
+			mockJobResponse: func() (models.Jobs, error) {
+				return models.Jobs{Model: gorm.Model{ID: 2}, Status: models.JobOpen}, nil
+			},
+			mockCreateResponse: func() (models.Application, error) {
+				return models.Application{}, errors.New("could not create the application")
+			},
+			expectCreateCalled: true,
+		},
+		{
+			name: "success",
+			args: args{
+				ctx:             context.Background(),
+				uid:             1,
+				jid:             2,
+				applicationData: models.NewApplication{CoverLetter: "hire me"},
+			},
+			want: models.ApplicationResponse{
+				Uid:         1,
+				Jid:         2,
+				CoverLetter: "hire me",
+				Status:      models.ApplicationSubmitted,
+			},
+			wantErr: false,
+			mockJobResponse: func() (models.Jobs, error) {
+				return models.Jobs{Model: gorm.Model{ID: 2}, Status: models.JobOpen}, nil
+			},
+			mockCreateResponse: func() (models.Application, error) {
+				return models.Application{
+					Uid:         1,
+					Jid:         2,
+					CoverLetter: "hire me",
+					Status:      models.ApplicationSubmitted,
+				}, nil
+			},
+			expectCreateCalled: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mc := gomock.NewController(t)
+			mockRepo := repository.NewMockUserRepo(mc)
+			mockRepo.EXPECT().ViewJobDetailsBy(tt.args.ctx, tt.args.jid).Return(tt.mockJobResponse()).AnyTimes()
+			if tt.expectCreateCalled {
+				mockRepo.EXPECT().CreateApplication(tt.args.ctx, models.Application{
+					Uid:         uint(tt.args.uid),
+					Jid:         uint(tt.args.jid),
+					CoverLetter: tt.args.applicationData.CoverLetter,
+					Status:      models.ApplicationSubmitted,
+				}).Return(tt.mockCreateResponse()).Times(1)
+			}
+
+			svc, err := NewService(mockRepo, &auth.Auth{})
+			if err != nil {
+				t.Errorf("error is initializing the repo layer")
+				return
+			}
+			got, err := svc.ApplyForJob(tt.args.ctx, tt.args.uid, tt.args.jid, tt.args.applicationData)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Service.ApplyForJob() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Service.ApplyForJob() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestService_WithdrawApplication(t *testing.T) {
+	type args struct {
+		ctx context.Context
+		uid uint64
+		aid uint64
+	}
+	tests := []struct {
+		name               string
+		args               args
+		want               models.ApplicationResponse
+		wantErr            bool
+		mockFindResponse   func() (models.Application, error)
+		expectUpdateCalled bool
+	}{
+		{
+			name: "application of another candidate",
+			args: args{
+				ctx: context.Background(),
+				uid: 1,
+				aid: 5,
+			},
+			want:    models.ApplicationResponse{},
+			wantErr: true,
+			mockFindResponse: func() (models.Application, error) {
+				return models.Application{Model: gorm.Model{ID: 5}, Uid: 2, Status: models.ApplicationSubmitted}, nil
+			},
+		},
+		{
+			name: "application already rejected",
+			args: args{
+				ctx: context.Background(),
+				uid: 1,
+				aid: 5,
+			},
+			want:    models.ApplicationResponse{},
+			wantErr: true,
+			mockFindResponse: func() (models.Application, error) {
+				return models.Application{Model: gorm.Model{ID: 5}, Uid: 1, Status: models.ApplicationRejected}, nil
+			},
+		},
+		{
+			name: "success",
+			args: args{
+				ctx: context.Background(),
+				uid: 1,
+				aid: 5,
+			},
+			want:    models.ApplicationResponse{ID: 5, Uid: 1, Status: models.ApplicationWithdrawn},
+			wantErr: false,
+			mockFindResponse: func() (models.Application, error) {
+				return models.Application{Model: gorm.Model{ID: 5}, Uid: 1, Status: models.ApplicationInterview}, nil
+			},
+			expectUpdateCalled: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mc := gomock.NewController(t)
+			mockRepo := repository.NewMockUserRepo(mc)
+			mockRepo.EXPECT().FindApplication(tt.args.ctx, tt.args.aid).Return(tt.mockFindResponse()).AnyTimes()
+			if tt.expectUpdateCalled {
+				application, _ := tt.mockFindResponse()
+				mockRepo.EXPECT().UpdateApplicationStatus(tt.args.ctx, application, models.ApplicationAudit{
+					Aid:        application.ID,
+					FromStatus: application.Status,
+					ToStatus:   models.ApplicationWithdrawn,
+					ChangedBy:  uint(tt.args.uid),
+				}).Return(models.Application{Model: gorm.Model{ID: tt.want.ID}, Uid: tt.want.Uid, Status: tt.want.Status}, nil).Times(1)
+			}
+
+			svc, err := NewService(mockRepo, &auth.Auth{})
+			if err != nil {
+				t.Errorf("error is initializing the repo layer")
+				return
+			}
+			got, err := svc.WithdrawApplication(tt.args.ctx, tt.args.uid, tt.args.aid)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Service.WithdrawApplication() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Service.WithdrawApplication() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -3,29 +3,86 @@ package service
 import (
 	"context"
 
+	"github.com/afthaab/job-portal/internal/apperr"
+	"github.com/afthaab/job-portal/internal/auth"
 	"github.com/afthaab/job-portal/internal/models"
 )
 
-func (s *Service) AddCompanyDetails(ctx context.Context, companyData models.Company) (models.Company, error) {
-	companyData, err := s.UserRepo.CreateCompany(ctx, companyData)
+// AddCompanyDetails creates the company with the signed in user as its owner
+func (s *Service) AddCompanyDetails(ctx context.Context, claims auth.Claims, companyData models.NewCompany) (models.CompanyResponse, error) {
+	uid, err := claimsUserID(claims)
 	if err != nil {
-		return models.Company{}, err
+		return models.CompanyResponse{}, err
 	}
-	return companyData, nil
+	company := newCompany(companyData)
+	company.Members = []models.CompanyMember{{Uid: uint(uid), Role: models.MemberOwner}}
+
+	company, err = s.UserRepo.CreateCompany(ctx, company)
+	if err != nil {
+		return models.CompanyResponse{}, err
+	}
+	return newCompanyResponse(company), nil
 }
 
-func (s *Service) ViewAllCompanies(ctx context.Context) ([]models.Company, error) {
-	companyDetails, err := s.UserRepo.ViewCompanies(ctx)
+func (s *Service) ViewAllCompanies(ctx context.Context, filter models.CompanyFilter, page models.PageQuery) (models.Page[models.CompanyResponse], error) {
+	companyDetails, total, err := s.UserRepo.ViewCompanies(ctx, filter, page)
 	if err != nil {
-		return nil, err
+		return models.Page[models.CompanyResponse]{}, err
 	}
-	return companyDetails, nil
+	return newPage(mapItems(companyDetails, newCompanyResponse), total, page), nil
 }
 
-func (s *Service) ViewCompanyDetails(ctx context.Context, cid uint64) (models.Company, error) {
+func (s *Service) ViewCompanyDetails(ctx context.Context, cid uint64) (models.CompanyResponse, error) {
 	companyData, err := s.UserRepo.ViewCompanyById(ctx, cid)
 	if err != nil {
-		return models.Company{}, err
+		return models.CompanyResponse{}, err
+	}
+	return newCompanyResponse(companyData), nil
+}
+
+func (s *Service) UpdateCompanyDetails(ctx context.Context, claims auth.Claims, cid uint64, companyData models.NewCompany) (models.CompanyResponse, error) {
+	err := s.requireMember(ctx, claims, cid, models.MemberOwner)
+	if err != nil {
+		return models.CompanyResponse{}, err
+	}
+	company, err := s.UserRepo.UpdateCompany(ctx, cid, newCompany(companyData))
+	if err != nil {
+		return models.CompanyResponse{}, err
+	}
+	return newCompanyResponse(company), nil
+}
+
+func (s *Service) PatchCompanyDetails(ctx context.Context, claims auth.Claims, cid uint64, companyData models.UpdateCompany) (models.CompanyResponse, error) {
+	if companyData == (models.UpdateCompany{}) {
+		return models.CompanyResponse{}, apperr.Validation("nothing to update")
+	}
+	err := s.requireMember(ctx, claims, cid, models.MemberOwner)
+	if err != nil {
+		return models.CompanyResponse{}, err
+	}
+	updatedData, err := s.UserRepo.UpdateCompany(ctx, cid, models.Company{
+		Name:     companyData.Name,
+		Location: companyData.Location,
+		Field:    companyData.Field,
+	})
+	if err != nil {
+		return models.CompanyResponse{}, err
+	}
+	return newCompanyResponse(updatedData), nil
+}
+
+func (s *Service) DeleteCompany(ctx context.Context, claims auth.Claims, cid uint64) error {
+	err := s.requireMember(ctx, claims, cid, models.MemberOwner)
+	if err != nil {
+		return err
+	}
+	return s.UserRepo.DeleteCompany(ctx, cid)
+}
+
+func (s *Service) RestoreCompany(ctx context.Context, cid uint64) (models.CompanyResponse, error) {
+	companyData, err := s.UserRepo.RestoreCompany(ctx, cid)
+	if err != nil {
+		return models.CompanyResponse{}, err
 	}
-	return companyData, nil
+	return newCompanyResponse(companyData), nil
 }
@@ -8,19 +8,22 @@ import (
 
 	"github.com/afthaab/job-portal/internal/auth"
 	"github.com/afthaab/job-portal/internal/models"
+	"github.com/afthaab/job-portal/internal/pkg"
 	"github.com/afthaab/job-portal/internal/repository"
+	"github.com/golang-jwt/jwt/v5"
 	"go.uber.org/mock/gomock"
+	"gorm.io/gorm"
 )
 
 func TestService_AddCompanyDetails(t *testing.T) {
 	type args struct {
 		ctx         context.Context
-		companyData models.Company
+		companyData models.NewCompany
 	}
 	tests := []struct {
 		name         string
 		args         args
-		want         models.Company
+		want         models.CompanyResponse
 		wantErr      bool
 		mockResponse func() (models.Company, error)
 	}{
@@ -28,13 +31,13 @@ func TestService_AddCompanyDetails(t *testing.T) {
 			name: "error in the database",
 			args: args{
 				ctx: context.Background(),
-				companyData: models.Company{
+				companyData: models.NewCompany{
 					Name:     "Infosys",
 					Location: "Bangalore",
 					Field:    "IT",
 				},
 			},
-			want:    models.Company{},
+			want:    models.CompanyResponse{},
 			wantErr: true,
 			mockResponse: func() (models.Company, error) {
 				return models.Company{}, errors.New("could not add the data to the database")
@@ -44,13 +47,13 @@ func TestService_AddCompanyDetails(t *testing.T) {
 			name: "success from database",
 			args: args{
 				ctx: context.Background(),
-				companyData: models.Company{
+				companyData: models.NewCompany{
 					Name:     "Infosys",
 					Location: "Bangalore",
 					Field:    "IT",
 				},
 			},
-			want: models.Company{
+			want: models.CompanyResponse{
 				Name:     "Infosys",
 				Location: "Bangalore",
 				Field:    "IT",
@@ -69,7 +72,14 @@ func TestService_AddCompanyDetails(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			mc := gomock.NewController(t)
 			mockRepo := repository.NewMockUserRepo(mc)
-			mockRepo.EXPECT().CreateCompany(tt.args.ctx, tt.args.companyData).Return(tt.mockResponse()).AnyTimes()
+			// the signed in user is stored as the owner of the new company
+			companyData := models.Company{
+				Name:     tt.args.companyData.Name,
+				Location: tt.args.companyData.Location,
+				Field:    tt.args.companyData.Field,
+				Members:  []models.CompanyMember{{Uid: 1, Role: models.MemberOwner}},
+			}
+			mockRepo.EXPECT().CreateCompany(tt.args.ctx, companyData).Return(tt.mockResponse()).AnyTimes()
 
 			svc, err := NewService(mockRepo, &auth.Auth{})
 			if err != nil {
@@ -77,7 +87,7 @@ func TestService_AddCompanyDetails(t *testing.T) {
 				return
 			}
 
-			got, err := svc.AddCompanyDetails(tt.args.ctx, tt.args.companyData)
+			got, err := svc.AddCompanyDetails(tt.args.ctx, auth.Claims{RegisteredClaims: jwt.RegisteredClaims{Subject: "1"}, Role: models.RoleRecruiter}, tt.args.companyData)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("Service.AddCompanyDetails() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -91,45 +101,54 @@ func TestService_AddCompanyDetails(t *testing.T) {
 
 func TestService_ViewAllCompanies(t *testing.T) {
 	type args struct {
-		ctx context.Context
+		ctx    context.Context
+		filter models.CompanyFilter
+		page   models.PageQuery
 	}
 	tests := []struct {
 		name         string
 		args         args
-		want         []models.Company
+		want         models.Page[models.CompanyResponse]
 		wantErr      bool
-		mockResponse func() ([]models.Company, error)
+		mockResponse func() ([]models.Company, int64, error)
 	}{
 		{
 			name: "error from database",
 			args: args{
-				ctx: context.Background(),
+				ctx:  context.Background(),
+				page: models.PageQuery{Limit: 20},
 			},
-			want:    nil,
+			want:    models.Page[models.CompanyResponse]{},
 			wantErr: true,
-			mockResponse: func() ([]models.Company, error) {
-				return nil, errors.New("test error from the  mock function")
+			mockResponse: func() ([]models.Company, int64, error) {
+				return nil, 0, errors.New("test error from the  mock function")
 			},
 		},
 		{
 			name: "success from database",
 			args: args{
-				ctx: context.Background(),
+				ctx:    context.Background(),
+				filter: models.CompanyFilter{Field: "IT"},
+				page:   models.PageQuery{Limit: 2},
 			},
-			want: []models.Company{
-				{
-					Name:     "Bosch",
-					Location: "Whitefield",
-					Field:    "IT",
-				},
-				{
-					Name:     "Allegis",
-					Location: "Koramangala",
-					Field:    "IT",
+			want: models.Page[models.CompanyResponse]{
+				Items: []models.CompanyResponse{
+					{
+						Name:     "Bosch",
+						Location: "Whitefield",
+						Field:    "IT",
+					},
+					{
+						Name:     "Allegis",
+						Location: "Koramangala",
+						Field:    "IT",
+					},
 				},
+				Total:      3,
+				NextCursor: pkg.EncodeCursor(2),
 			},
 			wantErr: false,
-			mockResponse: func() ([]models.Company, error) {
+			mockResponse: func() ([]models.Company, int64, error) {
 				return []models.Company{
 					{
 						Name:     "Bosch",
@@ -141,7 +160,7 @@ func TestService_ViewAllCompanies(t *testing.T) {
 						Location: "Koramangala",
 						Field:    "IT",
 					},
-				}, nil
+				}, 3, nil
 			},
 		},
 	}
@@ -149,14 +168,14 @@ func TestService_ViewAllCompanies(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			mc := gomock.NewController(t)
 			mockRepo := repository.NewMockUserRepo(mc)
-			mockRepo.EXPECT().ViewCompanies(tt.args.ctx).Return(tt.mockResponse()).AnyTimes()
+			mockRepo.EXPECT().ViewCompanies(tt.args.ctx, tt.args.filter, tt.args.page).Return(tt.mockResponse()).AnyTimes()
 
 			svc, err := NewService(mockRepo, &auth.Auth{})
 			if err != nil {
 				t.Errorf("error is initializing the repo layer")
 				return
 			}
-			got, err := svc.ViewAllCompanies(tt.args.ctx)
+			got, err := svc.ViewAllCompanies(tt.args.ctx, tt.args.filter, tt.args.page)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("Service.ViewAllCompanies() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -176,7 +195,7 @@ func TestService_ViewCompanyDetails(t *testing.T) {
 	tests := []struct {
 		name         string
 		args         args
-		want         models.Company
+		want         models.CompanyResponse
 		wantErr      bool
 		mockResponse func() (models.Company, error)
 	}{
@@ -186,7 +205,7 @@ func TestService_ViewCompanyDetails(t *testing.T) {
 				ctx: context.Background(),
 				cid: 1,
 			},
-			want:    models.Company{},
+			want:    models.CompanyResponse{},
 			wantErr: true,
 			mockResponse: func() (models.Company, error) {
 				return models.Company{}, errors.New("test error from the  mock function")
@@ -198,7 +217,7 @@ func TestService_ViewCompanyDetails(t *testing.T) {
 				ctx: context.Background(),
 				cid: 1,
 			},
-			want: models.Company{
+			want: models.CompanyResponse{
 				Name:     "Infosys",
 				Location: "Chennai",
 				Field:    "Web Development",
@@ -235,3 +254,106 @@ func TestService_ViewCompanyDetails(t *testing.T) {
 		})
 	}
 }
+
+func TestService_PatchCompanyDetails(t *testing.T) {
+	type args struct {
+		ctx         context.Context
+		claims      auth.Claims
+		cid         uint64
+		companyData models.UpdateCompany
+	}
+	owner := auth.Claims{RegisteredClaims: jwt.RegisteredClaims{Subject: "1"}, Role: models.RoleRecruiter}
+	tests := []struct {
+		name         string
+		args         args
+		want         models.CompanyResponse
+		wantErr      bool
+		member       models.CompanyMember
+		mockResponse func() (models.Company, error)
+	}{
+		{
+			name: "nothing to update",
+			args: args{
+				ctx:         context.Background(),
+				cid:         1,
+				companyData: models.UpdateCompany{},
+			},
+			want:    models.CompanyResponse{},
+			wantErr: true,
+		},
+		{
+			name: "not the owner of the company",
+			args: args{
+				ctx:         context.Background(),
+				claims:      owner,
+				cid:         1,
+				companyData: models.UpdateCompany{Name: "Infosys"},
+			},
+			want:    models.CompanyResponse{},
+			wantErr: true,
+			member:  models.CompanyMember{Model: gorm.Model{ID: 1}, Cid: 1, Uid: 1, Role: models.MemberRecruiter},
+		},
+		{
+			name: "error from the database",
+			args: args{
+				ctx:         context.Background(),
+				claims:      owner,
+				cid:         1,
+				companyData: models.UpdateCompany{Name: "Infosys"},
+			},
+			member:  models.CompanyMember{Model: gorm.Model{ID: 1}, Cid: 1, Uid: 1, Role: models.MemberOwner},
+			want:    models.CompanyResponse{},
+			wantErr: true,
+			mockResponse: func() (models.Company, error) {
+				return models.Company{}, errors.New("could not find the company")
+			},
+		},
+		{
+			name: "success from the database",
+			args: args{
+				ctx:         context.Background(),
+				claims:      owner,
+				cid:         1,
+				companyData: models.UpdateCompany{Name: "Infosys"},
+			},
+			member: models.CompanyMember{Model: gorm.Model{ID: 1}, Cid: 1, Uid: 1, Role: models.MemberOwner},
+			want: models.CompanyResponse{
+				Name:     "Infosys",
+				Location: "Chennai",
+				Field:    "IT",
+			},
+			wantErr: false,
+			mockResponse: func() (models.Company, error) {
+				return models.Company{
+					Name:     "Infosys",
+					Location: "Chennai",
+					Field:    "IT",
+				}, nil
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mc := gomock.NewController(t)
+			mockRepo := repository.NewMockUserRepo(mc)
+			mockRepo.EXPECT().FindCompanyMember(tt.args.ctx, tt.args.cid, uint64(1)).Return(tt.member, nil).AnyTimes()
+			if tt.mockResponse != nil {
+				mockRepo.EXPECT().UpdateCompany(tt.args.ctx, tt.args.cid, models.Company{Name: tt.args.companyData.Name}).Return(tt.mockResponse()).Times(1)
+			}
+
+			svc, err := NewService(mockRepo, &auth.Auth{})
+			if err != nil {
+				t.Errorf("error is initializing the repo layer")
+				return
+			}
+			got, err := svc.PatchCompanyDetails(tt.args.ctx, tt.args.claims, tt.args.cid, tt.args.companyData)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Service.PatchCompanyDetails() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Service.PatchCompanyDetails() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,119 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/afthaab/job-portal/internal/apperr"
+	"github.com/afthaab/job-portal/internal/mail"
+	"github.com/afthaab/job-portal/internal/models"
+	"github.com/afthaab/job-portal/internal/repository"
+)
+
+var (
+	errInvalidEmailToken = apperr.Validation("invalid or expired verification token")
+	errEmailNotVerified  = apperr.Forbidden("email is not verified, follow the link mailed to it or ask for a new one")
+)
+
+// VerifyEmail follows a link mailed to verify the address of a new account or to confirm a changed one
+func (s *Service) VerifyEmail(ctx context.Context, token string) (models.UserResponse, error) {
+	tokenHash := hashToken(token)
+	tokenData, err := s.UserRepo.UseUserToken(ctx, models.TokenPurposeVerifyEmail, tokenHash)
+	if errors.Is(err, apperr.ErrNotFound) {
+		// the links that confirm a changed address open the same page
+		return s.confirmEmailChange(ctx, tokenHash)
+	}
+	if errors.Is(err, repository.ErrUserTokenUsed) {
+		return models.UserResponse{}, errInvalidEmailToken
+	}
+	if err != nil {
+		return models.UserResponse{}, err
+	}
+	if time.Now().After(tokenData.ExpiresAt) {
+		return models.UserResponse{}, errInvalidEmailToken
+	}
+
+	// the user may have changed the address since the token was sent, that address is verified on its own
+	err = s.UserRepo.VerifyUserEmail(ctx, uint64(tokenData.Uid), tokenData.Email)
+	if errors.Is(err, apperr.ErrNotFound) {
+		return models.UserResponse{}, errInvalidEmailToken
+	}
+	if err != nil {
+		return models.UserResponse{}, err
+	}
+
+	userDetails, err := s.UserRepo.FindUserById(ctx, uint64(tokenData.Uid))
+	if err != nil {
+		return models.UserResponse{}, err
+	}
+	return newUserResponse(userDetails), nil
+}
+
+// ResendVerification mails a new link to an unverified account and makes the earlier ones useless.
+// It answers the same for an unknown or already verified email so it can not be used to find accounts,
+// the link is stored and mailed after the answer so it does not take longer either
+func (s *Service) ResendVerification(ctx context.Context, email string) error {
+	userDetails, err := s.UserRepo.CheckEmail(ctx, email)
+	if errors.Is(err, apperr.ErrNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if userDetails.EmailVerified() {
+		return nil
+	}
+
+	s.goBackground("sending the verification mail", func(ctx context.Context) error {
+		err := s.UserRepo.RevokeUserTokens(ctx, uint64(userDetails.ID), models.TokenPurposeVerifyEmail)
+		if err != nil {
+			return err
+		}
+		return s.sendVerification(ctx, userDetails)
+	})
+	return nil
+}
+
+// sendVerification stores a new verification token for the current email of the user and mails it
+func (s *Service) sendVerification(ctx context.Context, userDetails models.User) error {
+	token, err := newOpaqueToken()
+	if err != nil {
+		return err
+	}
+	_, err = s.UserRepo.CreateUserToken(ctx, models.UserToken{
+		Uid:       userDetails.ID,
+		Purpose:   models.TokenPurposeVerifyEmail,
+		TokenHash: hashToken(token),
+		Email:     userDetails.Email,
+		ExpiresAt: time.Now().Add(s.verification.TTL),
+	})
+	if err != nil {
+		return err
+	}
+
+	return s.mailer.Send(ctx, mail.Message{
+		To:      userDetails.Email,
+		Subject: "Verify your email address",
+		Body: fmt.Sprintf("Hi %s,\n\nplease confirm this is your email address:\n\n%s\n\n"+
+			"This expires in %s. If you did not sign up for the job portal you can ignore this mail.\n",
+			userDetails.Username, tokenLink(s.verification.URL, token), s.verification.TTL),
+	})
+}
+
+// tokenLink is the link a mailed token is opened with, the bare token when there is no page to open
+func tokenLink(page string, token string) string {
+	if page == "" {
+		return "your token is " + token
+	}
+	u, err := url.Parse(page)
+	if err != nil {
+		return "your token is " + token
+	}
+	q := u.Query()
+	q.Set("token", token)
+	u.RawQuery = q.Encode()
+	return u.String()
+}
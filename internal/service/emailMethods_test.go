@@ -0,0 +1,217 @@
+package service
+
+import (
+	"context"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/afthaab/job-portal/internal/apperr"
+	"github.com/afthaab/job-portal/internal/mail"
+	"github.com/afthaab/job-portal/internal/models"
+	"github.com/afthaab/job-portal/internal/repository"
+	"go.uber.org/mock/gomock"
+	"gorm.io/gorm"
+)
+
+func TestService_VerifyEmail(t *testing.T) {
+	verifiedAt := time.Now()
+	live := models.UserToken{Uid: 1, Email: "afthab606@gmail.com", ExpiresAt: time.Now().Add(time.Hour)}
+	tests := []struct {
+		name      string
+		setupMock func(m *repository.MockUserRepo)
+		want      models.UserResponse
+		wantKind  apperr.Kind
+		wantErr   bool
+	}{
+		{
+			name: "unknown token",
+			setupMock: func(m *repository.MockUserRepo) {
+				m.EXPECT().UseUserToken(gomock.Any(), models.TokenPurposeVerifyEmail, hashToken("token")).Return(models.UserToken{}, apperr.NotFound("could not find the token"))
+				m.EXPECT().UseUserToken(gomock.Any(), models.TokenPurposeChangeEmail, hashToken("token")).Return(models.UserToken{}, apperr.NotFound("could not find the token"))
+			},
+			wantErr:  true,
+			wantKind: apperr.KindValidation,
+		},
+		{
+			name: "token already used",
+			setupMock: func(m *repository.MockUserRepo) {
+				m.EXPECT().UseUserToken(gomock.Any(), models.TokenPurposeVerifyEmail, hashToken("token")).Return(models.UserToken{}, repository.ErrUserTokenUsed)
+			},
+			wantErr:  true,
+			wantKind: apperr.KindValidation,
+		},
+		{
+			name: "token expired",
+			setupMock: func(m *repository.MockUserRepo) {
+				m.EXPECT().UseUserToken(gomock.Any(), models.TokenPurposeVerifyEmail, hashToken("token")).Return(models.UserToken{Uid: 1, ExpiresAt: time.Now().Add(-time.Minute)}, nil)
+			},
+			wantErr:  true,
+			wantKind: apperr.KindValidation,
+		},
+		{
+			name: "email changed since the token was sent",
+			setupMock: func(m *repository.MockUserRepo) {
+				m.EXPECT().UseUserToken(gomock.Any(), models.TokenPurposeVerifyEmail, hashToken("token")).Return(live, nil)
+				m.EXPECT().VerifyUserEmail(gomock.Any(), uint64(1), "afthab606@gmail.com").Return(apperr.NotFound("user with this email not found"))
+			},
+			wantErr:  true,
+			wantKind: apperr.KindValidation,
+		},
+		{
+			name: "verified",
+			setupMock: func(m *repository.MockUserRepo) {
+				m.EXPECT().UseUserToken(gomock.Any(), models.TokenPurposeVerifyEmail, hashToken("token")).Return(live, nil)
+				m.EXPECT().VerifyUserEmail(gomock.Any(), uint64(1), "afthab606@gmail.com").Return(nil)
+				m.EXPECT().FindUserById(gomock.Any(), uint64(1)).Return(models.User{
+					Model:           gorm.Model{ID: 1},
+					Username:        "afthab",
+					Email:           "afthab606@gmail.com",
+					EmailVerifiedAt: &verifiedAt,
+				}, nil)
+			},
+			want: models.UserResponse{ID: 1, Username: "afthab", Email: "afthab606@gmail.com", EmailVerified: true},
+		},
+		{
+			name: "new address taken since the change was asked for",
+			setupMock: func(m *repository.MockUserRepo) {
+				m.EXPECT().UseUserToken(gomock.Any(), models.TokenPurposeVerifyEmail, hashToken("token")).Return(models.UserToken{}, apperr.NotFound("could not find the token"))
+				m.EXPECT().UseUserToken(gomock.Any(), models.TokenPurposeChangeEmail, hashToken("token")).Return(models.UserToken{Uid: 1, Email: "new@example.com", ExpiresAt: time.Now().Add(time.Hour)}, nil)
+				m.EXPECT().FindUserById(gomock.Any(), uint64(1)).Return(models.User{Model: gorm.Model{ID: 1}, Email: "afthab606@gmail.com"}, nil)
+				m.EXPECT().ChangeUserEmail(gomock.Any(), uint64(1), "new@example.com").Return(apperr.Conflict("could not change the email, it already exists"))
+			},
+			wantErr:  true,
+			wantKind: apperr.KindConflict,
+		},
+		{
+			name: "address changed",
+			setupMock: func(m *repository.MockUserRepo) {
+				m.EXPECT().UseUserToken(gomock.Any(), models.TokenPurposeVerifyEmail, hashToken("token")).Return(models.UserToken{}, apperr.NotFound("could not find the token"))
+				m.EXPECT().UseUserToken(gomock.Any(), models.TokenPurposeChangeEmail, hashToken("token")).Return(models.UserToken{Uid: 1, Email: "new@example.com", ExpiresAt: time.Now().Add(time.Hour)}, nil)
+				old := m.EXPECT().FindUserById(gomock.Any(), uint64(1)).Return(models.User{Model: gorm.Model{ID: 1}, Username: "afthab", Email: "afthab606@gmail.com", EmailVerifiedAt: &verifiedAt}, nil)
+				change := m.EXPECT().ChangeUserEmail(gomock.Any(), uint64(1), "new@example.com").Return(nil).After(old)
+				m.EXPECT().FindUserById(gomock.Any(), uint64(1)).Return(models.User{Model: gorm.Model{ID: 1}, Username: "afthab", Email: "new@example.com", EmailVerifiedAt: &verifiedAt}, nil).After(change)
+			},
+			want: models.UserResponse{ID: 1, Username: "afthab", Email: "new@example.com", EmailVerified: true},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mc := gomock.NewController(t)
+			mockRepo := repository.NewMockUserRepo(mc)
+			tt.setupMock(mockRepo)
+
+			svc, err := NewService(mockRepo, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := svc.VerifyEmail(context.Background(), "token")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Service.VerifyEmail() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr && apperr.KindOf(err) != tt.wantKind {
+				t.Errorf("Service.VerifyEmail() kind = %v, want %v", apperr.KindOf(err), tt.wantKind)
+			}
+			if got != tt.want {
+				t.Errorf("Service.VerifyEmail() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestService_ResendVerification(t *testing.T) {
+	verifiedAt := time.Now()
+	tests := []struct {
+		name      string
+		setupMock func(m *repository.MockUserRepo, stored *models.UserToken)
+		wantMail  bool
+	}{
+		{
+			name: "unknown email",
+			setupMock: func(m *repository.MockUserRepo, stored *models.UserToken) {
+				m.EXPECT().CheckEmail(gomock.Any(), "afthab606@gmail.com").Return(models.User{}, apperr.NotFound("email not found"))
+			},
+		},
+		{
+			name: "already verified",
+			setupMock: func(m *repository.MockUserRepo, stored *models.UserToken) {
+				m.EXPECT().CheckEmail(gomock.Any(), "afthab606@gmail.com").Return(models.User{EmailVerifiedAt: &verifiedAt}, nil)
+			},
+		},
+		{
+			name: "new link replaces the old ones",
+			setupMock: func(m *repository.MockUserRepo, stored *models.UserToken) {
+				m.EXPECT().CheckEmail(gomock.Any(), "afthab606@gmail.com").Return(models.User{
+					Model:    gorm.Model{ID: 1},
+					Username: "afthab",
+					Email:    "afthab606@gmail.com",
+				}, nil)
+				revoke := m.EXPECT().RevokeUserTokens(gomock.Any(), uint64(1), models.TokenPurposeVerifyEmail).Return(nil)
+				m.EXPECT().CreateUserToken(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, token models.UserToken) (models.UserToken, error) {
+					*stored = token
+					return token, nil
+				}).After(revoke)
+			},
+			wantMail: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mc := gomock.NewController(t)
+			mockRepo := repository.NewMockUserRepo(mc)
+			var stored models.UserToken
+			tt.setupMock(mockRepo, &stored)
+
+			dir := t.TempDir()
+			mailer, err := mail.NewFileMailer(dir, "no-reply@localhost")
+			if err != nil {
+				t.Fatal(err)
+			}
+			svc, err := NewService(mockRepo, nil, WithMailer(mailer), WithVerification(Verification{
+				Required: true,
+				TTL:      time.Hour,
+				URL:      "https://jobportal.com/verify",
+			}))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			err = svc.ResendVerification(context.Background(), "afthab606@gmail.com")
+			if err != nil {
+				t.Fatalf("Service.ResendVerification() error = %v", err)
+			}
+			// the mail goes out after the answer
+			err = svc.Wait(context.Background())
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			files, _ := filepath.Glob(filepath.Join(dir, "*.eml"))
+			if !tt.wantMail {
+				if len(files) != 0 {
+					t.Errorf("Service.ResendVerification() sent %d mails, want none", len(files))
+				}
+				return
+			}
+			if len(files) != 1 {
+				t.Fatalf("Service.ResendVerification() sent %d mails, want 1", len(files))
+			}
+			data, err := os.ReadFile(files[0])
+			if err != nil {
+				t.Fatal(err)
+			}
+			// the link carries the token whose hash was stored for the current email
+			link := regexp.MustCompile(`https://jobportal\.com/verify\?token=\S+`).Find(data)
+			u, err := url.Parse(string(link))
+			if err != nil || link == nil {
+				t.Fatalf("no verification link in the mail:\n%s", data)
+			}
+			if hashToken(u.Query().Get("token")) != stored.TokenHash || stored.Email != "afthab606@gmail.com" || stored.Purpose != models.TokenPurposeVerifyEmail {
+				t.Errorf("stored token %+v does not match the mailed link %s", stored, link)
+			}
+		})
+	}
+}
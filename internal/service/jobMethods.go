@@ -2,40 +2,198 @@ package service
 
 import (
 	"context"
+	"errors"
 
+	"github.com/afthaab/job-portal/internal/apperr"
+	"github.com/afthaab/job-portal/internal/auth"
 	"github.com/afthaab/job-portal/internal/models"
 )
 
-func (s *Service) ViewJobById(ctx context.Context, jid uint64) (models.Jobs, error) {
-	jobData, err := s.UserRepo.ViewJobDetailsBy(ctx, jid)
+// managers are the company roles that can post and edit jobs and handle the applicants
+var managers = []string{models.MemberOwner, models.MemberRecruiter}
+
+// members are every company role, all of them can see the closed and draft jobs of the company
+var members = []string{models.MemberOwner, models.MemberRecruiter, models.MemberViewer}
+
+// canSeeHiddenJobs reports whether closed and draft postings of the company are shown to the user,
+// only admins and the members of the company see them
+func (s *Service) canSeeHiddenJobs(ctx context.Context, claims auth.Claims, cid uint64) (bool, error) {
+	err := s.requireMember(ctx, claims, cid, members...)
+	if errors.Is(err, errNotCompanyMember) {
+		return false, nil
+	}
 	if err != nil {
-		return models.Jobs{}, err
+		return false, err
 	}
-	return jobData, nil
+	return true, nil
 }
 
-func (s *Service) ViewAllJobs(ctx context.Context) ([]models.Jobs, error) {
-	jobDatas, err := s.UserRepo.FindAllJobs(ctx)
+func (s *Service) ViewJobById(ctx context.Context, claims auth.Claims, jid uint64) (models.JobResponse, error) {
+	jobData, err := s.findJob(ctx, jid)
 	if err != nil {
-		return nil, err
+		return models.JobResponse{}, err
+	}
+	if jobData.Status != models.JobOpen {
+		ok, err := s.canSeeHiddenJobs(ctx, claims, uint64(jobData.Cid))
+		if err != nil {
+			return models.JobResponse{}, err
+		}
+		if !ok {
+			return models.JobResponse{}, apperr.NotFound("could not find the job")
+		}
 	}
-	return jobDatas, nil
+	return newJobResponse(jobData), nil
+}
+
+func (s *Service) ViewAllJobs(ctx context.Context, claims auth.Claims, filter models.JobFilter, page models.PageQuery) (models.Page[models.JobResponse], error) {
+	memberOf, openOnly, err := s.memberOf(ctx, claims)
+	if err != nil {
+		return models.Page[models.JobResponse]{}, err
+	}
+	filter.OpenOnly, filter.MemberOf = openOnly, memberOf
+	jobDatas, total, err := s.UserRepo.FindAllJobs(ctx, filter, page)
+	if err != nil {
+		return models.Page[models.JobResponse]{}, err
+	}
+	return newPage(mapItems(jobDatas, newJobResponse), total, page), nil
 
 }
 
-func (s *Service) AddJobDetails(ctx context.Context, jobData models.Jobs, cid uint64) (models.Jobs, error) {
-	jobData.Cid = uint(cid)
-	jobData, err := s.UserRepo.CreateJob(ctx, jobData)
+func (s *Service) AddJobDetails(ctx context.Context, claims auth.Claims, jobData models.NewJob, cid uint64) (models.JobResponse, error) {
+	err := s.requireMember(ctx, claims, cid, managers...)
+	if err != nil {
+		return models.JobResponse{}, err
+	}
+	job := newJob(jobData)
+	job.Cid = uint(cid)
+	job, err = s.UserRepo.CreateJob(ctx, job)
+	if err != nil {
+		return models.JobResponse{}, err
+	}
+	return newJobResponse(job), nil
+}
+
+func (s *Service) ViewJob(ctx context.Context, claims auth.Claims, cid uint64, page models.PageQuery) (models.Page[models.JobResponse], error) {
+	ok, err := s.canSeeHiddenJobs(ctx, claims, cid)
+	if err != nil {
+		return models.Page[models.JobResponse]{}, err
+	}
+	filter := models.JobFilter{
+		Cid:      cid,
+		OpenOnly: !ok,
+	}
+	jobData, total, err := s.UserRepo.FindAllJobs(ctx, filter, page)
+	if err != nil {
+		return models.Page[models.JobResponse]{}, err
+	}
+	return newPage(mapItems(jobData, newJobResponse), total, page), nil
+}
+
+func (s *Service) UpdateJobDetails(ctx context.Context, claims auth.Claims, jid uint64, jobData models.NewJob) (models.JobResponse, error) {
+	existing, err := s.findManagedJob(ctx, claims, jid)
+	if err != nil {
+		return models.JobResponse{}, err
+	}
+
+	existing.Name = jobData.Name
+	existing.Compensation = jobData.Compensation
+	existing.NoticePeriodDays = jobData.NoticePeriodDays
+	existing.Description = jobData.Description
+	if jobData.Status != "" {
+		existing.Status = jobData.Status
+	}
+	existing.NeedsReview = false
+
+	return s.updateJob(ctx, jid, existing)
+}
+
+func (s *Service) PatchJobDetails(ctx context.Context, claims auth.Claims, jid uint64, jobData models.UpdateJob) (models.JobResponse, error) {
+	if jobData == (models.UpdateJob{}) {
+		return models.JobResponse{}, apperr.Validation("nothing to update")
+	}
+	existing, err := s.findManagedJob(ctx, claims, jid)
+	if err != nil {
+		return models.JobResponse{}, err
+	}
+
+	if jobData.Name != "" {
+		existing.Name = jobData.Name
+	}
+	if jobData.Compensation != nil {
+		existing.Compensation = *jobData.Compensation
+	}
+	if jobData.NoticePeriodDays != nil {
+		existing.NoticePeriodDays = *jobData.NoticePeriodDays
+	}
+	if jobData.Description != "" {
+		existing.Description = jobData.Description
+	}
+	if jobData.Status != "" {
+		existing.Status = jobData.Status
+	}
+	// a job edited after the salary migration has been looked at by a recruiter
+	if jobData.Compensation != nil || jobData.NoticePeriodDays != nil {
+		existing.NeedsReview = false
+	}
+
+	return s.updateJob(ctx, jid, existing)
+}
+
+func (s *Service) UpdateJobStatus(ctx context.Context, claims auth.Claims, jid uint64, status string) (models.JobResponse, error) {
+	existing, err := s.findManagedJob(ctx, claims, jid)
+	if err != nil {
+		return models.JobResponse{}, err
+	}
+	existing.Status = status
+	return s.updateJob(ctx, jid, existing)
+}
+
+func (s *Service) updateJob(ctx context.Context, jid uint64, jobData models.Jobs) (models.JobResponse, error) {
+	jobData, err := s.UserRepo.UpdateJob(ctx, jid, jobData)
+	if err != nil {
+		return models.JobResponse{}, err
+	}
+	return newJobResponse(jobData), nil
+}
+
+func (s *Service) findJob(ctx context.Context, jid uint64) (models.Jobs, error) {
+	jobData, err := s.UserRepo.ViewJobDetailsBy(ctx, jid)
 	if err != nil {
 		return models.Jobs{}, err
 	}
+	if jobData.ID == 0 {
+		return models.Jobs{}, apperr.NotFound("could not find the job")
+	}
 	return jobData, nil
 }
 
-func (s *Service) ViewJob(ctx context.Context, cid uint64) ([]models.Jobs, error) {
-	jobData, err := s.UserRepo.FindJob(ctx, cid)
+// findManagedJob loads the job if the user may edit the jobs of its company. A closed or draft job is
+// not found for users who can not see it, like ViewJobById, so the refusal does not tell that it exists
+func (s *Service) findManagedJob(ctx context.Context, claims auth.Claims, jid uint64) (models.Jobs, error) {
+	jobData, err := s.findJob(ctx, jid)
+	if err != nil {
+		return models.Jobs{}, err
+	}
+	err = s.requireMember(ctx, claims, uint64(jobData.Cid), managers...)
+	if errors.Is(err, errNotCompanyMember) && jobData.Status != models.JobOpen {
+		ok, seeErr := s.canSeeHiddenJobs(ctx, claims, uint64(jobData.Cid))
+		if seeErr != nil {
+			return models.Jobs{}, seeErr
+		}
+		if !ok {
+			return models.Jobs{}, apperr.NotFound("could not find the job")
+		}
+	}
 	if err != nil {
-		return nil, err
+		return models.Jobs{}, err
 	}
 	return jobData, nil
 }
+
+func (s *Service) DeleteJob(ctx context.Context, claims auth.Claims, jid uint64) error {
+	_, err := s.findManagedJob(ctx, claims, jid)
+	if err != nil {
+		return err
+	}
+	return s.UserRepo.DeleteJob(ctx, jid)
+}
@@ -6,66 +6,97 @@ import (
 	"reflect"
 	"testing"
 
+	"github.com/afthaab/job-portal/internal/apperr"
 	"github.com/afthaab/job-portal/internal/auth"
 	"github.com/afthaab/job-portal/internal/models"
+	"github.com/afthaab/job-portal/internal/pkg"
 	"github.com/afthaab/job-portal/internal/repository"
+	"github.com/golang-jwt/jwt/v5"
 	"go.uber.org/mock/gomock"
+	"gorm.io/gorm"
 )
 
 func TestService_AddJobDetails(t *testing.T) {
 	type args struct {
 		ctx     context.Context
-		jobData models.Jobs
+		claims  auth.Claims
+		jobData models.NewJob
 		Cid     uint64
 	}
+	recruiter := auth.Claims{RegisteredClaims: jwt.RegisteredClaims{Subject: "1"}, Role: models.RoleRecruiter}
 	tests := []struct {
 		name             string
 		args             args
-		want             models.Jobs
+		want             models.JobResponse
 		wantErr          bool
+		member           models.CompanyMember
 		mockRepoResponse func() (models.Jobs, error)
 	}{
+		{
+			name: "not a member of the company",
+			args: args{
+				ctx:     context.Background(),
+				claims:  recruiter,
+				jobData: models.NewJob{Name: "Junior web developer"},
+				Cid:     1,
+			},
+			want:    models.JobResponse{},
+			wantErr: true,
+		},
+		{
+			name: "viewers cannot post jobs",
+			args: args{
+				ctx:     context.Background(),
+				claims:  recruiter,
+				jobData: models.NewJob{Name: "Junior web developer"},
+				Cid:     1,
+			},
+			want:    models.JobResponse{},
+			wantErr: true,
+			member:  models.CompanyMember{Model: gorm.Model{ID: 1}, Cid: 1, Uid: 1, Role: models.MemberViewer},
+		},
 		{
 			name: "database success",
 			args: args{
-				ctx: context.Background(),
-				jobData: models.Jobs{
-					Cid:          1,
-					Name:         "Junior web developer",
-					NoticePeriod: "30",
-					Salary:       "10000",
+				ctx:    context.Background(),
+				claims: recruiter,
+				jobData: models.NewJob{
+					Name:             "Junior web developer",
+					NoticePeriodDays: 30,
+					Compensation:     models.Compensation{MinAmount: 10000, MaxAmount: 10000, Currency: "INR", PayPeriod: models.PayYearly},
 				},
 				Cid: 1,
 			},
-			want: models.Jobs{
-				Cid:          1,
-				Name:         "Junior web developer",
-				NoticePeriod: "30",
-				Salary:       "10000",
+			want: models.JobResponse{
+				Cid:              1,
+				Name:             "Junior web developer",
+				NoticePeriodDays: 30,
+				Compensation:     models.Compensation{MinAmount: 10000, MaxAmount: 10000, Currency: "INR", PayPeriod: models.PayYearly},
 			},
 			wantErr: false,
+			member:  models.CompanyMember{Model: gorm.Model{ID: 1}, Cid: 1, Uid: 1, Role: models.MemberRecruiter},
 			mockRepoResponse: func() (models.Jobs, error) {
 				return models.Jobs{
-					Cid:          1,
-					Name:         "Junior web developer",
-					NoticePeriod: "30",
-					Salary:       "10000",
+					Cid:              1,
+					Name:             "Junior web developer",
+					NoticePeriodDays: 30,
+					Compensation:     models.Compensation{MinAmount: 10000, MaxAmount: 10000, Currency: "INR", PayPeriod: models.PayYearly},
 				}, nil
 			},
 		},
 		{
 			name: "database error",
 			args: args{
-				ctx: context.Background(),
-				jobData: models.Jobs{
-					Cid:          1,
-					Name:         "Junior web developer",
-					NoticePeriod: "30",
-					Salary:       "10000",
+				ctx:    context.Background(),
+				claims: auth.Claims{Role: models.RoleAdmin},
+				jobData: models.NewJob{
+					Name:             "Junior web developer",
+					NoticePeriodDays: 30,
+					Compensation:     models.Compensation{MinAmount: 10000, MaxAmount: 10000, Currency: "INR", PayPeriod: models.PayYearly},
 				},
 				Cid: 1,
 			},
-			want:    models.Jobs{},
+			want:    models.JobResponse{},
 			wantErr: true,
 			mockRepoResponse: func() (models.Jobs, error) {
 				return models.Jobs{}, errors.New("could not create the jobs")
@@ -76,14 +107,24 @@ func TestService_AddJobDetails(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			mc := gomock.NewController(t)
 			mockRepo := repository.NewMockUserRepo(mc)
-			mockRepo.EXPECT().CreateJob(tt.args.ctx, tt.args.jobData).Return(tt.mockRepoResponse()).AnyTimes()
+			mockRepo.EXPECT().FindCompanyMember(tt.args.ctx, tt.args.Cid, uint64(1)).Return(tt.member, nil).AnyTimes()
+			if tt.mockRepoResponse != nil {
+				// the company of the job comes from the path and not from the body
+				jobData := models.Jobs{
+					Cid:              uint(tt.args.Cid),
+					Name:             tt.args.jobData.Name,
+					NoticePeriodDays: tt.args.jobData.NoticePeriodDays,
+					Compensation:     tt.args.jobData.Compensation,
+				}
+				mockRepo.EXPECT().CreateJob(tt.args.ctx, jobData).Return(tt.mockRepoResponse()).Times(1)
+			}
 
 			svc, err := NewService(mockRepo, &auth.Auth{})
 			if err != nil {
 				t.Errorf("error is initializing the repo layer")
 				return
 			}
-			got, err := svc.AddJobDetails(tt.args.ctx, tt.args.jobData, tt.args.Cid)
+			got, err := svc.AddJobDetails(tt.args.ctx, tt.args.claims, tt.args.jobData, tt.args.Cid)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("Service.AddJobDetails() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -97,19 +138,20 @@ func TestService_AddJobDetails(t *testing.T) {
 
 func TestService_ViewJobById(t *testing.T) {
 	type args struct {
-		ctx context.Context
-		jid uint64
+		ctx    context.Context
+		claims auth.Claims
+		jid    uint64
 	}
 	tests := []struct {
 		name             string
 		args             args
-		want             models.Jobs
+		want             models.JobResponse
 		wantErr          bool
 		mockRepoResponse func() (models.Jobs, error)
 	}{
 		{
 			name: "error from db",
-			want: models.Jobs{},
+			want: models.JobResponse{},
 			args: args{
 				ctx: context.Background(),
 				jid: 15,
@@ -119,30 +161,97 @@ func TestService_ViewJobById(t *testing.T) {
 				return models.Jobs{}, errors.New("test error")
 			},
 		},
+		{
+			name: "missing job for admin",
+			want: models.JobResponse{},
+			args: args{
+				ctx:    context.Background(),
+				claims: auth.Claims{RegisteredClaims: jwt.RegisteredClaims{Subject: "3"}, Role: models.RoleAdmin},
+				jid:    15,
+			},
+			wantErr: true,
+			mockRepoResponse: func() (models.Jobs, error) {
+				return models.Jobs{}, apperr.NotFound("could not find the job")
+			},
+		},
+		{
+			name: "empty job for admin",
+			want: models.JobResponse{},
+			args: args{
+				ctx:    context.Background(),
+				claims: auth.Claims{RegisteredClaims: jwt.RegisteredClaims{Subject: "3"}, Role: models.RoleAdmin},
+				jid:    15,
+			},
+			wantErr: true,
+			mockRepoResponse: func() (models.Jobs, error) {
+				return models.Jobs{}, nil
+			},
+		},
 		{
 			name: "success",
-			want: models.Jobs{
-				Company: models.Company{
-					Name: "TCS",
-				},
-				Cid:  1,
-				Name: "SDE",
+			want: models.JobResponse{
+				ID:     15,
+				Cid:    1,
+				Name:   "SDE",
+				Status: models.JobOpen,
 			},
 			args: args{
-				ctx: context.Background(),
-				jid: 15,
+				ctx:    context.Background(),
+				claims: auth.Claims{RegisteredClaims: jwt.RegisteredClaims{Subject: "2"}, Role: models.RoleCandidate},
+				jid:    15,
 			},
 			wantErr: false,
 			mockRepoResponse: func() (models.Jobs, error) {
 				return models.Jobs{
+					Model: gorm.Model{ID: 15},
 					Company: models.Company{
 						Name: "TCS",
 					},
-					Cid:  1,
-					Name: "SDE",
+					Cid:    1,
+					Name:   "SDE",
+					Status: models.JobOpen,
 				}, nil
 			},
 		},
+		{
+			name: "closed job hidden from candidate",
+			want: models.JobResponse{},
+			args: args{
+				ctx:    context.Background(),
+				claims: auth.Claims{RegisteredClaims: jwt.RegisteredClaims{Subject: "2"}, Role: models.RoleCandidate},
+				jid:    15,
+			},
+			wantErr: true,
+			mockRepoResponse: func() (models.Jobs, error) {
+				return models.Jobs{Model: gorm.Model{ID: 15}, Cid: 1, Name: "SDE", Status: models.JobClosed}, nil
+			},
+		},
+		{
+			name: "closed job hidden from recruiter of another company",
+			want: models.JobResponse{},
+			args: args{
+				ctx:    context.Background(),
+				claims: auth.Claims{RegisteredClaims: jwt.RegisteredClaims{Subject: "2"}, Role: models.RoleRecruiter},
+				jid:    15,
+			},
+			wantErr: true,
+			mockRepoResponse: func() (models.Jobs, error) {
+				return models.Jobs{Model: gorm.Model{ID: 15}, Cid: 1, Name: "SDE", Status: models.JobClosed}, nil
+			},
+		},
+		{
+			name: "closed job shown to recruiter of the company",
+			want: models.JobResponse{ID: 15, Cid: 1, Name: "SDE", Status: models.JobClosed},
+			args: args{
+				ctx:    context.Background(),
+				claims: auth.Claims{RegisteredClaims: jwt.RegisteredClaims{Subject: "1"}, Role: models.RoleRecruiter},
+				jid:    15,
+			},
+			wantErr: false,
+			mockRepoResponse: func() (models.Jobs, error) {
+				return models.Jobs{Model: gorm.Model{ID: 15}, Cid: 1, Name: "SDE", Status: models.JobClosed}, nil
+			},
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -151,8 +260,15 @@ func TestService_ViewJobById(t *testing.T) {
 			if tt.mockRepoResponse != nil {
 				mockRepo.EXPECT().ViewJobDetailsBy(tt.args.ctx, tt.args.jid).Return(tt.mockRepoResponse()).AnyTimes()
 			}
+			// user 1 recruits for company 1, every other user is not a member
+			mockRepo.EXPECT().FindCompanyMember(tt.args.ctx, uint64(1), gomock.Any()).DoAndReturn(func(ctx context.Context, cid uint64, uid uint64) (models.CompanyMember, error) {
+				if uid == 1 {
+					return models.CompanyMember{Model: gorm.Model{ID: 1}, Cid: 1, Uid: 1, Role: models.MemberRecruiter}, nil
+				}
+				return models.CompanyMember{}, nil
+			}).AnyTimes()
 			s, _ := NewService(mockRepo, &auth.Auth{})
-			got, err := s.ViewJobById(tt.args.ctx, tt.args.jid)
+			got, err := s.ViewJobById(tt.args.ctx, tt.args.claims, tt.args.jid)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("Service.ViewJobById() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -166,59 +282,120 @@ func TestService_ViewJobById(t *testing.T) {
 
 func TestService_ViewAllJobs(t *testing.T) {
 	type args struct {
-		ctx context.Context
+		ctx    context.Context
+		claims auth.Claims
+		filter models.JobFilter
+		page   models.PageQuery
 	}
 	tests := []struct {
 		name             string
 		args             args
-		want             []models.Jobs
+		want             models.Page[models.JobResponse]
 		wantErr          bool
-		mockRepoResponse func() ([]models.Jobs, error)
+		wantFilter       models.JobFilter
+		mockRepoResponse func() ([]models.Jobs, int64, error)
 	}{
 		{
 			name: "database success",
 			args: args{
-				ctx: context.Background(),
+				ctx:    context.Background(),
+				claims: auth.Claims{RegisteredClaims: jwt.RegisteredClaims{Subject: "2"}, Role: models.RoleCandidate},
+				filter: models.JobFilter{Location: "Bangalore"},
+				page:   models.PageQuery{Limit: 2},
 			},
-			want: []models.Jobs{
-				{
-					Cid:          01,
-					Name:         "junio web developer",
-					Salary:       "10000",
-					NoticePeriod: "30",
-				}, {
-					Cid:          01,
-					Name:         "senior web developer",
-					Salary:       "100000",
-					NoticePeriod: "50",
+			want: models.Page[models.JobResponse]{
+				Items: []models.JobResponse{
+					{
+						Cid:              01,
+						Name:             "junio web developer",
+						Compensation:     models.Compensation{MinAmount: 10000, MaxAmount: 10000, Currency: "INR", PayPeriod: models.PayYearly},
+						NoticePeriodDays: 30,
+					}, {
+						Cid:              01,
+						Name:             "senior web developer",
+						Compensation:     models.Compensation{MinAmount: 100000, MaxAmount: 100000, Currency: "INR", PayPeriod: models.PayYearly},
+						NoticePeriodDays: 50,
+					},
 				},
+				Total:      5,
+				NextCursor: pkg.EncodeCursor(2),
 			},
-			wantErr: false,
-			mockRepoResponse: func() ([]models.Jobs, error) {
+			wantErr:    false,
+			wantFilter: models.JobFilter{Location: "Bangalore", OpenOnly: true},
+			mockRepoResponse: func() ([]models.Jobs, int64, error) {
 				return []models.Jobs{
 					{
-						Cid:          01,
-						Name:         "junio web developer",
-						Salary:       "10000",
-						NoticePeriod: "30",
+						Cid:              01,
+						Name:             "junio web developer",
+						Compensation:     models.Compensation{MinAmount: 10000, MaxAmount: 10000, Currency: "INR", PayPeriod: models.PayYearly},
+						NoticePeriodDays: 30,
 					}, {
-						Cid:          01,
-						Name:         "senior web developer",
-						Salary:       "100000",
-						NoticePeriod: "50",
+						Cid:              01,
+						Name:             "senior web developer",
+						Compensation:     models.Compensation{MinAmount: 100000, MaxAmount: 100000, Currency: "INR", PayPeriod: models.PayYearly},
+						NoticePeriodDays: 50,
 					},
-				}, nil
+				}, 5, nil
+			},
+		},
+		{
+			name: "admin sees hidden jobs on the last page",
+			args: args{
+				ctx:    context.Background(),
+				claims: auth.Claims{Role: models.RoleAdmin},
+				page:   models.PageQuery{Limit: 2, Offset: 4},
+			},
+			want: models.Page[models.JobResponse]{
+				Items: []models.JobResponse{
+					{
+						Cid:    01,
+						Name:   "senior web developer",
+						Status: models.JobDraft,
+					},
+				},
+				Total: 5,
+			},
+			wantErr:    false,
+			wantFilter: models.JobFilter{},
+			mockRepoResponse: func() ([]models.Jobs, int64, error) {
+				return []models.Jobs{
+					{
+						Cid:    01,
+						Name:   "senior web developer",
+						Status: models.JobDraft,
+					},
+				}, 5, nil
+			},
+		},
+		{
+			name: "recruiter sees the hidden jobs of their companies",
+			args: args{
+				ctx:    context.Background(),
+				claims: auth.Claims{RegisteredClaims: jwt.RegisteredClaims{Subject: "1"}, Role: models.RoleRecruiter},
+				page:   models.PageQuery{Limit: 20},
+			},
+			want: models.Page[models.JobResponse]{
+				Items: []models.JobResponse{{Cid: 1, Name: "senior web developer", Status: models.JobDraft}},
+				Total: 1,
+			},
+			wantErr:    false,
+			wantFilter: models.JobFilter{OpenOnly: true, MemberOf: []uint{1}},
+			mockRepoResponse: func() ([]models.Jobs, int64, error) {
+				return []models.Jobs{{Cid: 1, Name: "senior web developer", Status: models.JobDraft}}, 1, nil
 			},
 		},
 		{
 			name: "database error",
 			args: args{
-				ctx: context.Background(),
+				ctx:    context.Background(),
+				claims: auth.Claims{RegisteredClaims: jwt.RegisteredClaims{Subject: "2"}, Role: models.RoleCandidate},
+				page:   models.PageQuery{Limit: 20},
 			},
-			want:    nil,
-			wantErr: true,
-			mockRepoResponse: func() ([]models.Jobs, error) {
-				return nil, errors.New("could not find the records in the database")
+			want:       models.Page[models.JobResponse]{},
+			wantErr:    true,
+			wantFilter: models.JobFilter{OpenOnly: true},
+			mockRepoResponse: func() ([]models.Jobs, int64, error) {
+				return nil, 0, errors.New("could not find the records in the database")
 			},
 		},
 	}
@@ -226,7 +403,13 @@ func TestService_ViewAllJobs(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			mc := gomock.NewController(t)
 			mockRepo := repository.NewMockUserRepo(mc)
-			mockRepo.EXPECT().FindAllJobs(tt.args.ctx).Return(tt.mockRepoResponse()).AnyTimes()
+			mockRepo.EXPECT().FindMemberCompanies(tt.args.ctx, gomock.Any()).DoAndReturn(func(ctx context.Context, uid uint64) ([]uint, error) {
+				if uid == 1 {
+					return []uint{1}, nil
+				}
+				return nil, nil
+			}).AnyTimes()
+			mockRepo.EXPECT().FindAllJobs(tt.args.ctx, tt.wantFilter, tt.args.page).Return(tt.mockRepoResponse()).Times(1)
 
 			svc, err := NewService(mockRepo, &auth.Auth{})
 			if err != nil {
@@ -234,7 +417,7 @@ func TestService_ViewAllJobs(t *testing.T) {
 				return
 			}
 
-			got, err := svc.ViewAllJobs(tt.args.ctx)
+			got, err := svc.ViewAllJobs(tt.args.ctx, tt.args.claims, tt.args.filter, tt.args.page)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("Service.ViewAllJobs() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -248,64 +431,89 @@ func TestService_ViewAllJobs(t *testing.T) {
 
 func TestService_ViewJob(t *testing.T) {
 	type args struct {
-		ctx context.Context
-		cid uint64
+		ctx    context.Context
+		claims auth.Claims
+		cid    uint64
+		page   models.PageQuery
 	}
 	tests := []struct {
 		name             string
 		args             args
-		want             []models.Jobs
+		want             models.Page[models.JobResponse]
 		wantErr          bool
-		mockRepoResponse func() ([]models.Jobs, error)
+		mockRepoResponse func() ([]models.Jobs, int64, error)
 	}{
 		{
 			name: "error in database",
 			args: args{
-				ctx: context.Background(),
-				cid: 1,
+				ctx:    context.Background(),
+				claims: auth.Claims{RegisteredClaims: jwt.RegisteredClaims{Subject: "2"}, Role: models.RoleCandidate},
+				cid:    1,
+				page:   models.PageQuery{Limit: 20},
 			},
-			want:    nil,
+			want:    models.Page[models.JobResponse]{},
 			wantErr: true,
-			mockRepoResponse: func() ([]models.Jobs, error) {
-				return nil, errors.New("could not view the jobs")
+			mockRepoResponse: func() ([]models.Jobs, int64, error) {
+				return nil, 0, errors.New("could not view the jobs")
+			},
+		},
+		{
+			name: "no jobs for the company",
+			args: args{
+				ctx:    context.Background(),
+				claims: auth.Claims{RegisteredClaims: jwt.RegisteredClaims{Subject: "2"}, Role: models.RoleCandidate},
+				cid:    1,
+				page:   models.PageQuery{Limit: 20},
+			},
+			want: models.Page[models.JobResponse]{
+				Items: []models.JobResponse{},
+			},
+			wantErr: false,
+			mockRepoResponse: func() ([]models.Jobs, int64, error) {
+				return nil, 0, nil
 			},
 		},
 		{
 			name: "success from database",
 			args: args{
-				ctx: context.Background(),
-				cid: 1,
-			},
-			want: []models.Jobs{
-				{
-					Cid:          1,
-					Name:         "Junior web developer",
-					Salary:       "100000",
-					NoticePeriod: "30",
-				},
-				{
-					Cid:          1,
-					Name:         "Senior web developer",
-					Salary:       "200000",
-					NoticePeriod: "10",
+				ctx:    context.Background(),
+				claims: auth.Claims{RegisteredClaims: jwt.RegisteredClaims{Subject: "2"}, Role: models.RoleCandidate},
+				cid:    1,
+				page:   models.PageQuery{Limit: 20},
+			},
+			want: models.Page[models.JobResponse]{
+				Items: []models.JobResponse{
+					{
+						Cid:              1,
+						Name:             "Junior web developer",
+						Compensation:     models.Compensation{MinAmount: 100000, MaxAmount: 100000, Currency: "INR", PayPeriod: models.PayYearly},
+						NoticePeriodDays: 30,
+					},
+					{
+						Cid:              1,
+						Name:             "Senior web developer",
+						Compensation:     models.Compensation{MinAmount: 200000, MaxAmount: 200000, Currency: "INR", PayPeriod: models.PayYearly},
+						NoticePeriodDays: 10,
+					},
 				},
+				Total: 2,
 			},
 			wantErr: false,
-			mockRepoResponse: func() ([]models.Jobs, error) {
+			mockRepoResponse: func() ([]models.Jobs, int64, error) {
 				return []models.Jobs{
 					{
-						Cid:          1,
-						Name:         "Junior web developer",
-						Salary:       "100000",
-						NoticePeriod: "30",
+						Cid:              1,
+						Name:             "Junior web developer",
+						Compensation:     models.Compensation{MinAmount: 100000, MaxAmount: 100000, Currency: "INR", PayPeriod: models.PayYearly},
+						NoticePeriodDays: 30,
 					},
 					{
-						Cid:          1,
-						Name:         "Senior web developer",
-						Salary:       "200000",
-						NoticePeriod: "10",
+						Cid:              1,
+						Name:             "Senior web developer",
+						Compensation:     models.Compensation{MinAmount: 200000, MaxAmount: 200000, Currency: "INR", PayPeriod: models.PayYearly},
+						NoticePeriodDays: 10,
 					},
-				}, nil
+				}, 2, nil
 			},
 		},
 	}
@@ -313,14 +521,16 @@ func TestService_ViewJob(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			mc := gomock.NewController(t)
 			mockRepo := repository.NewMockUserRepo(mc)
-			mockRepo.EXPECT().FindJob(tt.args.ctx, tt.args.cid).Return(tt.mockRepoResponse()).AnyTimes()
+			mockRepo.EXPECT().FindCompanyMember(tt.args.ctx, tt.args.cid, uint64(2)).Return(models.CompanyMember{}, nil).AnyTimes()
+			filter := models.JobFilter{Cid: tt.args.cid, OpenOnly: true}
+			mockRepo.EXPECT().FindAllJobs(tt.args.ctx, filter, tt.args.page).Return(tt.mockRepoResponse()).Times(1)
 
 			svc, err := NewService(mockRepo, &auth.Auth{})
 			if err != nil {
 				t.Errorf("error is initializing the repo layer")
 				return
 			}
-			got, err := svc.ViewJob(tt.args.ctx, tt.args.cid)
+			got, err := svc.ViewJob(tt.args.ctx, tt.args.claims, tt.args.cid, tt.args.page)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("Service.ViewJob() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -331,3 +541,47 @@ func TestService_ViewJob(t *testing.T) {
 		})
 	}
 }
+
+func TestService_DeleteJob(t *testing.T) {
+	tests := []struct {
+		name     string
+		uid      string
+		status   string
+		wantKind apperr.Kind
+	}{
+		{name: "draft job of another company", uid: "2", status: models.JobDraft, wantKind: apperr.KindNotFound},
+		{name: "open job of another company", uid: "2", status: models.JobOpen, wantKind: apperr.KindForbidden},
+		{name: "draft job seen by a viewer of the company", uid: "3", status: models.JobDraft, wantKind: apperr.KindForbidden},
+		{name: "recruiter of the company", uid: "1", status: models.JobDraft},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mc := gomock.NewController(t)
+			mockRepo := repository.NewMockUserRepo(mc)
+			mockRepo.EXPECT().ViewJobDetailsBy(gomock.Any(), uint64(15)).Return(models.Jobs{Model: gorm.Model{ID: 15}, Cid: 1, Status: tt.status}, nil)
+			// user 1 recruits for company 1 and user 3 only views it
+			mockRepo.EXPECT().FindCompanyMember(gomock.Any(), uint64(1), gomock.Any()).DoAndReturn(func(ctx context.Context, cid uint64, uid uint64) (models.CompanyMember, error) {
+				switch uid {
+				case 1:
+					return models.CompanyMember{Model: gorm.Model{ID: 1}, Cid: 1, Uid: 1, Role: models.MemberRecruiter}, nil
+				case 3:
+					return models.CompanyMember{Model: gorm.Model{ID: 3}, Cid: 1, Uid: 3, Role: models.MemberViewer}, nil
+				}
+				return models.CompanyMember{}, nil
+			}).AnyTimes()
+			if tt.wantKind == apperr.KindInternal {
+				mockRepo.EXPECT().DeleteJob(gomock.Any(), uint64(15)).Return(nil)
+			}
+
+			s, _ := NewService(mockRepo, &auth.Auth{})
+			claims := auth.Claims{RegisteredClaims: jwt.RegisteredClaims{Subject: tt.uid}, Role: models.RoleRecruiter}
+			err := s.DeleteJob(context.Background(), claims, 15)
+			if tt.wantKind == apperr.KindInternal && err != nil {
+				t.Fatalf("Service.DeleteJob() error = %v", err)
+			}
+			if tt.wantKind != apperr.KindInternal && apperr.KindOf(err) != tt.wantKind {
+				t.Errorf("Service.DeleteJob() error = %v, want kind %v", err, tt.wantKind)
+			}
+		})
+	}
+}
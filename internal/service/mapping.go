@@ -0,0 +1,118 @@
+package service
+
+import "github.com/afthaab/job-portal/internal/models"
+
+// the request and response types of the api are mapped to the gorm models here, so a new column
+// does not show up in a response and a client can not set ids or timestamps
+
+func newUserResponse(user models.User) models.UserResponse {
+	return models.UserResponse{
+		ID:            user.ID,
+		Username:      user.Username,
+		Email:         user.Email,
+		Role:          user.Role,
+		EmailVerified: user.EmailVerified(),
+		FullName:      user.FullName,
+		Headline:      user.Headline,
+		Location:      user.Location,
+		CreatedAt:     user.CreatedAt,
+	}
+}
+
+func newCompany(companyData models.NewCompany) models.Company {
+	return models.Company{
+		Name:     companyData.Name,
+		Location: companyData.Location,
+		Field:    companyData.Field,
+	}
+}
+
+func newCompanyResponse(company models.Company) models.CompanyResponse {
+	return models.CompanyResponse{
+		ID:        company.ID,
+		Name:      company.Name,
+		Location:  company.Location,
+		Field:     company.Field,
+		CreatedAt: company.CreatedAt,
+		UpdatedAt: company.UpdatedAt,
+	}
+}
+
+func newJob(jobData models.NewJob) models.Jobs {
+	return models.Jobs{
+		Name:             jobData.Name,
+		Compensation:     jobData.Compensation,
+		NoticePeriodDays: jobData.NoticePeriodDays,
+		Description:      jobData.Description,
+		Status:           jobData.Status,
+	}
+}
+
+func newJobResponse(job models.Jobs) models.JobResponse {
+	return models.JobResponse{
+		ID:               job.ID,
+		Cid:              job.Cid,
+		Name:             job.Name,
+		Compensation:     job.Compensation,
+		NoticePeriodDays: job.NoticePeriodDays,
+		Description:      job.Description,
+		Status:           job.Status,
+		NeedsReview:      job.NeedsReview,
+		CreatedAt:        job.CreatedAt,
+		UpdatedAt:        job.UpdatedAt,
+	}
+}
+
+func newJobSearchResponse(result models.JobSearchResult) models.JobSearchResponse {
+	return models.JobSearchResponse{
+		Job:        newJobResponse(result.Job),
+		Rank:       result.Rank,
+		Highlights: result.Highlights,
+	}
+}
+
+func newMemberResponse(member models.CompanyMember) models.MemberResponse {
+	return models.MemberResponse{
+		Cid:       member.Cid,
+		Uid:       member.Uid,
+		Role:      member.Role,
+		CreatedAt: member.CreatedAt,
+	}
+}
+
+func newInvitationResponse(invitation models.CompanyInvitation) models.InvitationResponse {
+	return models.InvitationResponse{
+		ID:         invitation.ID,
+		Cid:        invitation.Cid,
+		Email:      invitation.Email,
+		Role:       invitation.Role,
+		InvitedBy:  invitation.InvitedBy,
+		ExpiresAt:  invitation.ExpiresAt,
+		AcceptedAt: invitation.AcceptedAt,
+		CreatedAt:  invitation.CreatedAt,
+	}
+}
+
+func newApplicationResponse(application models.Application) models.ApplicationResponse {
+	return models.ApplicationResponse{
+		ID:          application.ID,
+		Uid:         application.Uid,
+		Jid:         application.Jid,
+		CoverLetter: application.CoverLetter,
+		Status:      application.Status,
+		CreatedAt:   application.CreatedAt,
+		UpdatedAt:   application.UpdatedAt,
+	}
+}
+
+// mapItems maps every row of a list to its response type
+func mapItems[T any, R any](items []T, f func(T) R) []R {
+	if items == nil {
+		return nil
+	}
+	mapped := make([]R, 0, len(items))
+	for _, item := range items {
+		mapped = append(mapped, f(item))
+	}
+	return mapped
+}
@@ -0,0 +1,218 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/afthaab/job-portal/internal/apperr"
+	"github.com/afthaab/job-portal/internal/auth"
+	"github.com/afthaab/job-portal/internal/mail"
+	"github.com/afthaab/job-portal/internal/models"
+)
+
+// invitations are valid for a week
+const invitationTTL = 7 * 24 * time.Hour
+
+var errNotCompanyMember = apperr.Forbidden("you are not allowed to manage this company")
+
+// claimsUserID returns the id of the signed in user from the subject of the token
+func claimsUserID(claims auth.Claims) (uint64, error) {
+	uid, err := strconv.ParseUint(claims.Subject, 10, 64)
+	if err != nil {
+		return 0, apperr.Unauthorized("invalid user in the token")
+	}
+	return uid, nil
+}
+
+// requireMember checks that the user has one of the roles in the company, admins can manage every company
+func (s *Service) requireMember(ctx context.Context, claims auth.Claims, cid uint64, roles ...string) error {
+	if claims.Role == models.RoleAdmin {
+		return nil
+	}
+	uid, err := claimsUserID(claims)
+	if err != nil {
+		return err
+	}
+	memberData, err := s.UserRepo.FindCompanyMember(ctx, cid, uid)
+	if err != nil {
+		return err
+	}
+	for _, role := range roles {
+		if memberData.ID != 0 && memberData.Role == role {
+			return nil
+		}
+	}
+	return errNotCompanyMember
+}
+
+// memberOf returns the companies whose hidden jobs the user can see, ok is false for admins who see every job
+func (s *Service) memberOf(ctx context.Context, claims auth.Claims) (cids []uint, ok bool, err error) {
+	if claims.Role == models.RoleAdmin {
+		return nil, false, nil
+	}
+	uid, err := claimsUserID(claims)
+	if err != nil {
+		return nil, false, err
+	}
+	cids, err = s.UserRepo.FindMemberCompanies(ctx, uid)
+	if err != nil {
+		return nil, false, err
+	}
+	return cids, true, nil
+}
+
+func (s *Service) InviteMember(ctx context.Context, claims auth.Claims, cid uint64, invitation models.NewInvitation) (models.InvitationResponse, error) {
+	err := s.requireMember(ctx, claims, cid, models.MemberOwner)
+	if err != nil {
+		return models.InvitationResponse{}, err
+	}
+	uid, err := claimsUserID(claims)
+	if err != nil {
+		return models.InvitationResponse{}, err
+	}
+	companyData, err := s.UserRepo.ViewCompanyById(ctx, cid)
+	if err != nil {
+		return models.InvitationResponse{}, err
+	}
+
+	invitationData, err := s.UserRepo.CreateInvitation(ctx, models.CompanyInvitation{
+		Cid:       uint(cid),
+		Email:     strings.ToLower(strings.TrimSpace(invitation.Email)),
+		Role:      invitation.Role,
+		InvitedBy: uint(uid),
+		ExpiresAt: time.Now().Add(invitationTTL),
+	})
+	if err != nil {
+		return models.InvitationResponse{}, err
+	}
+
+	// like a change of email a failure is reported, the owner invites again and the invitee gets the newest one
+	err = s.mailer.Send(ctx, mail.Message{
+		To:      invitationData.Email,
+		Subject: fmt.Sprintf("You are invited to join %s", companyData.Name),
+		Body: fmt.Sprintf("Hi,\n\nyou are invited to join %s on the job portal as %s.\n\n"+
+			"Sign in or sign up with this email address and accept the invitation:\n\n%s\n\n"+
+			"This expires in %s. If you do not know the company you can ignore this mail.\n",
+			companyData.Name, invitationData.Role, invitationLink(s.invitationURL, invitationData.ID), invitationTTL),
+	})
+	if err != nil {
+		return models.InvitationResponse{}, err
+	}
+	return newInvitationResponse(invitationData), nil
+}
+
+// invitationLink is the page that accepts the invitation with its id as the invitation query parameter,
+// without a page the mail tells where to find the invitation once signed in
+func invitationLink(page string, iid uint) string {
+	id := strconv.FormatUint(uint64(iid), 10)
+	if page == "" {
+		return "it is invitation " + id + " among the invitations of your account"
+	}
+	u, err := url.Parse(page)
+	if err != nil {
+		return "it is invitation " + id + " among the invitations of your account"
+	}
+	q := u.Query()
+	q.Set("invitation", id)
+	u.RawQuery = q.Encode()
+	return u.String()
+}
+
+func (s *Service) ViewCompanyMembers(ctx context.Context, claims auth.Claims, cid uint64, page models.PageQuery) (models.Page[models.MemberResponse], error) {
+	err := s.requireMember(ctx, claims, cid, models.MemberOwner, models.MemberRecruiter, models.MemberViewer)
+	if err != nil {
+		return models.Page[models.MemberResponse]{}, err
+	}
+	memberDatas, total, err := s.UserRepo.FindCompanyMembers(ctx, cid, page)
+	if err != nil {
+		return models.Page[models.MemberResponse]{}, err
+	}
+	return newPage(mapItems(memberDatas, newMemberResponse), total, page), nil
+}
+
+// RemoveMember takes the user out of the company, owners can remove anyone and every member can leave
+func (s *Service) RemoveMember(ctx context.Context, claims auth.Claims, cid uint64, uid uint64) error {
+	self, err := claimsUserID(claims)
+	if err != nil {
+		return err
+	}
+	if self != uid {
+		err = s.requireMember(ctx, claims, cid, models.MemberOwner)
+		if err != nil {
+			return err
+		}
+	}
+	return s.UserRepo.DeleteCompanyMember(ctx, cid, uid)
+}
+
+func (s *Service) ViewMyInvitations(ctx context.Context, claims auth.Claims) ([]models.InvitationResponse, error) {
+	userDetails, err := s.signedInUser(ctx, claims)
+	if err != nil {
+		return nil, err
+	}
+	invitationDatas, err := s.UserRepo.FindPendingInvitations(ctx, strings.ToLower(userDetails.Email))
+	if err != nil {
+		return nil, err
+	}
+	if invitationDatas == nil {
+		return []models.InvitationResponse{}, nil
+	}
+	return mapItems(invitationDatas, newInvitationResponse), nil
+}
+
+// AcceptInvitation adds the signed in user to the company, a candidate the invitation made a recruiter gets
+// a new access token right away so the recruiter routes do not wait for the next refresh
+func (s *Service) AcceptInvitation(ctx context.Context, claims auth.Claims, iid uint64) (models.AcceptedInvitation, error) {
+	userDetails, err := s.signedInUser(ctx, claims)
+	if err != nil {
+		return models.AcceptedInvitation{}, err
+	}
+	invitationData, err := s.UserRepo.FindInvitation(ctx, iid)
+	if err != nil {
+		return models.AcceptedInvitation{}, err
+	}
+
+	// the invitation is bound to the email it was sent to
+	if invitationData.Email != strings.ToLower(userDetails.Email) {
+		return models.AcceptedInvitation{}, apperr.NotFound("could not find the invitation")
+	}
+	if invitationData.AcceptedAt != nil {
+		return models.AcceptedInvitation{}, apperr.Conflict("invitation was already accepted")
+	}
+	if time.Now().After(invitationData.ExpiresAt) {
+		return models.AcceptedInvitation{}, apperr.Conflict("invitation has expired")
+	}
+
+	memberData, err := s.UserRepo.AcceptInvitation(ctx, invitationData, models.CompanyMember{
+		Cid:  invitationData.Cid,
+		Uid:  userDetails.ID,
+		Role: invitationData.Role,
+	})
+	if err != nil {
+		return models.AcceptedInvitation{}, err
+	}
+	accepted := models.AcceptedInvitation{Member: newMemberResponse(memberData)}
+
+	// the repository promoted the candidate the same way
+	if userDetails.Role == models.RoleCandidate && memberData.Role != models.MemberViewer {
+		userDetails.Role = models.RoleRecruiter
+		accepted.Token, err = s.generateAccessToken(userDetails)
+		if err != nil {
+			return models.AcceptedInvitation{}, err
+		}
+		accepted.ExpiresIn = int64(s.accessTokenTTL.Seconds())
+	}
+	return accepted, nil
+}
+
+func (s *Service) signedInUser(ctx context.Context, claims auth.Claims) (models.User, error) {
+	uid, err := claimsUserID(claims)
+	if err != nil {
+		return models.User{}, err
+	}
+	return s.UserRepo.FindUserById(ctx, uid)
+}
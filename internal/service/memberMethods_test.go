@@ -0,0 +1,231 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/afthaab/job-portal/internal/auth"
+	mockauth "github.com/afthaab/job-portal/internal/auth/mockModels"
+	"github.com/afthaab/job-portal/internal/mail"
+	"github.com/afthaab/job-portal/internal/models"
+	"github.com/afthaab/job-portal/internal/repository"
+	"github.com/golang-jwt/jwt/v5"
+	"go.uber.org/mock/gomock"
+	"gorm.io/gorm"
+)
+
+func TestService_InviteMember(t *testing.T) {
+	owner := auth.Claims{RegisteredClaims: jwt.RegisteredClaims{Subject: "1"}, Role: models.RoleRecruiter}
+	tests := []struct {
+		name       string
+		member     models.CompanyMember
+		mailFails  bool
+		wantErr    bool
+		wantMailed bool
+	}{
+		{
+			name:    "recruiters cannot invite",
+			member:  models.CompanyMember{Model: gorm.Model{ID: 1}, Cid: 1, Uid: 1, Role: models.MemberRecruiter},
+			wantErr: true,
+		},
+		{
+			name:       "owner invites by email",
+			member:     models.CompanyMember{Model: gorm.Model{ID: 1}, Cid: 1, Uid: 1, Role: models.MemberOwner},
+			wantMailed: true,
+		},
+		{
+			name:      "invitation mail not sent",
+			member:    models.CompanyMember{Model: gorm.Model{ID: 1}, Cid: 1, Uid: 1, Role: models.MemberOwner},
+			mailFails: true,
+			wantErr:   true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mc := gomock.NewController(t)
+			mockRepo := repository.NewMockUserRepo(mc)
+			mockRepo.EXPECT().FindCompanyMember(gomock.Any(), uint64(1), uint64(1)).Return(tt.member, nil)
+			if tt.member.Role == models.MemberOwner {
+				mockRepo.EXPECT().ViewCompanyById(gomock.Any(), uint64(1)).Return(models.Company{Model: gorm.Model{ID: 1}, Name: "tek"}, nil)
+				mockRepo.EXPECT().CreateInvitation(gomock.Any(), gomock.Cond(func(x any) bool {
+					invitation, ok := x.(models.CompanyInvitation)
+					return ok && invitation.Email == "recruiter@example.com" && invitation.Cid == 1 &&
+						invitation.Role == models.MemberRecruiter && invitation.InvitedBy == 1 && invitation.ExpiresAt.After(time.Now())
+				})).DoAndReturn(func(ctx context.Context, invitation models.CompanyInvitation) (models.CompanyInvitation, error) {
+					invitation.ID = 5
+					return invitation, nil
+				})
+			}
+
+			dir := t.TempDir()
+			mailer, err := mail.NewFileMailer(dir, "no-reply@localhost")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tt.mailFails {
+				// the mailer can not write once its directory is gone
+				os.RemoveAll(dir)
+			}
+			svc, err := NewService(mockRepo, &auth.Auth{}, WithMailer(mailer), WithInvitationURL("https://jobs.example.com/invitations"))
+			if err != nil {
+				t.Errorf("error is initializing the repo layer")
+				return
+			}
+			_, err = svc.InviteMember(context.Background(), owner, 1, models.NewInvitation{Email: " Recruiter@Example.com", Role: models.MemberRecruiter})
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Service.InviteMember() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			files, _ := filepath.Glob(filepath.Join(dir, "*.eml"))
+			if (len(files) == 1) != tt.wantMailed {
+				t.Fatalf("Service.InviteMember() sent %d mails, want mail %v", len(files), tt.wantMailed)
+			}
+			if tt.wantMailed {
+				data, _ := os.ReadFile(files[0])
+				if !strings.Contains(string(data), "To: recruiter@example.com") ||
+					!strings.Contains(string(data), "https://jobs.example.com/invitations?invitation=5") {
+					t.Errorf("Service.InviteMember() mailed %q, want the accept link sent to the invitee", data)
+				}
+			}
+		})
+	}
+}
+
+func TestService_AcceptInvitation(t *testing.T) {
+	accepted := time.Now()
+	user := models.User{Model: gorm.Model{ID: 2}, Email: "Recruiter@example.com", Role: models.RoleCandidate}
+	claims := auth.Claims{RegisteredClaims: jwt.RegisteredClaims{Subject: "2"}, Role: models.RoleCandidate}
+
+	tests := []struct {
+		name         string
+		invitation   models.CompanyInvitation
+		want         models.AcceptedInvitation
+		wantErr      bool
+		mockResponse func() (models.CompanyMember, error)
+	}{
+		{
+			name:       "invitation for another email",
+			invitation: models.CompanyInvitation{Model: gorm.Model{ID: 5}, Cid: 1, Email: "someone@example.com", Role: models.MemberRecruiter, ExpiresAt: time.Now().Add(time.Hour)},
+			wantErr:    true,
+		},
+		{
+			name:       "invitation already accepted",
+			invitation: models.CompanyInvitation{Model: gorm.Model{ID: 5}, Cid: 1, Email: "recruiter@example.com", Role: models.MemberRecruiter, ExpiresAt: time.Now().Add(time.Hour), AcceptedAt: &accepted},
+			wantErr:    true,
+		},
+		{
+			name:       "invitation expired",
+			invitation: models.CompanyInvitation{Model: gorm.Model{ID: 5}, Cid: 1, Email: "recruiter@example.com", Role: models.MemberRecruiter, ExpiresAt: time.Now().Add(-time.Hour)},
+			wantErr:    true,
+		},
+		{
+			name:       "error from the database",
+			invitation: models.CompanyInvitation{Model: gorm.Model{ID: 5}, Cid: 1, Email: "recruiter@example.com", Role: models.MemberRecruiter, ExpiresAt: time.Now().Add(time.Hour)},
+			wantErr:    true,
+			mockResponse: func() (models.CompanyMember, error) {
+				return models.CompanyMember{}, errors.New("could not accept the invitation")
+			},
+		},
+		{
+			name:       "candidate made a recruiter gets a new token",
+			invitation: models.CompanyInvitation{Model: gorm.Model{ID: 5}, Cid: 1, Email: "recruiter@example.com", Role: models.MemberRecruiter, ExpiresAt: time.Now().Add(time.Hour)},
+			want: models.AcceptedInvitation{
+				Member:    models.MemberResponse{Cid: 1, Uid: 2, Role: models.MemberRecruiter},
+				Token:     "recruiter token",
+				ExpiresIn: 900,
+			},
+			mockResponse: func() (models.CompanyMember, error) {
+				return models.CompanyMember{Cid: 1, Uid: 2, Role: models.MemberRecruiter}, nil
+			},
+		},
+		{
+			name:       "viewer keeps the token",
+			invitation: models.CompanyInvitation{Model: gorm.Model{ID: 5}, Cid: 1, Email: "recruiter@example.com", Role: models.MemberViewer, ExpiresAt: time.Now().Add(time.Hour)},
+			want:       models.AcceptedInvitation{Member: models.MemberResponse{Cid: 1, Uid: 2, Role: models.MemberViewer}},
+			mockResponse: func() (models.CompanyMember, error) {
+				return models.CompanyMember{Cid: 1, Uid: 2, Role: models.MemberViewer}, nil
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mc := gomock.NewController(t)
+			mockRepo := repository.NewMockUserRepo(mc)
+			mockRepo.EXPECT().FindUserById(gomock.Any(), uint64(2)).Return(user, nil)
+			mockRepo.EXPECT().FindInvitation(gomock.Any(), uint64(5)).Return(tt.invitation, nil)
+			if tt.mockResponse != nil {
+				mockRepo.EXPECT().AcceptInvitation(gomock.Any(), tt.invitation, models.CompanyMember{Cid: 1, Uid: 2, Role: tt.invitation.Role}).Return(tt.mockResponse())
+			}
+			mockAuth := mockauth.NewMockAuthentication(mc)
+			if tt.want.Token != "" {
+				mockAuth.EXPECT().GenerateAuthToken(gomock.Cond(func(x any) bool {
+					c, ok := x.(auth.Claims)
+					return ok && c.Subject == "2" && c.Role == models.RoleRecruiter
+				})).Return(tt.want.Token, nil)
+			}
+
+			svc, err := NewService(mockRepo, mockAuth)
+			if err != nil {
+				t.Errorf("error is initializing the repo layer")
+				return
+			}
+			got, err := svc.AcceptInvitation(context.Background(), claims, 5)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Service.AcceptInvitation() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Service.AcceptInvitation() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestService_RemoveMember(t *testing.T) {
+	owner := auth.Claims{RegisteredClaims: jwt.RegisteredClaims{Subject: "1"}, Role: models.RoleRecruiter}
+	tests := []struct {
+		name      string
+		uid       uint64
+		setupMock func(m *repository.MockUserRepo)
+		wantErr   error
+	}{
+		{
+			name: "only owner leaving",
+			uid:  1,
+			setupMock: func(m *repository.MockUserRepo) {
+				m.EXPECT().DeleteCompanyMember(gomock.Any(), uint64(1), uint64(1)).Return(repository.ErrLastOwner)
+			},
+			wantErr: repository.ErrLastOwner,
+		},
+		{
+			name: "owner removing a member",
+			uid:  2,
+			setupMock: func(m *repository.MockUserRepo) {
+				m.EXPECT().FindCompanyMember(gomock.Any(), uint64(1), uint64(1)).Return(models.CompanyMember{Model: gorm.Model{ID: 1}, Cid: 1, Uid: 1, Role: models.MemberOwner}, nil)
+				m.EXPECT().DeleteCompanyMember(gomock.Any(), uint64(1), uint64(2)).Return(nil)
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mc := gomock.NewController(t)
+			mockRepo := repository.NewMockUserRepo(mc)
+			tt.setupMock(mockRepo)
+
+			svc, err := NewService(mockRepo, &auth.Auth{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			err = svc.RemoveMember(context.Background(), owner, 1, tt.uid)
+			if !errors.Is(err, tt.wantErr) && err != tt.wantErr {
+				t.Errorf("Service.RemoveMember() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,22 @@
+package service
+
+import (
+	"github.com/afthaab/job-portal/internal/models"
+	"github.com/afthaab/job-portal/internal/pkg"
+)
+
+// newPage wraps the rows of a list into the response envelope, the next cursor is only set when rows are left
+func newPage[T any](items []T, total int64, page models.PageQuery) models.Page[T] {
+	if items == nil {
+		items = []T{}
+	}
+	result := models.Page[T]{
+		Items: items,
+		Total: total,
+	}
+	next := page.Offset + len(items)
+	if len(items) > 0 && int64(next) < total {
+		result.NextCursor = pkg.EncodeCursor(next)
+	}
+	return result
+}
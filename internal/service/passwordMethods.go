@@ -0,0 +1,147 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/afthaab/job-portal/internal/apperr"
+	"github.com/afthaab/job-portal/internal/mail"
+	"github.com/afthaab/job-portal/internal/models"
+	"github.com/afthaab/job-portal/internal/repository"
+	"github.com/rs/zerolog/log"
+)
+
+var errInvalidResetToken = apperr.Validation("invalid or expired password reset token")
+
+// ForgotPassword mails a password reset link and makes the links sent before useless.
+// It answers the same whether or not the email belongs to an account, the link is stored and mailed
+// after the answer so a known email does not take longer to answer than an unknown one
+func (s *Service) ForgotPassword(ctx context.Context, email string) error {
+	userDetails, err := s.UserRepo.CheckEmail(ctx, email)
+	if errors.Is(err, apperr.ErrNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	s.goBackground("sending the password reset mail", func(ctx context.Context) error {
+		return s.sendPasswordReset(ctx, userDetails)
+	})
+	return nil
+}
+
+func (s *Service) sendPasswordReset(ctx context.Context, userDetails models.User) error {
+	err := s.UserRepo.RevokeUserTokens(ctx, uint64(userDetails.ID), models.TokenPurposeResetPassword)
+	if err != nil {
+		return err
+	}
+	token, err := newOpaqueToken()
+	if err != nil {
+		return err
+	}
+	_, err = s.UserRepo.CreateUserToken(ctx, models.UserToken{
+		Uid:       userDetails.ID,
+		Purpose:   models.TokenPurposeResetPassword,
+		TokenHash: hashToken(token),
+		Email:     userDetails.Email,
+		ExpiresAt: time.Now().Add(s.passwordReset.TTL),
+	})
+	if err != nil {
+		return err
+	}
+
+	return s.mailer.Send(ctx, mail.Message{
+		To:      userDetails.Email,
+		Subject: "Reset your password",
+		Body: fmt.Sprintf("Hi %s,\n\nsomeone asked to reset the password of your job portal account, to choose a new one use:\n\n%s\n\n"+
+			"This expires in %s and works once. If it was not you, ignore this mail and your password stays the same.\n",
+			userDetails.Username, tokenLink(s.passwordReset.URL, token), s.passwordReset.TTL),
+	})
+}
+
+// ResetPassword sets a new password with a mailed token and signs the user out everywhere,
+// the refresh tokens are revoked and so are the access tokens issued until now
+func (s *Service) ResetPassword(ctx context.Context, token string, password string) error {
+	// the token is only used up once the new password passed the policy, a rejected password can be retried
+	tokenData, err := s.UserRepo.FindUserToken(ctx, models.TokenPurposeResetPassword, hashToken(token))
+	if errors.Is(err, apperr.ErrNotFound) {
+		return errInvalidResetToken
+	}
+	if err != nil {
+		return err
+	}
+	if tokenData.UsedAt != nil || time.Now().After(tokenData.ExpiresAt) {
+		return errInvalidResetToken
+	}
+
+	uid := uint64(tokenData.Uid)
+	userDetails, err := s.UserRepo.FindUserById(ctx, uid)
+	if errors.Is(err, apperr.ErrNotFound) {
+		return errInvalidResetToken
+	}
+	if err != nil {
+		return err
+	}
+	// a link sent to an address the account no longer has does not reset it
+	if userDetails.Email != tokenData.Email {
+		return errInvalidResetToken
+	}
+
+	err = s.passwordPolicy.Check(password, userDetails.Username, userDetails.Email)
+	if err != nil {
+		return err
+	}
+	hashedPass, err := s.hasher.Hash(password)
+	if err != nil {
+		return err
+	}
+
+	// two requests racing with the same token can not both get past this
+	_, err = s.UserRepo.UseUserToken(ctx, models.TokenPurposeResetPassword, tokenData.TokenHash)
+	if errors.Is(err, apperr.ErrNotFound) || errors.Is(err, repository.ErrUserTokenUsed) {
+		return errInvalidResetToken
+	}
+	if err != nil {
+		return err
+	}
+
+	changedAt := time.Now()
+	err = s.UserRepo.UpdateUserPassword(ctx, uid, hashedPass, changedAt)
+	if err != nil {
+		return err
+	}
+	err = s.signOutEverywhere(ctx, userDetails, changedAt)
+	if err != nil {
+		return err
+	}
+
+	// following the mailed link proves the address too
+	err = s.UserRepo.VerifyUserEmail(ctx, uid, tokenData.Email)
+	if err != nil {
+		log.Error().Err(err).Uint64("uid", uid).Msg("error in verifying the email on password reset")
+	}
+	// the old failures were guesses at a password that is gone
+	err = s.lockout.Reset(ctx, lockoutKey(userDetails.Email))
+	if err != nil {
+		log.Error().Err(err).Msg("error in resetting the sign in lockout")
+	}
+	return nil
+}
+
+// signOutEverywhere revokes every refresh token of the user, the reset links still out
+// and every access token issued up to changedAt
+func (s *Service) signOutEverywhere(ctx context.Context, userDetails models.User, changedAt time.Time) error {
+	err := s.UserRepo.RevokeUserRefreshTokens(ctx, uint64(userDetails.ID))
+	if err != nil {
+		return err
+	}
+	err = s.UserRepo.RevokeUserTokens(ctx, uint64(userDetails.ID), models.TokenPurposeResetPassword)
+	if err != nil {
+		return err
+	}
+	s.auth.RevokeSubject(strconv.FormatUint(uint64(userDetails.ID), 10), changedAt, changedAt.Add(s.accessTokenTTL))
+	return nil
+}
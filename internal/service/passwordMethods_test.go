@@ -0,0 +1,197 @@
+package service
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/afthaab/job-portal/internal/apperr"
+	mockauth "github.com/afthaab/job-portal/internal/auth/mockModels"
+	"github.com/afthaab/job-portal/internal/mail"
+	"github.com/afthaab/job-portal/internal/models"
+	"github.com/afthaab/job-portal/internal/password"
+	"github.com/afthaab/job-portal/internal/repository"
+	"go.uber.org/mock/gomock"
+	"gorm.io/gorm"
+)
+
+func TestService_ForgotPassword(t *testing.T) {
+	user := models.User{Model: gorm.Model{ID: 1}, Username: "afthab", Email: "afthab606@gmail.com"}
+	tests := []struct {
+		name      string
+		setupMock func(m *repository.MockUserRepo)
+		wantMail  bool
+	}{
+		{
+			name: "unknown email",
+			setupMock: func(m *repository.MockUserRepo) {
+				m.EXPECT().CheckEmail(gomock.Any(), "afthab606@gmail.com").Return(models.User{}, apperr.NotFound("email not found"))
+			},
+		},
+		{
+			name: "link mailed",
+			setupMock: func(m *repository.MockUserRepo) {
+				m.EXPECT().CheckEmail(gomock.Any(), "afthab606@gmail.com").Return(user, nil)
+				revoke := m.EXPECT().RevokeUserTokens(gomock.Any(), uint64(1), models.TokenPurposeResetPassword).Return(nil)
+				m.EXPECT().CreateUserToken(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, token models.UserToken) (models.UserToken, error) {
+					if token.Purpose != models.TokenPurposeResetPassword || token.Email != user.Email || time.Until(token.ExpiresAt) > time.Hour {
+						t.Errorf("CreateUserToken() got %+v", token)
+					}
+					return token, nil
+				}).After(revoke)
+			},
+			wantMail: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mc := gomock.NewController(t)
+			mockRepo := repository.NewMockUserRepo(mc)
+			tt.setupMock(mockRepo)
+
+			dir := t.TempDir()
+			mailer, err := mail.NewFileMailer(dir, "no-reply@localhost")
+			if err != nil {
+				t.Fatal(err)
+			}
+			svc, err := NewService(mockRepo, nil, WithMailer(mailer))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			// both cases answer the same, only the mail tells them apart
+			err = svc.ForgotPassword(context.Background(), "afthab606@gmail.com")
+			if err != nil {
+				t.Fatalf("Service.ForgotPassword() error = %v", err)
+			}
+			// the mail goes out after the answer
+			err = svc.Wait(context.Background())
+			if err != nil {
+				t.Fatal(err)
+			}
+			files, _ := filepath.Glob(filepath.Join(dir, "*.eml"))
+			if (len(files) == 1) != tt.wantMail {
+				t.Errorf("Service.ForgotPassword() sent %d mails, want mail %v", len(files), tt.wantMail)
+			}
+			if tt.wantMail {
+				data, _ := os.ReadFile(files[0])
+				if len(data) == 0 {
+					t.Errorf("Service.ForgotPassword() sent an empty mail")
+				}
+			}
+		})
+	}
+}
+
+func TestService_ResetPassword(t *testing.T) {
+	user := models.User{Model: gorm.Model{ID: 1}, Username: "afthab", Email: "afthab606@gmail.com"}
+	usedAt := time.Now()
+	live := models.UserToken{Uid: 1, Email: "afthab606@gmail.com", TokenHash: hashToken("token"), ExpiresAt: time.Now().Add(time.Hour)}
+	used := live
+	used.UsedAt = &usedAt
+	find := func(m *repository.MockUserRepo, token models.UserToken) {
+		m.EXPECT().FindUserToken(gomock.Any(), models.TokenPurposeResetPassword, hashToken("token")).Return(token, nil)
+	}
+	tests := []struct {
+		name      string
+		password  string
+		setupMock func(m *repository.MockUserRepo, a *mockauth.MockAuthentication)
+		wantErr   error
+		wantKind  apperr.Kind
+	}{
+		{
+			name: "unknown token",
+			setupMock: func(m *repository.MockUserRepo, a *mockauth.MockAuthentication) {
+				m.EXPECT().FindUserToken(gomock.Any(), models.TokenPurposeResetPassword, hashToken("token")).Return(models.UserToken{}, apperr.NotFound("could not find the token"))
+			},
+			wantErr: errInvalidResetToken,
+		},
+		{
+			name: "token already used",
+			setupMock: func(m *repository.MockUserRepo, a *mockauth.MockAuthentication) {
+				find(m, used)
+			},
+			wantErr: errInvalidResetToken,
+		},
+		{
+			name: "token expired",
+			setupMock: func(m *repository.MockUserRepo, a *mockauth.MockAuthentication) {
+				find(m, models.UserToken{Uid: 1, ExpiresAt: time.Now().Add(-time.Second)})
+			},
+			wantErr: errInvalidResetToken,
+		},
+		{
+			name: "email changed since the link was sent",
+			setupMock: func(m *repository.MockUserRepo, a *mockauth.MockAuthentication) {
+				find(m, live)
+				m.EXPECT().FindUserById(gomock.Any(), uint64(1)).Return(models.User{Model: gorm.Model{ID: 1}, Email: "new@example.com"}, nil)
+			},
+			wantErr: errInvalidResetToken,
+		},
+		{
+			name:     "password breaks the policy and the token stays usable",
+			password: "afthab1234",
+			setupMock: func(m *repository.MockUserRepo, a *mockauth.MockAuthentication) {
+				find(m, live)
+				m.EXPECT().FindUserById(gomock.Any(), uint64(1)).Return(user, nil)
+			},
+			wantKind: apperr.KindValidation,
+		},
+		{
+			name: "another request used the token first",
+			setupMock: func(m *repository.MockUserRepo, a *mockauth.MockAuthentication) {
+				find(m, live)
+				m.EXPECT().FindUserById(gomock.Any(), uint64(1)).Return(user, nil)
+				m.EXPECT().UseUserToken(gomock.Any(), models.TokenPurposeResetPassword, hashToken("token")).Return(models.UserToken{}, repository.ErrUserTokenUsed)
+			},
+			wantErr: errInvalidResetToken,
+		},
+		{
+			name: "password reset and sessions revoked",
+			setupMock: func(m *repository.MockUserRepo, a *mockauth.MockAuthentication) {
+				find(m, live)
+				m.EXPECT().FindUserById(gomock.Any(), uint64(1)).Return(user, nil)
+				m.EXPECT().UseUserToken(gomock.Any(), models.TokenPurposeResetPassword, hashToken("token")).Return(used, nil)
+				m.EXPECT().UpdateUserPassword(gomock.Any(), uint64(1), gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, uid uint64, hash string, changedAt time.Time) error {
+					_, err := password.NewHasher(password.DefaultArgon2id).Verify("purple tractor rain", hash)
+					if err != nil {
+						t.Errorf("UpdateUserPassword() got a hash that does not match the new password: %v", err)
+					}
+					return nil
+				})
+				m.EXPECT().RevokeUserRefreshTokens(gomock.Any(), uint64(1)).Return(nil)
+				m.EXPECT().RevokeUserTokens(gomock.Any(), uint64(1), models.TokenPurposeResetPassword).Return(nil)
+				m.EXPECT().VerifyUserEmail(gomock.Any(), uint64(1), "afthab606@gmail.com").Return(nil)
+				a.EXPECT().RevokeSubject("1", gomock.Any(), gomock.Any())
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mc := gomock.NewController(t)
+			mockRepo := repository.NewMockUserRepo(mc)
+			mockAuth := mockauth.NewMockAuthentication(mc)
+			tt.setupMock(mockRepo, mockAuth)
+
+			svc, err := NewService(mockRepo, mockAuth)
+			if err != nil {
+				t.Fatal(err)
+			}
+			newPassword := tt.password
+			if newPassword == "" {
+				newPassword = "purple tractor rain"
+			}
+			err = svc.ResetPassword(context.Background(), "token", newPassword)
+			switch {
+			case tt.wantErr != nil && err != tt.wantErr:
+				t.Errorf("Service.ResetPassword() error = %v, want %v", err, tt.wantErr)
+			case tt.wantKind != apperr.KindInternal && apperr.KindOf(err) != tt.wantKind:
+				t.Errorf("Service.ResetPassword() error = %v, want kind %v", err, tt.wantKind)
+			case tt.wantErr == nil && tt.wantKind == apperr.KindInternal && err != nil:
+				t.Errorf("Service.ResetPassword() error = %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,272 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/afthaab/job-portal/internal/apperr"
+	"github.com/afthaab/job-portal/internal/auth"
+	"github.com/afthaab/job-portal/internal/mail"
+	"github.com/afthaab/job-portal/internal/models"
+	"github.com/afthaab/job-portal/internal/password"
+	"github.com/afthaab/job-portal/internal/repository"
+	"github.com/rs/zerolog/log"
+)
+
+// errWrongPassword is a validation error and not an unauthorized one so a client
+// does not take a mistyped current password for an expired session
+var errWrongPassword = apperr.Invalid("current password is incorrect", apperr.FieldError{
+	Field:   "current_password",
+	Code:    "incorrect",
+	Message: "current password is incorrect",
+})
+
+// the account of the signed in user, every method here resolves the user from the subject of the token
+
+func (s *Service) ViewProfile(ctx context.Context, claims auth.Claims) (models.UserResponse, error) {
+	userDetails, err := s.signedInUser(ctx, claims)
+	if err != nil {
+		return models.UserResponse{}, err
+	}
+	return newUserResponse(userDetails), nil
+}
+
+func (s *Service) UpdateProfile(ctx context.Context, claims auth.Claims, profile models.UpdateProfile) (models.UserResponse, error) {
+	if profile == (models.UpdateProfile{}) {
+		return models.UserResponse{}, apperr.Validation("nothing to update")
+	}
+	uid, err := claimsUserID(claims)
+	if err != nil {
+		return models.UserResponse{}, err
+	}
+	if profile.Username != nil {
+		username := strings.TrimSpace(*profile.Username)
+		if username == "" {
+			return models.UserResponse{}, apperr.Validation("username can not be blank")
+		}
+		profile.Username = &username
+	}
+
+	userDetails, err := s.UserRepo.UpdateUserProfile(ctx, uid, profile)
+	if errors.Is(err, apperr.ErrConflict) {
+		return models.UserResponse{}, apperr.Conflict("username is already taken")
+	}
+	if err != nil {
+		return models.UserResponse{}, err
+	}
+	return newUserResponse(userDetails), nil
+}
+
+// ChangePassword sets a new password and signs the user out everywhere like a reset does,
+// the client signs in again with the new password
+func (s *Service) ChangePassword(ctx context.Context, claims auth.Claims, passwords models.ChangePasswordRequest) error {
+	userDetails, err := s.signedInUser(ctx, claims)
+	if err != nil {
+		return err
+	}
+	err = s.confirmPassword(ctx, userDetails, passwords.CurrentPassword)
+	if err != nil {
+		return err
+	}
+	if passwords.NewPassword == passwords.CurrentPassword {
+		return apperr.Invalid("new password is the current one", apperr.FieldError{
+			Field:   "new_password",
+			Code:    "unchanged",
+			Message: "new password must differ from the current one",
+		})
+	}
+	err = s.passwordPolicy.Check(passwords.NewPassword, userDetails.Username, userDetails.Email)
+	if err != nil {
+		return err
+	}
+	hashedPass, err := s.hasher.Hash(passwords.NewPassword)
+	if err != nil {
+		return err
+	}
+
+	changedAt := time.Now()
+	err = s.UserRepo.UpdateUserPassword(ctx, uint64(userDetails.ID), hashedPass, changedAt)
+	if err != nil {
+		return err
+	}
+	err = s.signOutEverywhere(ctx, userDetails, changedAt)
+	if err != nil {
+		return err
+	}
+	s.notify(ctx, userDetails.Email, "Your password was changed", fmt.Sprintf(
+		"Hi %s,\n\nthe password of your job portal account was just changed and every session was signed out.\n\n"+
+			"If it was not you, reset your password right away.\n", userDetails.Username))
+	return nil
+}
+
+// ChangeEmail mails a link to the new address, the account keeps its current address until the link is followed
+// so a mistyped address can not lock the user out. VerifyEmail takes these links as well
+func (s *Service) ChangeEmail(ctx context.Context, claims auth.Claims, change models.ChangeEmailRequest) error {
+	userDetails, err := s.signedInUser(ctx, claims)
+	if err != nil {
+		return err
+	}
+	err = s.confirmPassword(ctx, userDetails, change.CurrentPassword)
+	if err != nil {
+		return err
+	}
+	email := strings.TrimSpace(change.Email)
+	if strings.EqualFold(email, userDetails.Email) {
+		return apperr.Validation("this is already the email of the account")
+	}
+	_, err = s.UserRepo.CheckEmail(ctx, email)
+	if err == nil {
+		return apperr.Conflict("email is already taken")
+	}
+	if !errors.Is(err, apperr.ErrNotFound) {
+		return err
+	}
+
+	// only the last address asked for can be confirmed
+	err = s.UserRepo.RevokeUserTokens(ctx, uint64(userDetails.ID), models.TokenPurposeChangeEmail)
+	if err != nil {
+		return err
+	}
+	token, err := newOpaqueToken()
+	if err != nil {
+		return err
+	}
+	_, err = s.UserRepo.CreateUserToken(ctx, models.UserToken{
+		Uid:       userDetails.ID,
+		Purpose:   models.TokenPurposeChangeEmail,
+		TokenHash: hashToken(token),
+		Email:     email,
+		ExpiresAt: time.Now().Add(s.verification.TTL),
+	})
+	if err != nil {
+		return err
+	}
+
+	// unlike the mail at signup nothing changed yet, so a failure is reported and the user asks again
+	return s.mailer.Send(ctx, mail.Message{
+		To:      email,
+		Subject: "Confirm your new email address",
+		Body: fmt.Sprintf("Hi %s,\n\nplease confirm this is the new email address of your job portal account:\n\n%s\n\n"+
+			"This expires in %s. If you did not ask for this you can ignore this mail.\n",
+			userDetails.Username, tokenLink(s.verification.URL, token), s.verification.TTL),
+	})
+}
+
+// confirmEmailChange moves the account to the address a change email token was sent to
+func (s *Service) confirmEmailChange(ctx context.Context, tokenHash string) (models.UserResponse, error) {
+	tokenData, err := s.UserRepo.UseUserToken(ctx, models.TokenPurposeChangeEmail, tokenHash)
+	if errors.Is(err, apperr.ErrNotFound) || errors.Is(err, repository.ErrUserTokenUsed) {
+		return models.UserResponse{}, errInvalidEmailToken
+	}
+	if err != nil {
+		return models.UserResponse{}, err
+	}
+	if time.Now().After(tokenData.ExpiresAt) {
+		return models.UserResponse{}, errInvalidEmailToken
+	}
+
+	uid := uint64(tokenData.Uid)
+	userDetails, err := s.UserRepo.FindUserById(ctx, uid)
+	if errors.Is(err, apperr.ErrNotFound) {
+		return models.UserResponse{}, errInvalidEmailToken
+	}
+	if err != nil {
+		return models.UserResponse{}, err
+	}
+
+	// the address may have been taken by a signup since the link was sent
+	err = s.UserRepo.ChangeUserEmail(ctx, uid, tokenData.Email)
+	if errors.Is(err, apperr.ErrConflict) {
+		return models.UserResponse{}, apperr.Conflict("email is already taken")
+	}
+	if err != nil {
+		return models.UserResponse{}, err
+	}
+	s.notify(ctx, userDetails.Email, "Your email address was changed", fmt.Sprintf(
+		"Hi %s,\n\nthe email address of your job portal account was changed to %s and this address will no longer get mail from us.\n",
+		userDetails.Username, tokenData.Email))
+
+	userDetails, err = s.UserRepo.FindUserById(ctx, uid)
+	if err != nil {
+		return models.UserResponse{}, err
+	}
+	return newUserResponse(userDetails), nil
+}
+
+// DeleteAccount erases the user and everything about the user for good and revokes the access tokens still out.
+// An account that is the only owner of a company is refused until the company has another owner or is deleted
+func (s *Service) DeleteAccount(ctx context.Context, claims auth.Claims, currentPassword string) error {
+	userDetails, err := s.signedInUser(ctx, claims)
+	if err != nil {
+		return err
+	}
+	err = s.confirmPassword(ctx, userDetails, currentPassword)
+	if err != nil {
+		return err
+	}
+
+	// the refresh tokens go with the account, the access tokens still out are refused from now on
+	// and the revocation is stored with the erasure so a restart loads it back
+	now := time.Now()
+	revoked := models.RevokedSubject{
+		Subject:      strconv.FormatUint(uint64(userDetails.ID), 10),
+		IssuedBefore: now,
+		ExpiresAt:    now.Add(s.accessTokenTTL),
+	}
+	err = s.UserRepo.EraseUser(ctx, uint64(userDetails.ID), revoked)
+	if err != nil {
+		return err
+	}
+	s.auth.RevokeSubject(revoked.Subject, revoked.IssuedBefore, revoked.ExpiresAt)
+	err = s.lockout.Reset(ctx, lockoutKey(userDetails.Email))
+	if err != nil {
+		log.Error().Err(err).Msg("error in resetting the sign in lockout")
+	}
+	return nil
+}
+
+// confirmPassword checks the current password before the account is changed, the failures count
+// towards the same lockout as sign in so a stolen session can not be used to guess the password
+func (s *Service) confirmPassword(ctx context.Context, userDetails models.User, plain string) error {
+	account := lockoutKey(userDetails.Email)
+	locked, err := s.lockout.LockedFor(ctx, account)
+	if err != nil {
+		log.Error().Err(err).Msg("error in reading the sign in lockout")
+	}
+	if locked > 0 {
+		return apperr.TooManyRequests("too many wrong passwords, try again later", locked)
+	}
+
+	_, err = s.hasher.Verify(plain, userDetails.PasswordHash)
+	if err != nil {
+		if !errors.Is(err, password.ErrMismatch) {
+			log.Error().Err(err).Uint("uid", userDetails.ID).Msg("error in checking the password")
+		}
+		lock, err := s.lockout.Fail(ctx, account)
+		if err != nil {
+			log.Error().Err(err).Msg("error in recording the wrong password")
+		}
+		if lock > 0 {
+			log.Warn().Dur("locked for", lock).Msg("account locked after repeated wrong passwords")
+		}
+		return errWrongPassword
+	}
+
+	err = s.lockout.Reset(ctx, account)
+	if err != nil {
+		log.Error().Err(err).Msg("error in resetting the sign in lockout")
+	}
+	return nil
+}
+
+// notify mails the user about a change to the account, the change stands even if the mail is not sent
+func (s *Service) notify(ctx context.Context, to string, subject string, body string) {
+	err := s.mailer.Send(ctx, mail.Message{To: to, Subject: subject, Body: body})
+	if err != nil {
+		log.Error().Err(err).Str("subject", subject).Msg("error in sending the notification mail")
+	}
+}
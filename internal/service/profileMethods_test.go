@@ -0,0 +1,326 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/afthaab/job-portal/internal/apperr"
+	"github.com/afthaab/job-portal/internal/auth"
+	mockauth "github.com/afthaab/job-portal/internal/auth/mockModels"
+	"github.com/afthaab/job-portal/internal/mail"
+	"github.com/afthaab/job-portal/internal/models"
+	"github.com/afthaab/job-portal/internal/ratelimit"
+	"github.com/afthaab/job-portal/internal/repository"
+	"github.com/golang-jwt/jwt/v5"
+	"go.uber.org/mock/gomock"
+	"gorm.io/gorm"
+)
+
+// the current password of profileUser is 12345678
+var profileUser = models.User{
+	Model:        gorm.Model{ID: 1},
+	Username:     "afthab",
+	Email:        "afthab606@gmail.com",
+	PasswordHash: "$2a$10$uS/GmX48bxvhGPS.IrujaefuktoqGuKz3HBeOOMH6MGrnDT1H4TEy",
+}
+
+var profileClaims = auth.Claims{RegisteredClaims: jwt.RegisteredClaims{Subject: "1"}, Role: models.RoleCandidate}
+
+func TestService_UpdateProfile(t *testing.T) {
+	blank := "  "
+	taken := "taken"
+	headline := "Go developer"
+	tests := []struct {
+		name      string
+		profile   models.UpdateProfile
+		setupMock func(m *repository.MockUserRepo)
+		want      models.UserResponse
+		wantKind  apperr.Kind
+	}{
+		{
+			name:     "nothing to update",
+			wantKind: apperr.KindValidation,
+		},
+		{
+			name:     "blank username",
+			profile:  models.UpdateProfile{Username: &blank},
+			wantKind: apperr.KindValidation,
+		},
+		{
+			name:    "username taken",
+			profile: models.UpdateProfile{Username: &taken},
+			setupMock: func(m *repository.MockUserRepo) {
+				m.EXPECT().UpdateUserProfile(gomock.Any(), uint64(1), gomock.Any()).Return(models.User{}, apperr.Conflict("could not update the profile, it already exists"))
+			},
+			wantKind: apperr.KindConflict,
+		},
+		{
+			name:    "profile updated",
+			profile: models.UpdateProfile{Headline: &headline},
+			setupMock: func(m *repository.MockUserRepo) {
+				m.EXPECT().UpdateUserProfile(gomock.Any(), uint64(1), models.UpdateProfile{Headline: &headline}).Return(models.User{
+					Model:    gorm.Model{ID: 1},
+					Username: "afthab",
+					Headline: headline,
+				}, nil)
+			},
+			want: models.UserResponse{ID: 1, Username: "afthab", Headline: headline},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mc := gomock.NewController(t)
+			mockRepo := repository.NewMockUserRepo(mc)
+			if tt.setupMock != nil {
+				tt.setupMock(mockRepo)
+			}
+
+			svc, err := NewService(mockRepo, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := svc.UpdateProfile(context.Background(), profileClaims, tt.profile)
+			if tt.wantKind != apperr.KindInternal && apperr.KindOf(err) != tt.wantKind {
+				t.Errorf("Service.UpdateProfile() error = %v, want kind %v", err, tt.wantKind)
+			}
+			if tt.wantKind == apperr.KindInternal && err != nil {
+				t.Errorf("Service.UpdateProfile() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Service.UpdateProfile() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestService_ChangePassword(t *testing.T) {
+	tests := []struct {
+		name      string
+		passwords models.ChangePasswordRequest
+		setupMock func(m *repository.MockUserRepo, a *mockauth.MockAuthentication)
+		wantErr   error
+		wantKind  apperr.Kind
+	}{
+		{
+			name:      "wrong current password",
+			passwords: models.ChangePasswordRequest{CurrentPassword: "wrong password", NewPassword: "purple tractor rain"},
+			wantErr:   errWrongPassword,
+		},
+		{
+			name:      "new password breaks the policy",
+			passwords: models.ChangePasswordRequest{CurrentPassword: "12345678", NewPassword: "password"},
+			wantKind:  apperr.KindValidation,
+		},
+		{
+			name:      "password changed and sessions revoked",
+			passwords: models.ChangePasswordRequest{CurrentPassword: "12345678", NewPassword: "purple tractor rain"},
+			setupMock: func(m *repository.MockUserRepo, a *mockauth.MockAuthentication) {
+				m.EXPECT().UpdateUserPassword(gomock.Any(), uint64(1), gomock.Any(), gomock.Any()).Return(nil)
+				m.EXPECT().RevokeUserRefreshTokens(gomock.Any(), uint64(1)).Return(nil)
+				m.EXPECT().RevokeUserTokens(gomock.Any(), uint64(1), models.TokenPurposeResetPassword).Return(nil)
+				a.EXPECT().RevokeSubject("1", gomock.Any(), gomock.Any())
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mc := gomock.NewController(t)
+			mockRepo := repository.NewMockUserRepo(mc)
+			mockAuth := mockauth.NewMockAuthentication(mc)
+			mockRepo.EXPECT().FindUserById(gomock.Any(), uint64(1)).Return(profileUser, nil)
+			if tt.setupMock != nil {
+				tt.setupMock(mockRepo, mockAuth)
+			}
+
+			svc, err := NewService(mockRepo, mockAuth)
+			if err != nil {
+				t.Fatal(err)
+			}
+			err = svc.ChangePassword(context.Background(), profileClaims, tt.passwords)
+			switch {
+			case tt.wantErr != nil && err != tt.wantErr:
+				t.Errorf("Service.ChangePassword() error = %v, want %v", err, tt.wantErr)
+			case tt.wantKind != apperr.KindInternal && apperr.KindOf(err) != tt.wantKind:
+				t.Errorf("Service.ChangePassword() error = %v, want kind %v", err, tt.wantKind)
+			case tt.wantErr == nil && tt.wantKind == apperr.KindInternal && err != nil:
+				t.Errorf("Service.ChangePassword() error = %v", err)
+			}
+		})
+	}
+}
+
+func TestService_ChangePassword_lockout(t *testing.T) {
+	ctx := context.Background()
+	mc := gomock.NewController(t)
+	mockRepo := repository.NewMockUserRepo(mc)
+	mockRepo.EXPECT().FindUserById(ctx, uint64(1)).Return(profileUser, nil).Times(3)
+
+	lockout := ratelimit.NewMemoryLockout(ratelimit.LockoutPolicy{Threshold: 2, Base: time.Minute, Max: time.Hour})
+	svc, err := NewService(mockRepo, nil, WithLockout(lockout))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// wrong current passwords count towards the lock of sign in, the right one is refused while it lasts
+	for i := 0; i < 2; i++ {
+		err = svc.ChangePassword(ctx, profileClaims, models.ChangePasswordRequest{CurrentPassword: "wrong password", NewPassword: "purple tractor rain"})
+		if err != errWrongPassword {
+			t.Errorf("Service.ChangePassword() error = %v, want %v", err, errWrongPassword)
+		}
+	}
+	err = svc.ChangePassword(ctx, profileClaims, models.ChangePasswordRequest{CurrentPassword: "12345678", NewPassword: "purple tractor rain"})
+	if apperr.KindOf(err) != apperr.KindTooManyRequests {
+		t.Errorf("Service.ChangePassword() error = %v, want too many requests", err)
+	}
+	locked, _ := lockout.LockedFor(ctx, "afthab606@gmail.com")
+	if locked <= 0 {
+		t.Errorf("sign in is not locked after the wrong passwords")
+	}
+}
+
+func TestService_ChangeEmail(t *testing.T) {
+	tests := []struct {
+		name      string
+		change    models.ChangeEmailRequest
+		setupMock func(m *repository.MockUserRepo)
+		wantKind  apperr.Kind
+		wantMail  bool
+	}{
+		{
+			name:     "wrong current password",
+			change:   models.ChangeEmailRequest{Email: "new@example.com", CurrentPassword: "wrong password"},
+			wantKind: apperr.KindValidation,
+		},
+		{
+			name:     "same address",
+			change:   models.ChangeEmailRequest{Email: "Afthab606@gmail.com", CurrentPassword: "12345678"},
+			wantKind: apperr.KindValidation,
+		},
+		{
+			name:   "address taken",
+			change: models.ChangeEmailRequest{Email: "new@example.com", CurrentPassword: "12345678"},
+			setupMock: func(m *repository.MockUserRepo) {
+				m.EXPECT().CheckEmail(gomock.Any(), "new@example.com").Return(models.User{Model: gorm.Model{ID: 2}}, nil)
+			},
+			wantKind: apperr.KindConflict,
+		},
+		{
+			name:   "link mailed to the new address",
+			change: models.ChangeEmailRequest{Email: " new@example.com ", CurrentPassword: "12345678"},
+			setupMock: func(m *repository.MockUserRepo) {
+				m.EXPECT().CheckEmail(gomock.Any(), "new@example.com").Return(models.User{}, apperr.NotFound("email not found"))
+				revoke := m.EXPECT().RevokeUserTokens(gomock.Any(), uint64(1), models.TokenPurposeChangeEmail).Return(nil)
+				m.EXPECT().CreateUserToken(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, token models.UserToken) (models.UserToken, error) {
+					if token.Purpose != models.TokenPurposeChangeEmail || token.Email != "new@example.com" || token.Uid != 1 {
+						t.Errorf("CreateUserToken() got %+v", token)
+					}
+					return token, nil
+				}).After(revoke)
+			},
+			wantMail: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mc := gomock.NewController(t)
+			mockRepo := repository.NewMockUserRepo(mc)
+			mockRepo.EXPECT().FindUserById(gomock.Any(), uint64(1)).Return(profileUser, nil)
+			if tt.setupMock != nil {
+				tt.setupMock(mockRepo)
+			}
+
+			dir := t.TempDir()
+			mailer, err := mail.NewFileMailer(dir, "no-reply@localhost")
+			if err != nil {
+				t.Fatal(err)
+			}
+			svc, err := NewService(mockRepo, nil, WithMailer(mailer))
+			if err != nil {
+				t.Fatal(err)
+			}
+			err = svc.ChangeEmail(context.Background(), profileClaims, tt.change)
+			if tt.wantKind != apperr.KindInternal && apperr.KindOf(err) != tt.wantKind {
+				t.Errorf("Service.ChangeEmail() error = %v, want kind %v", err, tt.wantKind)
+			}
+			if tt.wantKind == apperr.KindInternal && err != nil {
+				t.Errorf("Service.ChangeEmail() error = %v", err)
+			}
+			files, _ := filepath.Glob(filepath.Join(dir, "*.eml"))
+			if (len(files) == 1) != tt.wantMail {
+				t.Fatalf("Service.ChangeEmail() sent %d mails, want mail %v", len(files), tt.wantMail)
+			}
+			if tt.wantMail {
+				data, _ := os.ReadFile(files[0])
+				if !strings.Contains(string(data), "To: new@example.com") {
+					t.Errorf("Service.ChangeEmail() mailed %q, want it sent to the new address", data)
+				}
+			}
+		})
+	}
+}
+
+func TestService_DeleteAccount(t *testing.T) {
+	tests := []struct {
+		name      string
+		password  string
+		setupMock func(m *repository.MockUserRepo, a *mockauth.MockAuthentication)
+		wantKind  apperr.Kind
+	}{
+		{
+			name:     "wrong current password",
+			password: "wrong password",
+			wantKind: apperr.KindValidation,
+		},
+		{
+			name:     "only owner of a company",
+			password: "12345678",
+			setupMock: func(m *repository.MockUserRepo, a *mockauth.MockAuthentication) {
+				m.EXPECT().EraseUser(gomock.Any(), uint64(1), gomock.Any()).Return(apperr.Conflict("the account is the only owner of a company"))
+			},
+			wantKind: apperr.KindConflict,
+		},
+		{
+			name:     "account erased and sessions revoked",
+			password: "12345678",
+			setupMock: func(m *repository.MockUserRepo, a *mockauth.MockAuthentication) {
+				// the revocation is stored with the erasure so it outlives a restart
+				erase := m.EXPECT().EraseUser(gomock.Any(), uint64(1), gomock.Any()).DoAndReturn(
+					func(ctx context.Context, uid uint64, revoked models.RevokedSubject) error {
+						if revoked.Subject != "1" || revoked.ExpiresAt.Sub(revoked.IssuedBefore) != 15*time.Minute {
+							return fmt.Errorf("EraseUser() revoked = %+v, want every token of subject 1 for one access token ttl", revoked)
+						}
+						return nil
+					})
+				a.EXPECT().RevokeSubject("1", gomock.Any(), gomock.Any()).After(erase)
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mc := gomock.NewController(t)
+			mockRepo := repository.NewMockUserRepo(mc)
+			mockAuth := mockauth.NewMockAuthentication(mc)
+			mockRepo.EXPECT().FindUserById(gomock.Any(), uint64(1)).Return(profileUser, nil)
+			if tt.setupMock != nil {
+				tt.setupMock(mockRepo, mockAuth)
+			}
+
+			svc, err := NewService(mockRepo, mockAuth)
+			if err != nil {
+				t.Fatal(err)
+			}
+			err = svc.DeleteAccount(context.Background(), profileClaims, tt.password)
+			if tt.wantKind != apperr.KindInternal && apperr.KindOf(err) != tt.wantKind {
+				t.Errorf("Service.DeleteAccount() error = %v, want kind %v", err, tt.wantKind)
+			}
+			if tt.wantKind == apperr.KindInternal && err != nil {
+				t.Errorf("Service.DeleteAccount() error = %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,32 @@
+package service
+
+import (
+	"context"
+	"strings"
+
+	"github.com/afthaab/job-portal/internal/apperr"
+	"github.com/afthaab/job-portal/internal/auth"
+	"github.com/afthaab/job-portal/internal/models"
+)
+
+func (s *Service) SearchJobs(ctx context.Context, claims auth.Claims, query string, page models.PageQuery) (models.Page[models.JobSearchResponse], error) {
+	query = strings.TrimSpace(query)
+	if query == "" {
+		return models.Page[models.JobSearchResponse]{}, apperr.Validation("please provide a search term")
+	}
+
+	memberOf, openOnly, err := s.memberOf(ctx, claims)
+	if err != nil {
+		return models.Page[models.JobSearchResponse]{}, err
+	}
+	search := models.JobSearch{
+		Query:    query,
+		OpenOnly: openOnly,
+		MemberOf: memberOf,
+	}
+	results, total, err := s.UserRepo.SearchJobs(ctx, search, page)
+	if err != nil {
+		return models.Page[models.JobSearchResponse]{}, err
+	}
+	return newPage(mapItems(results, newJobSearchResponse), total, page), nil
+}
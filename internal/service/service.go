@@ -3,39 +3,221 @@ package service
 import (
 	"context"
 	"errors"
+	"sync"
+	"time"
 
 	"github.com/afthaab/job-portal/internal/auth"
+	"github.com/afthaab/job-portal/internal/mail"
 	"github.com/afthaab/job-portal/internal/models"
+	"github.com/afthaab/job-portal/internal/password"
+	"github.com/afthaab/job-portal/internal/ratelimit"
 	"github.com/afthaab/job-portal/internal/repository"
+	"github.com/rs/zerolog/log"
 )
 
 type Service struct {
-	UserRepo repository.UserRepo
-	auth     auth.Authentication
+	UserRepo        repository.UserRepo
+	auth            auth.Authentication
+	accessTokenTTL  time.Duration
+	refreshTokenTTL time.Duration
+	lockout         ratelimit.Lockout
+	mailer          mail.Mailer
+	verification    Verification
+	passwordReset   PasswordReset
+	invitationURL   string
+	passwordPolicy  password.Policy
+	hasher          *password.Hasher
+	// dummy is the hash compared against on sign in with an unknown email
+	dummyOnce sync.Once
+	dummy     string
+	// background counts the work still running after its request was answered
+	background sync.WaitGroup
+}
+
+// backgroundTimeout bounds the work done after a request was answered, such as sending a mail
+const backgroundTimeout = time.Minute
+
+// Verification is how the service verifies the email of the users who sign up
+type Verification struct {
+	// Required refuses sign in to users who did not verify their email yet
+	Required bool
+	// TTL is how long a verification link stays valid
+	TTL time.Duration
+	// URL is the page the links open with the token query parameter, empty mails the bare token
+	URL string
+}
+
+// Option changes one of the defaults of the service
+type Option func(*Service)
+
+// WithTokenTTL sets how long the access tokens and refresh tokens handed out on sign in stay valid
+func WithTokenTTL(access time.Duration, refresh time.Duration) Option {
+	return func(s *Service) {
+		s.accessTokenTTL = access
+		s.refreshTokenTTL = refresh
+	}
+}
+
+// WithLockout sets where the failed sign ins are counted, the default keeps them in memory
+func WithLockout(l ratelimit.Lockout) Option {
+	return func(s *Service) {
+		s.lockout = l
+	}
+}
+
+// PasswordReset is how long the mailed password reset links stay valid and the page they open
+type PasswordReset struct {
+	TTL time.Duration
+	URL string
+}
+
+// WithMailer sets how the service sends its emails, the default only logs them
+func WithMailer(m mail.Mailer) Option {
+	return func(s *Service) {
+		s.mailer = m
+	}
+}
+
+// WithVerification changes the defaults of the email verification
+func WithVerification(v Verification) Option {
+	return func(s *Service) {
+		s.verification = v
+	}
+}
+
+// WithPasswordReset changes the defaults of the password reset
+func WithPasswordReset(p PasswordReset) Option {
+	return func(s *Service) {
+		s.passwordReset = p
+	}
+}
+
+// WithInvitationURL sets the page the invitation mails link to, empty mails the id of the invitation
+func WithInvitationURL(url string) Option {
+	return func(s *Service) {
+		s.invitationURL = url
+	}
+}
+
+// WithPasswords sets the policy new passwords must meet and how they are hashed,
+// the policy is capped at the longest password the hasher can hash
+func WithPasswords(policy password.Policy, hasher *password.Hasher) Option {
+	return func(s *Service) {
+		s.passwordPolicy = hasher.Limit(policy)
+		s.hasher = hasher
+	}
+}
+
+// goBackground runs work once the request is answered so the response time does not tell whether it ran,
+// it gets a context of its own because the context of the request ends with the response
+func (s *Service) goBackground(name string, work func(ctx context.Context) error) {
+	s.background.Add(1)
+	go func() {
+		defer s.background.Done()
+		ctx, cancel := context.WithTimeout(context.Background(), backgroundTimeout)
+		defer cancel()
+		err := work(ctx)
+		if err != nil {
+			log.Error().Err(err).Msg("error in " + name)
+		}
+	}()
+}
+
+// Wait is called on shutdown so the mails that were promised in a response still go out
+func (s *Service) Wait(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		s.background.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
 }
 
 //go:generate mockgen -source=service.go -destination=mockmodels/service_mock.go -package=mockmodels
 
 type UserService interface {
-	UserSignup(ctx context.Context, userData models.NewUser) (models.User, error)
-	UserSignIn(ctx context.Context, userData models.NewUser) (string, error)
+	UserSignup(ctx context.Context, userData models.NewUser) (models.UserResponse, error)
+	UserSignIn(ctx context.Context, userData models.UserSignin) (models.TokenPair, error)
+	CreateUser(ctx context.Context, userData models.NewUser, role string) (models.UserResponse, error)
+	VerifyEmail(ctx context.Context, token string) (models.UserResponse, error)
+	ResendVerification(ctx context.Context, email string) error
+	ForgotPassword(ctx context.Context, email string) error
+	ResetPassword(ctx context.Context, token string, password string) error
+	AccessToken(ctx context.Context, uid uint64) (string, error)
+	RefreshToken(ctx context.Context, refreshToken string) (models.TokenPair, error)
+	Logout(ctx context.Context, claims auth.Claims, refreshToken string) error
+	// Wait blocks until the work started in the background is done or ctx ends
+	Wait(ctx context.Context) error
+	// LoadRevocations reads the revoked access tokens stored by every instance into the one of this instance
+	LoadRevocations(ctx context.Context) error
+	ViewProfile(ctx context.Context, claims auth.Claims) (models.UserResponse, error)
+	UpdateProfile(ctx context.Context, claims auth.Claims, profile models.UpdateProfile) (models.UserResponse, error)
+	ChangePassword(ctx context.Context, claims auth.Claims, passwords models.ChangePasswordRequest) error
+	ChangeEmail(ctx context.Context, claims auth.Claims, change models.ChangeEmailRequest) error
+	DeleteAccount(ctx context.Context, claims auth.Claims, currentPassword string) error
+
+	AddCompanyDetails(ctx context.Context, claims auth.Claims, companyData models.NewCompany) (models.CompanyResponse, error)
+	ViewAllCompanies(ctx context.Context, filter models.CompanyFilter, page models.PageQuery) (models.Page[models.CompanyResponse], error)
+	ViewCompanyDetails(ctx context.Context, cid uint64) (models.CompanyResponse, error)
+	UpdateCompanyDetails(ctx context.Context, claims auth.Claims, cid uint64, companyData models.NewCompany) (models.CompanyResponse, error)
+	PatchCompanyDetails(ctx context.Context, claims auth.Claims, cid uint64, companyData models.UpdateCompany) (models.CompanyResponse, error)
+	DeleteCompany(ctx context.Context, claims auth.Claims, cid uint64) error
+	RestoreCompany(ctx context.Context, cid uint64) (models.CompanyResponse, error)
+	InviteMember(ctx context.Context, claims auth.Claims, cid uint64, invitation models.NewInvitation) (models.InvitationResponse, error)
+	ViewCompanyMembers(ctx context.Context, claims auth.Claims, cid uint64, page models.PageQuery) (models.Page[models.MemberResponse], error)
+	RemoveMember(ctx context.Context, claims auth.Claims, cid uint64, uid uint64) error
+	ViewMyInvitations(ctx context.Context, claims auth.Claims) ([]models.InvitationResponse, error)
+	AcceptInvitation(ctx context.Context, claims auth.Claims, iid uint64) (models.AcceptedInvitation, error)
+	ViewJob(ctx context.Context, claims auth.Claims, cid uint64, page models.PageQuery) (models.Page[models.JobResponse], error)
 
-	AddCompanyDetails(ctx context.Context, companyData models.Company) (models.Company, error)
-	ViewAllCompanies(ctx context.Context) ([]models.Company, error)
-	ViewCompanyDetails(ctx context.Context, cid uint64) (models.Company, error)
-	ViewJob(ctx context.Context, cid uint64) ([]models.Jobs, error)
+	AddJobDetails(ctx context.Context, claims auth.Claims, jobData models.NewJob, cid uint64) (models.JobResponse, error)
+	ViewAllJobs(ctx context.Context, claims auth.Claims, filter models.JobFilter, page models.PageQuery) (models.Page[models.JobResponse], error)
+	ViewJobById(ctx context.Context, claims auth.Claims, jid uint64) (models.JobResponse, error)
+	UpdateJobDetails(ctx context.Context, claims auth.Claims, jid uint64, jobData models.NewJob) (models.JobResponse, error)
+	PatchJobDetails(ctx context.Context, claims auth.Claims, jid uint64, jobData models.UpdateJob) (models.JobResponse, error)
+	UpdateJobStatus(ctx context.Context, claims auth.Claims, jid uint64, status string) (models.JobResponse, error)
+	DeleteJob(ctx context.Context, claims auth.Claims, jid uint64) error
+	SearchJobs(ctx context.Context, claims auth.Claims, query string, page models.PageQuery) (models.Page[models.JobSearchResponse], error)
 
-	AddJobDetails(ctx context.Context, jobData models.Jobs, cid uint64) (models.Jobs, error)
-	ViewAllJobs(ctx context.Context) ([]models.Jobs, error)
-	ViewJobById(ctx context.Context, jid uint64) (models.Jobs, error)
+	ApplyForJob(ctx context.Context, uid uint64, jid uint64, applicationData models.NewApplication) (models.ApplicationResponse, error)
+	WithdrawApplication(ctx context.Context, uid uint64, aid uint64) (models.ApplicationResponse, error)
+	ViewMyApplications(ctx context.Context, uid uint64, page models.PageQuery) (models.Page[models.ApplicationResponse], error)
+	ViewJobApplicants(ctx context.Context, claims auth.Claims, jid uint64, page models.PageQuery) (models.Page[models.ApplicationResponse], error)
+	UpdateApplicationStatus(ctx context.Context, claims auth.Claims, aid uint64, status string) (models.ApplicationResponse, error)
 }
 
-func NewService(userRepo repository.UserRepo, a auth.Authentication) (UserService, error) {
+func NewService(userRepo repository.UserRepo, a auth.Authentication, opts ...Option) (UserService, error) {
 	if userRepo == nil {
 		return nil, errors.New("interface cannot be null")
 	}
-	return &Service{
-		UserRepo: userRepo,
-		auth:     a,
-	}, nil
+	s := &Service{
+		UserRepo:        userRepo,
+		auth:            a,
+		accessTokenTTL:  15 * time.Minute,
+		refreshTokenTTL: 30 * 24 * time.Hour,
+		lockout: ratelimit.NewMemoryLockout(ratelimit.LockoutPolicy{
+			Threshold: 5,
+			Base:      30 * time.Second,
+			Max:       15 * time.Minute,
+		}),
+		mailer: mail.LogMailer{},
+		verification: Verification{
+			Required: true,
+			TTL:      24 * time.Hour,
+		},
+		passwordReset: PasswordReset{
+			TTL: time.Hour,
+		},
+		passwordPolicy: password.DefaultPolicy,
+		hasher:         password.NewHasher(password.DefaultArgon2id),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s, nil
 }
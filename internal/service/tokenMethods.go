@@ -0,0 +1,224 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/afthaab/job-portal/internal/apperr"
+	"github.com/afthaab/job-portal/internal/auth"
+	"github.com/afthaab/job-portal/internal/models"
+	"github.com/afthaab/job-portal/internal/repository"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+)
+
+var errInvalidRefreshToken = apperr.Unauthorized("invalid refresh token")
+
+func (s *Service) RefreshToken(ctx context.Context, refreshToken string) (models.TokenPair, error) {
+	tokenData, err := s.UserRepo.FindRefreshToken(ctx, hashToken(refreshToken))
+	if errors.Is(err, apperr.ErrNotFound) {
+		return models.TokenPair{}, errInvalidRefreshToken
+	}
+	if err != nil {
+		return models.TokenPair{}, err
+	}
+
+	// a refresh token is only good once, seeing it again means it was stolen so the whole family is revoked
+	if tokenData.RevokedAt != nil {
+		log.Warn().Uint("uid", tokenData.Uid).Str("family", tokenData.Family).Msg("refresh token reuse detected")
+		err = s.UserRepo.RevokeRefreshTokenFamily(ctx, tokenData.Family)
+		if err != nil {
+			return models.TokenPair{}, err
+		}
+		return models.TokenPair{}, errInvalidRefreshToken
+	}
+	if time.Now().After(tokenData.ExpiresAt) {
+		return models.TokenPair{}, apperr.Unauthorized("refresh token has expired")
+	}
+
+	userDetails, err := s.UserRepo.FindUserById(ctx, uint64(tokenData.Uid))
+	if err != nil {
+		return models.TokenPair{}, err
+	}
+
+	accessToken, err := s.generateAccessToken(userDetails)
+	if err != nil {
+		return models.TokenPair{}, err
+	}
+	refresh, newToken, err := s.newRefreshToken(userDetails, tokenData.Family)
+	if err != nil {
+		return models.TokenPair{}, err
+	}
+
+	_, err = s.UserRepo.RotateRefreshToken(ctx, tokenData, newToken)
+	if errors.Is(err, repository.ErrRefreshTokenUsed) {
+		// another request rotated the same token first
+		log.Warn().Uint("uid", tokenData.Uid).Str("family", tokenData.Family).Msg("refresh token reuse detected")
+		err = s.UserRepo.RevokeRefreshTokenFamily(ctx, tokenData.Family)
+		if err != nil {
+			return models.TokenPair{}, err
+		}
+		return models.TokenPair{}, errInvalidRefreshToken
+	}
+	if err != nil {
+		return models.TokenPair{}, err
+	}
+
+	return models.TokenPair{
+		AccessToken:  accessToken,
+		RefreshToken: refresh,
+		ExpiresIn:    int64(s.accessTokenTTL.Seconds()),
+	}, nil
+}
+
+// Logout revokes the access token the request was made with and the refresh tokens of the session,
+// without a refresh token every session of the user is ended
+func (s *Service) Logout(ctx context.Context, claims auth.Claims, refreshToken string) error {
+	uid, err := claimsUserID(claims)
+	if err != nil {
+		return err
+	}
+
+	if refreshToken == "" {
+		err = s.UserRepo.RevokeUserRefreshTokens(ctx, uid)
+		if err != nil {
+			return err
+		}
+	} else {
+		tokenData, err := s.UserRepo.FindRefreshToken(ctx, hashToken(refreshToken))
+		if err != nil || uint64(tokenData.Uid) != uid {
+			return errInvalidRefreshToken
+		}
+		err = s.UserRepo.RevokeRefreshTokenFamily(ctx, tokenData.Family)
+		if err != nil {
+			return err
+		}
+	}
+
+	if claims.ID == "" || claims.ExpiresAt == nil {
+		return nil
+	}
+	err = s.UserRepo.RevokeAccessToken(ctx, models.RevokedToken{
+		Jti:       claims.ID,
+		ExpiresAt: claims.ExpiresAt.Time,
+	})
+	if err != nil {
+		return err
+	}
+	s.auth.RevokeToken(claims.ID, claims.ExpiresAt.Time)
+	return nil
+}
+
+// LoadRevocations reads the access tokens revoked through any instance into the revocation list: the ones
+// revoked on logout, the ones issued before a password change and the ones of erased accounts.
+// It runs on start and then every so often so a logout on one instance is honored by the others
+func (s *Service) LoadRevocations(ctx context.Context) error {
+	revoked, err := s.UserRepo.FindRevokedTokens(ctx)
+	if err != nil {
+		return err
+	}
+	for _, t := range revoked {
+		s.auth.RevokeToken(t.Jti, t.ExpiresAt)
+	}
+
+	changed, err := s.UserRepo.FindPasswordChanges(ctx, time.Now().Add(-s.accessTokenTTL))
+	if err != nil {
+		return err
+	}
+	for _, u := range changed {
+		s.auth.RevokeSubject(strconv.FormatUint(uint64(u.ID), 10), *u.PasswordChangedAt, u.PasswordChangedAt.Add(s.accessTokenTTL))
+	}
+
+	erased, err := s.UserRepo.FindRevokedSubjects(ctx)
+	if err != nil {
+		return err
+	}
+	for _, r := range erased {
+		s.auth.RevokeSubject(r.Subject, r.IssuedBefore, r.ExpiresAt)
+	}
+	return nil
+}
+
+// AccessToken mints an access token for the user without a refresh token, the token command
+// uses it to debug the routes as a given user
+func (s *Service) AccessToken(ctx context.Context, uid uint64) (string, error) {
+	userDetails, err := s.UserRepo.FindUserById(ctx, uid)
+	if err != nil {
+		return "", err
+	}
+	return s.generateAccessToken(userDetails)
+}
+
+// issueTokens signs a new access token and stores a new refresh token in the given family
+func (s *Service) issueTokens(ctx context.Context, userDetails models.User, family string) (models.TokenPair, error) {
+	accessToken, err := s.generateAccessToken(userDetails)
+	if err != nil {
+		return models.TokenPair{}, err
+	}
+
+	refresh, tokenData, err := s.newRefreshToken(userDetails, family)
+	if err != nil {
+		return models.TokenPair{}, err
+	}
+	_, err = s.UserRepo.CreateRefreshToken(ctx, tokenData)
+	if err != nil {
+		return models.TokenPair{}, err
+	}
+
+	return models.TokenPair{
+		AccessToken:  accessToken,
+		RefreshToken: refresh,
+		ExpiresIn:    int64(s.accessTokenTTL.Seconds()),
+	}, nil
+}
+
+func (s *Service) generateAccessToken(userDetails models.User) (string, error) {
+	now := time.Now()
+	claims := auth.Claims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        uuid.NewString(),
+			Issuer:    "job portal project",
+			Subject:   strconv.FormatUint(uint64(userDetails.ID), 10),
+			Audience:  jwt.ClaimStrings{"users"},
+			ExpiresAt: jwt.NewNumericDate(now.Add(s.accessTokenTTL)),
+			IssuedAt:  jwt.NewNumericDate(now),
+		},
+		Role: userDetails.Role,
+	}
+	return s.auth.GenerateAuthToken(claims)
+}
+
+func (s *Service) newRefreshToken(userDetails models.User, family string) (string, models.RefreshToken, error) {
+	refresh, err := newOpaqueToken()
+	if err != nil {
+		return "", models.RefreshToken{}, err
+	}
+	return refresh, models.RefreshToken{
+		Uid:       userDetails.ID,
+		TokenHash: hashToken(refresh),
+		Family:    family,
+		ExpiresAt: time.Now().Add(s.refreshTokenTTL),
+	}, nil
+}
+
+func newOpaqueToken() (string, error) {
+	b := make([]byte, 32)
+	_, err := rand.Read(b)
+	if err != nil {
+		return "", fmt.Errorf("error in generating the token : %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,224 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/afthaab/job-portal/internal/auth"
+	mockauth "github.com/afthaab/job-portal/internal/auth/mockModels"
+	"github.com/afthaab/job-portal/internal/models"
+	"github.com/afthaab/job-portal/internal/repository"
+	"github.com/golang-jwt/jwt/v5"
+	"go.uber.org/mock/gomock"
+	"gorm.io/gorm"
+)
+
+func TestService_RefreshToken(t *testing.T) {
+	revokedAt := time.Now().Add(-time.Minute)
+	live := models.RefreshToken{
+		Model:     gorm.Model{ID: 7},
+		Uid:       1,
+		TokenHash: hashToken("refresh"),
+		Family:    "family",
+		ExpiresAt: time.Now().Add(time.Hour),
+	}
+	used := live
+	used.RevokedAt = &revokedAt
+	expired := live
+	expired.ExpiresAt = time.Now().Add(-time.Hour)
+
+	tests := []struct {
+		name         string
+		setup        func(mockRepo *repository.MockUserRepo, mockAuth *mockauth.MockAuthentication)
+		want         string
+		wantErr      bool
+		wantRotation bool
+	}{
+		{
+			name: "unknown token",
+			setup: func(mockRepo *repository.MockUserRepo, mockAuth *mockauth.MockAuthentication) {
+				mockRepo.EXPECT().FindRefreshToken(gomock.Any(), hashToken("refresh")).Return(models.RefreshToken{}, errors.New("could not find the refresh token"))
+			},
+			wantErr: true,
+		},
+		{
+			name: "reused token revokes the family",
+			setup: func(mockRepo *repository.MockUserRepo, mockAuth *mockauth.MockAuthentication) {
+				mockRepo.EXPECT().FindRefreshToken(gomock.Any(), hashToken("refresh")).Return(used, nil)
+				mockRepo.EXPECT().RevokeRefreshTokenFamily(gomock.Any(), "family").Return(nil).Times(1)
+			},
+			wantErr: true,
+		},
+		{
+			name: "expired token",
+			setup: func(mockRepo *repository.MockUserRepo, mockAuth *mockauth.MockAuthentication) {
+				mockRepo.EXPECT().FindRefreshToken(gomock.Any(), hashToken("refresh")).Return(expired, nil)
+			},
+			wantErr: true,
+		},
+		{
+			name: "lost the race to another refresh",
+			setup: func(mockRepo *repository.MockUserRepo, mockAuth *mockauth.MockAuthentication) {
+				mockRepo.EXPECT().FindRefreshToken(gomock.Any(), hashToken("refresh")).Return(live, nil)
+				mockRepo.EXPECT().FindUserById(gomock.Any(), uint64(1)).Return(models.User{Model: gorm.Model{ID: 1}, Role: models.RoleCandidate}, nil)
+				mockAuth.EXPECT().GenerateAuthToken(gomock.Any()).Return("jwt test string", nil)
+				mockRepo.EXPECT().RotateRefreshToken(gomock.Any(), live, gomock.Any()).Return(models.RefreshToken{}, repository.ErrRefreshTokenUsed)
+				mockRepo.EXPECT().RevokeRefreshTokenFamily(gomock.Any(), "family").Return(nil).Times(1)
+			},
+			wantErr: true,
+		},
+		{
+			name: "success rotates the token",
+			setup: func(mockRepo *repository.MockUserRepo, mockAuth *mockauth.MockAuthentication) {
+				mockRepo.EXPECT().FindRefreshToken(gomock.Any(), hashToken("refresh")).Return(live, nil)
+				mockRepo.EXPECT().FindUserById(gomock.Any(), uint64(1)).Return(models.User{Model: gorm.Model{ID: 1}, Role: models.RoleCandidate}, nil)
+				mockAuth.EXPECT().GenerateAuthToken(gomock.Cond(func(x any) bool {
+					claims, ok := x.(auth.Claims)
+					return ok && claims.Subject == "1" && claims.ID != "" && claims.Role == models.RoleCandidate
+				})).Return("jwt test string", nil)
+				mockRepo.EXPECT().RotateRefreshToken(gomock.Any(), live, gomock.Cond(func(x any) bool {
+					newToken, ok := x.(models.RefreshToken)
+					return ok && newToken.Family == "family" && newToken.Uid == 1 && newToken.TokenHash != live.TokenHash
+				})).Return(models.RefreshToken{}, nil)
+			},
+			want:         "jwt test string",
+			wantRotation: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mc := gomock.NewController(t)
+			mockRepo := repository.NewMockUserRepo(mc)
+			mockAuth := mockauth.NewMockAuthentication(mc)
+			tt.setup(mockRepo, mockAuth)
+
+			svc, err := NewService(mockRepo, mockAuth)
+			if err != nil {
+				t.Errorf("error is initializing the repo layer")
+				return
+			}
+			got, err := svc.RefreshToken(context.Background(), "refresh")
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Service.RefreshToken() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got.AccessToken != tt.want {
+				t.Errorf("Service.RefreshToken() = %v, want %v", got.AccessToken, tt.want)
+			}
+			if tt.wantRotation && (got.RefreshToken == "" || got.RefreshToken == "refresh") {
+				t.Errorf("Service.RefreshToken() did not rotate the refresh token")
+			}
+		})
+	}
+}
+
+func TestService_Logout(t *testing.T) {
+	expiresAt := time.Now().Add(time.Minute).Truncate(time.Second)
+	claims := auth.Claims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        "jti",
+			Subject:   "1",
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
+		},
+	}
+
+	tests := []struct {
+		name         string
+		refreshToken string
+		setup        func(mockRepo *repository.MockUserRepo, mockAuth *mockauth.MockAuthentication)
+		wantErr      bool
+	}{
+		{
+			name: "logout of every session",
+			setup: func(mockRepo *repository.MockUserRepo, mockAuth *mockauth.MockAuthentication) {
+				mockRepo.EXPECT().RevokeUserRefreshTokens(gomock.Any(), uint64(1)).Return(nil)
+				mockRepo.EXPECT().RevokeAccessToken(gomock.Any(), models.RevokedToken{Jti: "jti", ExpiresAt: expiresAt}).Return(nil)
+				mockAuth.EXPECT().RevokeToken("jti", expiresAt)
+			},
+		},
+		{
+			name:         "logout of one session",
+			refreshToken: "refresh",
+			setup: func(mockRepo *repository.MockUserRepo, mockAuth *mockauth.MockAuthentication) {
+				mockRepo.EXPECT().FindRefreshToken(gomock.Any(), hashToken("refresh")).Return(models.RefreshToken{Uid: 1, Family: "family"}, nil)
+				mockRepo.EXPECT().RevokeRefreshTokenFamily(gomock.Any(), "family").Return(nil)
+				mockRepo.EXPECT().RevokeAccessToken(gomock.Any(), models.RevokedToken{Jti: "jti", ExpiresAt: expiresAt}).Return(nil)
+				mockAuth.EXPECT().RevokeToken("jti", expiresAt)
+			},
+		},
+		{
+			name:         "refresh token of another user",
+			refreshToken: "refresh",
+			setup: func(mockRepo *repository.MockUserRepo, mockAuth *mockauth.MockAuthentication) {
+				mockRepo.EXPECT().FindRefreshToken(gomock.Any(), hashToken("refresh")).Return(models.RefreshToken{Uid: 2, Family: "family"}, nil)
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mc := gomock.NewController(t)
+			mockRepo := repository.NewMockUserRepo(mc)
+			mockAuth := mockauth.NewMockAuthentication(mc)
+			tt.setup(mockRepo, mockAuth)
+
+			svc, err := NewService(mockRepo, mockAuth)
+			if err != nil {
+				t.Errorf("error is initializing the repo layer")
+				return
+			}
+			err = svc.Logout(context.Background(), claims, tt.refreshToken)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Service.Logout() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestService_LoadRevocations(t *testing.T) {
+	changedAt := time.Now().Add(-time.Minute)
+	expiresAt := time.Now().Add(10 * time.Minute)
+	tests := []struct {
+		name      string
+		setupMock func(m *repository.MockUserRepo, a *mockauth.MockAuthentication)
+		wantErr   bool
+	}{
+		{
+			name: "every kind of revocation is loaded",
+			setupMock: func(m *repository.MockUserRepo, a *mockauth.MockAuthentication) {
+				m.EXPECT().FindRevokedTokens(gomock.Any()).Return([]models.RevokedToken{{Jti: "jti", ExpiresAt: expiresAt}}, nil)
+				m.EXPECT().FindPasswordChanges(gomock.Any(), gomock.Any()).Return([]models.User{{Model: gorm.Model{ID: 1}, PasswordChangedAt: &changedAt}}, nil)
+				m.EXPECT().FindRevokedSubjects(gomock.Any()).Return([]models.RevokedSubject{{Subject: "2", IssuedBefore: changedAt, ExpiresAt: expiresAt}}, nil)
+				a.EXPECT().RevokeToken("jti", expiresAt)
+				a.EXPECT().RevokeSubject("1", changedAt, changedAt.Add(15*time.Minute))
+				a.EXPECT().RevokeSubject("2", changedAt, expiresAt)
+			},
+		},
+		{
+			name: "error from the database",
+			setupMock: func(m *repository.MockUserRepo, a *mockauth.MockAuthentication) {
+				m.EXPECT().FindRevokedTokens(gomock.Any()).Return(nil, errors.New("test error"))
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mc := gomock.NewController(t)
+			mockRepo := repository.NewMockUserRepo(mc)
+			mockAuth := mockauth.NewMockAuthentication(mc)
+			tt.setupMock(mockRepo, mockAuth)
+
+			svc, err := NewService(mockRepo, mockAuth)
+			if err != nil {
+				t.Fatal(err)
+			}
+			err = svc.LoadRevocations(context.Background())
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Service.LoadRevocations() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,331 @@
+package service
+
+import (
+	"context"
+
+	"github.com/afthaab/job-portal/internal/auth"
+	"github.com/afthaab/job-portal/internal/models"
+	"github.com/afthaab/job-portal/internal/tracing"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// tracedService starts a span for every call of the service it wraps
+type tracedService struct {
+	next UserService
+}
+
+// Traced wraps the service so each call shows up as a span between the handler and the sql spans
+func Traced(svc UserService) UserService {
+	return tracedService{next: svc}
+}
+
+func startSpan(ctx context.Context, method string) (context.Context, trace.Span) {
+	return tracing.Tracer().Start(ctx, "UserService."+method)
+}
+
+// endSpan marks the span failed when the call returned an error
+func endSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+func (t tracedService) UserSignup(ctx context.Context, userData models.NewUser) (models.UserResponse, error) {
+	ctx, span := startSpan(ctx, "UserSignup")
+	result, err := t.next.UserSignup(ctx, userData)
+	endSpan(span, err)
+	return result, err
+}
+
+func (t tracedService) UserSignIn(ctx context.Context, userData models.UserSignin) (models.TokenPair, error) {
+	ctx, span := startSpan(ctx, "UserSignIn")
+	result, err := t.next.UserSignIn(ctx, userData)
+	endSpan(span, err)
+	return result, err
+}
+
+func (t tracedService) CreateUser(ctx context.Context, userData models.NewUser, role string) (models.UserResponse, error) {
+	ctx, span := startSpan(ctx, "CreateUser")
+	result, err := t.next.CreateUser(ctx, userData, role)
+	endSpan(span, err)
+	return result, err
+}
+
+func (t tracedService) AccessToken(ctx context.Context, uid uint64) (string, error) {
+	ctx, span := startSpan(ctx, "AccessToken")
+	result, err := t.next.AccessToken(ctx, uid)
+	endSpan(span, err)
+	return result, err
+}
+
+func (t tracedService) VerifyEmail(ctx context.Context, token string) (models.UserResponse, error) {
+	ctx, span := startSpan(ctx, "VerifyEmail")
+	result, err := t.next.VerifyEmail(ctx, token)
+	endSpan(span, err)
+	return result, err
+}
+
+func (t tracedService) ResendVerification(ctx context.Context, email string) error {
+	ctx, span := startSpan(ctx, "ResendVerification")
+	err := t.next.ResendVerification(ctx, email)
+	endSpan(span, err)
+	return err
+}
+
+func (t tracedService) ForgotPassword(ctx context.Context, email string) error {
+	ctx, span := startSpan(ctx, "ForgotPassword")
+	err := t.next.ForgotPassword(ctx, email)
+	endSpan(span, err)
+	return err
+}
+
+func (t tracedService) ResetPassword(ctx context.Context, token string, password string) error {
+	ctx, span := startSpan(ctx, "ResetPassword")
+	err := t.next.ResetPassword(ctx, token, password)
+	endSpan(span, err)
+	return err
+}
+
+func (t tracedService) RefreshToken(ctx context.Context, refreshToken string) (models.TokenPair, error) {
+	ctx, span := startSpan(ctx, "RefreshToken")
+	result, err := t.next.RefreshToken(ctx, refreshToken)
+	endSpan(span, err)
+	return result, err
+}
+
+func (t tracedService) Logout(ctx context.Context, claims auth.Claims, refreshToken string) error {
+	ctx, span := startSpan(ctx, "Logout")
+	err := t.next.Logout(ctx, claims, refreshToken)
+	endSpan(span, err)
+	return err
+}
+
+// Wait is not traced, it is not part of a request
+func (t tracedService) Wait(ctx context.Context) error {
+	return t.next.Wait(ctx)
+}
+
+// LoadRevocations is not traced either, it runs on a timer
+func (t tracedService) LoadRevocations(ctx context.Context) error {
+	return t.next.LoadRevocations(ctx)
+}
+
+func (t tracedService) ViewProfile(ctx context.Context, claims auth.Claims) (models.UserResponse, error) {
+	ctx, span := startSpan(ctx, "ViewProfile")
+	result, err := t.next.ViewProfile(ctx, claims)
+	endSpan(span, err)
+	return result, err
+}
+
+func (t tracedService) UpdateProfile(ctx context.Context, claims auth.Claims, profile models.UpdateProfile) (models.UserResponse, error) {
+	ctx, span := startSpan(ctx, "UpdateProfile")
+	result, err := t.next.UpdateProfile(ctx, claims, profile)
+	endSpan(span, err)
+	return result, err
+}
+
+func (t tracedService) ChangePassword(ctx context.Context, claims auth.Claims, passwords models.ChangePasswordRequest) error {
+	ctx, span := startSpan(ctx, "ChangePassword")
+	err := t.next.ChangePassword(ctx, claims, passwords)
+	endSpan(span, err)
+	return err
+}
+
+func (t tracedService) ChangeEmail(ctx context.Context, claims auth.Claims, change models.ChangeEmailRequest) error {
+	ctx, span := startSpan(ctx, "ChangeEmail")
+	err := t.next.ChangeEmail(ctx, claims, change)
+	endSpan(span, err)
+	return err
+}
+
+func (t tracedService) DeleteAccount(ctx context.Context, claims auth.Claims, currentPassword string) error {
+	ctx, span := startSpan(ctx, "DeleteAccount")
+	err := t.next.DeleteAccount(ctx, claims, currentPassword)
+	endSpan(span, err)
+	return err
+}
+
+func (t tracedService) AddCompanyDetails(ctx context.Context, claims auth.Claims, companyData models.NewCompany) (models.CompanyResponse, error) {
+	ctx, span := startSpan(ctx, "AddCompanyDetails")
+	result, err := t.next.AddCompanyDetails(ctx, claims, companyData)
+	endSpan(span, err)
+	return result, err
+}
+
+func (t tracedService) ViewAllCompanies(ctx context.Context, filter models.CompanyFilter, page models.PageQuery) (models.Page[models.CompanyResponse], error) {
+	ctx, span := startSpan(ctx, "ViewAllCompanies")
+	result, err := t.next.ViewAllCompanies(ctx, filter, page)
+	endSpan(span, err)
+	return result, err
+}
+
+func (t tracedService) ViewCompanyDetails(ctx context.Context, cid uint64) (models.CompanyResponse, error) {
+	ctx, span := startSpan(ctx, "ViewCompanyDetails")
+	result, err := t.next.ViewCompanyDetails(ctx, cid)
+	endSpan(span, err)
+	return result, err
+}
+
+func (t tracedService) UpdateCompanyDetails(ctx context.Context, claims auth.Claims, cid uint64, companyData models.NewCompany) (models.CompanyResponse, error) {
+	ctx, span := startSpan(ctx, "UpdateCompanyDetails")
+	result, err := t.next.UpdateCompanyDetails(ctx, claims, cid, companyData)
+	endSpan(span, err)
+	return result, err
+}
+
+func (t tracedService) PatchCompanyDetails(ctx context.Context, claims auth.Claims, cid uint64, companyData models.UpdateCompany) (models.CompanyResponse, error) {
+	ctx, span := startSpan(ctx, "PatchCompanyDetails")
+	result, err := t.next.PatchCompanyDetails(ctx, claims, cid, companyData)
+	endSpan(span, err)
+	return result, err
+}
+
+func (t tracedService) DeleteCompany(ctx context.Context, claims auth.Claims, cid uint64) error {
+	ctx, span := startSpan(ctx, "DeleteCompany")
+	err := t.next.DeleteCompany(ctx, claims, cid)
+	endSpan(span, err)
+	return err
+}
+
+func (t tracedService) RestoreCompany(ctx context.Context, cid uint64) (models.CompanyResponse, error) {
+	ctx, span := startSpan(ctx, "RestoreCompany")
+	result, err := t.next.RestoreCompany(ctx, cid)
+	endSpan(span, err)
+	return result, err
+}
+
+func (t tracedService) InviteMember(ctx context.Context, claims auth.Claims, cid uint64, invitation models.NewInvitation) (models.InvitationResponse, error) {
+	ctx, span := startSpan(ctx, "InviteMember")
+	result, err := t.next.InviteMember(ctx, claims, cid, invitation)
+	endSpan(span, err)
+	return result, err
+}
+
+func (t tracedService) ViewCompanyMembers(ctx context.Context, claims auth.Claims, cid uint64, page models.PageQuery) (models.Page[models.MemberResponse], error) {
+	ctx, span := startSpan(ctx, "ViewCompanyMembers")
+	result, err := t.next.ViewCompanyMembers(ctx, claims, cid, page)
+	endSpan(span, err)
+	return result, err
+}
+
+func (t tracedService) RemoveMember(ctx context.Context, claims auth.Claims, cid uint64, uid uint64) error {
+	ctx, span := startSpan(ctx, "RemoveMember")
+	err := t.next.RemoveMember(ctx, claims, cid, uid)
+	endSpan(span, err)
+	return err
+}
+
+func (t tracedService) ViewMyInvitations(ctx context.Context, claims auth.Claims) ([]models.InvitationResponse, error) {
+	ctx, span := startSpan(ctx, "ViewMyInvitations")
+	result, err := t.next.ViewMyInvitations(ctx, claims)
+	endSpan(span, err)
+	return result, err
+}
+
+func (t tracedService) AcceptInvitation(ctx context.Context, claims auth.Claims, iid uint64) (models.AcceptedInvitation, error) {
+	ctx, span := startSpan(ctx, "AcceptInvitation")
+	result, err := t.next.AcceptInvitation(ctx, claims, iid)
+	endSpan(span, err)
+	return result, err
+}
+
+func (t tracedService) ViewJob(ctx context.Context, claims auth.Claims, cid uint64, page models.PageQuery) (models.Page[models.JobResponse], error) {
+	ctx, span := startSpan(ctx, "ViewJob")
+	result, err := t.next.ViewJob(ctx, claims, cid, page)
+	endSpan(span, err)
+	return result, err
+}
+
+func (t tracedService) AddJobDetails(ctx context.Context, claims auth.Claims, jobData models.NewJob, cid uint64) (models.JobResponse, error) {
+	ctx, span := startSpan(ctx, "AddJobDetails")
+	result, err := t.next.AddJobDetails(ctx, claims, jobData, cid)
+	endSpan(span, err)
+	return result, err
+}
+
+func (t tracedService) ViewAllJobs(ctx context.Context, claims auth.Claims, filter models.JobFilter, page models.PageQuery) (models.Page[models.JobResponse], error) {
+	ctx, span := startSpan(ctx, "ViewAllJobs")
+	result, err := t.next.ViewAllJobs(ctx, claims, filter, page)
+	endSpan(span, err)
+	return result, err
+}
+
+func (t tracedService) ViewJobById(ctx context.Context, claims auth.Claims, jid uint64) (models.JobResponse, error) {
+	ctx, span := startSpan(ctx, "ViewJobById")
+	result, err := t.next.ViewJobById(ctx, claims, jid)
+	endSpan(span, err)
+	return result, err
+}
+
+func (t tracedService) UpdateJobDetails(ctx context.Context, claims auth.Claims, jid uint64, jobData models.NewJob) (models.JobResponse, error) {
+	ctx, span := startSpan(ctx, "UpdateJobDetails")
+	result, err := t.next.UpdateJobDetails(ctx, claims, jid, jobData)
+	endSpan(span, err)
+	return result, err
+}
+
+func (t tracedService) PatchJobDetails(ctx context.Context, claims auth.Claims, jid uint64, jobData models.UpdateJob) (models.JobResponse, error) {
+	ctx, span := startSpan(ctx, "PatchJobDetails")
+	result, err := t.next.PatchJobDetails(ctx, claims, jid, jobData)
+	endSpan(span, err)
+	return result, err
+}
+
+func (t tracedService) UpdateJobStatus(ctx context.Context, claims auth.Claims, jid uint64, status string) (models.JobResponse, error) {
+	ctx, span := startSpan(ctx, "UpdateJobStatus")
+	result, err := t.next.UpdateJobStatus(ctx, claims, jid, status)
+	endSpan(span, err)
+	return result, err
+}
+
+func (t tracedService) DeleteJob(ctx context.Context, claims auth.Claims, jid uint64) error {
+	ctx, span := startSpan(ctx, "DeleteJob")
+	err := t.next.DeleteJob(ctx, claims, jid)
+	endSpan(span, err)
+	return err
+}
+
+func (t tracedService) SearchJobs(ctx context.Context, claims auth.Claims, query string, page models.PageQuery) (models.Page[models.JobSearchResponse], error) {
+	ctx, span := startSpan(ctx, "SearchJobs")
+	result, err := t.next.SearchJobs(ctx, claims, query, page)
+	endSpan(span, err)
+	return result, err
+}
+
+func (t tracedService) ApplyForJob(ctx context.Context, uid uint64, jid uint64, applicationData models.NewApplication) (models.ApplicationResponse, error) {
+	ctx, span := startSpan(ctx, "ApplyForJob")
+	result, err := t.next.ApplyForJob(ctx, uid, jid, applicationData)
+	endSpan(span, err)
+	return result, err
+}
+
+func (t tracedService) WithdrawApplication(ctx context.Context, uid uint64, aid uint64) (models.ApplicationResponse, error) {
+	ctx, span := startSpan(ctx, "WithdrawApplication")
+	result, err := t.next.WithdrawApplication(ctx, uid, aid)
+	endSpan(span, err)
+	return result, err
+}
+
+func (t tracedService) ViewMyApplications(ctx context.Context, uid uint64, page models.PageQuery) (models.Page[models.ApplicationResponse], error) {
+	ctx, span := startSpan(ctx, "ViewMyApplications")
+	result, err := t.next.ViewMyApplications(ctx, uid, page)
+	endSpan(span, err)
+	return result, err
+}
+
+func (t tracedService) ViewJobApplicants(ctx context.Context, claims auth.Claims, jid uint64, page models.PageQuery) (models.Page[models.ApplicationResponse], error) {
+	ctx, span := startSpan(ctx, "ViewJobApplicants")
+	result, err := t.next.ViewJobApplicants(ctx, claims, jid, page)
+	endSpan(span, err)
+	return result, err
+}
+
+func (t tracedService) UpdateApplicationStatus(ctx context.Context, claims auth.Claims, aid uint64, status string) (models.ApplicationResponse, error) {
+	ctx, span := startSpan(ctx, "UpdateApplicationStatus")
+	result, err := t.next.UpdateApplicationStatus(ctx, claims, aid, status)
+	endSpan(span, err)
+	return result, err
+}
@@ -3,48 +3,158 @@ package service
 import (
 	"context"
 	"errors"
-	"strconv"
+	"strings"
 	"time"
 
+	"github.com/afthaab/job-portal/internal/apperr"
+	"github.com/afthaab/job-portal/internal/metrics"
 	"github.com/afthaab/job-portal/internal/models"
-	"github.com/afthaab/job-portal/internal/pkg"
-	"github.com/golang-jwt/jwt/v5"
+	"github.com/afthaab/job-portal/internal/password"
 	"github.com/rs/zerolog/log"
 )
 
-func (s *Service) UserSignIn(ctx context.Context, userData models.NewUser) (string, error) {
+// errInvalidCredentials is the answer to an unknown email and to a wrong password alike
+// so sign in can not be used to find out who has an account
+var errInvalidCredentials = apperr.Unauthorized("invalid credentials")
+
+// dummyHash is compared against when the email is unknown so both failures take as long as a real check,
+// it is made by the current algorithm so it costs the same as the hashes of the users
+func (s *Service) dummyHash() string {
+	s.dummyOnce.Do(func() {
+		// hashing a constant can only fail on a broken random source, an empty hash then fails every compare
+		s.dummy, _ = s.hasher.Hash("not the password of anyone")
+	})
+	return s.dummy
+}
+
+func (s *Service) UserSignIn(ctx context.Context, userData models.UserSignin) (models.TokenPair, error) {
+	account := lockoutKey(userData.Email)
+
+	// a locked account is refused before the password is looked at so guessing during the lock tells nothing
+	locked, err := s.lockout.LockedFor(ctx, account)
+	if err != nil {
+		log.Error().Err(err).Msg("error in reading the sign in lockout")
+	}
+	if locked > 0 {
+		metrics.SignIns.WithLabelValues(metrics.ResultFailure).Inc()
+		return models.TokenPair{}, apperr.TooManyRequests("too many failed sign ins, try again later", locked)
+	}
+
 	// checcking the email in the db
 	userDetails, err := s.UserRepo.CheckEmail(ctx, userData.Email)
+	if errors.Is(err, apperr.ErrNotFound) {
+		_, _ = s.hasher.Verify(userData.Password, s.dummyHash())
+		return models.TokenPair{}, s.signInFailed(ctx, account)
+	}
 	if err != nil {
-		return "", err
+		return models.TokenPair{}, err
 	}
 
 	// comaparing the password and hashed password
-	err = pkg.CheckHashedPassword(userData.Password, userDetails.PasswordHash)
+	rehash, err := s.hasher.Verify(userData.Password, userDetails.PasswordHash)
+	if err != nil {
+		if !errors.Is(err, password.ErrMismatch) {
+			log.Error().Err(err).Uint("uid", userDetails.ID).Msg("error in checking the password")
+		}
+		return models.TokenPair{}, s.signInFailed(ctx, account)
+	}
+
+	err = s.lockout.Reset(ctx, account)
+	if err != nil {
+		log.Error().Err(err).Msg("error in resetting the sign in lockout")
+	}
+
+	// only checked once the password matched so it does not tell anyone else the account exists
+	if s.verification.Required && !userDetails.EmailVerified() {
+		metrics.SignIns.WithLabelValues(metrics.ResultFailure).Inc()
+		return models.TokenPair{}, errEmailNotVerified
+	}
+
+	// the password is only known in plain text here, so this is when an old hash can be replaced
+	if rehash {
+		s.upgradeHash(ctx, userDetails, userData.Password)
+	}
+
+	// every sign in starts a new refresh token family
+	family, err := newOpaqueToken()
+	if err != nil {
+		return models.TokenPair{}, err
+	}
+	tokens, err := s.issueTokens(ctx, userDetails, family)
+	if err != nil {
+		return models.TokenPair{}, err
+	}
+	metrics.SignIns.WithLabelValues(metrics.ResultSuccess).Inc()
+	return tokens, nil
+
+}
+
+// upgradeHash stores a hash of the password made with the current algorithm and parameters,
+// a failure only means the upgrade is tried again on the next sign in
+func (s *Service) upgradeHash(ctx context.Context, userDetails models.User, plain string) {
+	hash, err := s.hasher.Hash(plain)
+	if err == nil {
+		err = s.UserRepo.UpdatePasswordHash(ctx, uint64(userDetails.ID), hash)
+	}
 	if err != nil {
-		log.Info().Err(err).Send()
-		return "", errors.New("entered password is not wrong")
+		log.Error().Err(err).Uint("uid", userDetails.ID).Msg("error in upgrading the password hash")
 	}
+}
+
+// lockoutKey is the normalized email the failed passwords of an account are counted under
+func lockoutKey(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
 
-	// setting up the claims
-	claims := jwt.RegisteredClaims{
-		Issuer:    "job portal project",
-		Subject:   strconv.FormatUint(uint64(userDetails.ID), 10),
-		Audience:  jwt.ClaimStrings{"users"},
-		ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
-		IssuedAt:  jwt.NewNumericDate(time.Now()),
+// signInFailed counts a failed password against the account, the lock it may start shows up on the next attempt
+func (s *Service) signInFailed(ctx context.Context, account string) error {
+	metrics.SignIns.WithLabelValues(metrics.ResultFailure).Inc()
+	lock, err := s.lockout.Fail(ctx, account)
+	if err != nil {
+		log.Error().Err(err).Msg("error in recording the failed sign in")
+	}
+	if lock > 0 {
+		log.Warn().Dur("locked for", lock).Msg("account locked after repeated failed sign ins")
 	}
-	token, err := s.auth.GenerateAuthToken(claims)
+	return errInvalidCredentials
+}
+
+func (s *Service) UserSignup(ctx context.Context, userData models.NewUser) (models.UserResponse, error) {
+	// anyone can sign up so every signup is a candidate, recruiters and admins are only made by create-admin
+	userDetails, err := s.createUser(ctx, userData, models.RoleCandidate, false)
 	if err != nil {
-		return "", err
+		return models.UserResponse{}, err
 	}
 
-	return token, nil
+	// the account exists either way, a mail that could not be sent can be asked for again
+	err = s.sendVerification(ctx, userDetails)
+	if err != nil {
+		log.Error().Err(err).Uint("uid", userDetails.ID).Msg("error in sending the verification mail")
+	}
+	return newUserResponse(userDetails), nil
+}
 
+// CreateUser creates a user with any role, it is not routed and only the create-admin command
+// uses it to hand out the roles signup refuses. The operator vouches for the email so it starts verified
+func (s *Service) CreateUser(ctx context.Context, userData models.NewUser, role string) (models.UserResponse, error) {
+	userDetails, err := s.createUser(ctx, userData, role, true)
+	if err != nil {
+		return models.UserResponse{}, err
+	}
+	return newUserResponse(userDetails), nil
 }
 
-func (s *Service) UserSignup(ctx context.Context, userData models.NewUser) (models.User, error) {
-	hashedPass, err := pkg.HashPassword(userData.Password)
+func (s *Service) createUser(ctx context.Context, userData models.NewUser, role string, verified bool) (models.User, error) {
+	switch role {
+	case models.RoleCandidate, models.RoleRecruiter, models.RoleAdmin:
+	default:
+		return models.User{}, apperr.Validation("unknown role " + role)
+	}
+	err := s.passwordPolicy.Check(userData.Password, userData.Username, userData.Email)
+	if err != nil {
+		return models.User{}, err
+	}
+	hashedPass, err := s.hasher.Hash(userData.Password)
 	if err != nil {
 		return models.User{}, err
 	}
@@ -52,11 +162,11 @@ func (s *Service) UserSignup(ctx context.Context, userData models.NewUser) (mode
 		Username:     userData.Username,
 		Email:        userData.Email,
 		PasswordHash: hashedPass,
+		Role:         role,
 	}
-
-	userDetails, err = s.UserRepo.CreateUser(ctx, userDetails)
-	if err != nil {
-		return models.User{}, err
+	if verified {
+		now := time.Now()
+		userDetails.EmailVerifiedAt = &now
 	}
-	return userDetails, nil
+	return s.UserRepo.CreateUser(ctx, userDetails)
 }
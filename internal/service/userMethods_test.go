@@ -4,11 +4,16 @@ import (
 	"context"
 	"errors"
 	"reflect"
+	"strings"
 	"testing"
 	"time"
 
-	auth "github.com/afthaab/job-portal/internal/auth/mockModels"
+	"github.com/afthaab/job-portal/internal/apperr"
+	"github.com/afthaab/job-portal/internal/auth"
+	mockauth "github.com/afthaab/job-portal/internal/auth/mockModels"
 	"github.com/afthaab/job-portal/internal/models"
+	"github.com/afthaab/job-portal/internal/password"
+	"github.com/afthaab/job-portal/internal/ratelimit"
 	"github.com/afthaab/job-portal/internal/repository"
 	"github.com/golang-jwt/jwt/v5"
 	"go.uber.org/mock/gomock"
@@ -16,16 +21,17 @@ import (
 )
 
 func TestService_UserSignIn(t *testing.T) {
+	verifiedAt := time.Now().Add(-time.Hour)
 	type args struct {
 		ctx      context.Context
-		userData models.NewUser
+		userData models.UserSignin
 	}
 	tests := []struct {
 		name             string
 		args             args
 		want             string
 		wantErr          bool
-		claims           jwt.RegisteredClaims
+		claims           auth.Claims
 		mockResponse     func() (models.User, error)
 		mockAuthResponse func() (string, error)
 	}{
@@ -34,8 +40,7 @@ func TestService_UserSignIn(t *testing.T) {
 			name: "wrong email",
 			args: args{
 				ctx: context.Background(),
-				userData: models.NewUser{
-					Username: "afthab",
+				userData: models.UserSignin{
 					Email:    "afthab606@gmail.com",
 					Password: "12345",
 				},
@@ -50,35 +55,62 @@ func TestService_UserSignIn(t *testing.T) {
 			},
 		},
 		{
-			name: "token generation failed",
+			name: "email not verified",
 			args: args{
 				ctx: context.Background(),
-				userData: models.NewUser{
-					Username: "afthab",
+				userData: models.UserSignin{
 					Email:    "afthab606@gmail.com",
 					Password: "12345678",
 				},
 			},
-			want:    "jwt test string",
-			wantErr: false,
+			want:    "",
+			wantErr: true,
 			mockResponse: func() (models.User, error) {
 				return models.User{
 					Username:     "afthab",
 					Email:        "afthab606@gmail.com",
 					PasswordHash: "$2a$10$uS/GmX48bxvhGPS.IrujaefuktoqGuKz3HBeOOMH6MGrnDT1H4TEy",
+					Role:         models.RoleCandidate,
+				}, nil
+			},
+			mockAuthResponse: func() (string, error) {
+				return "jwt test string", nil
+			},
+		},
+		{
+			name: "token generation failed",
+			args: args{
+				ctx: context.Background(),
+				userData: models.UserSignin{
+					Email:    "afthab606@gmail.com",
+					Password: "12345678",
+				},
+			},
+			want:    "jwt test string",
+			wantErr: false,
+			mockResponse: func() (models.User, error) {
+				return models.User{
+					Username:        "afthab",
+					Email:           "afthab606@gmail.com",
+					PasswordHash:    "$2a$10$uS/GmX48bxvhGPS.IrujaefuktoqGuKz3HBeOOMH6MGrnDT1H4TEy",
+					Role:            models.RoleCandidate,
+					EmailVerifiedAt: &verifiedAt,
 					Model: gorm.Model{
 						ID: 1,
 					},
 				}, nil
 			},
-			claims: jwt.RegisteredClaims{
-				Issuer:  "job portal project",
-				Subject: "1",
-				Audience: jwt.ClaimStrings{
-					"users",
+			claims: auth.Claims{
+				RegisteredClaims: jwt.RegisteredClaims{
+					Issuer:  "job portal project",
+					Subject: "1",
+					Audience: jwt.ClaimStrings{
+						"users",
+					},
+					ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+					IssuedAt:  jwt.NewNumericDate(time.Now()),
 				},
-				ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
-				IssuedAt:  jwt.NewNumericDate(time.Now()),
+				Role: models.RoleCandidate,
 			},
 			mockAuthResponse: func() (string, error) {
 				return "jwt test string", nil
@@ -88,8 +120,7 @@ func TestService_UserSignIn(t *testing.T) {
 			name: "success generate token",
 			args: args{
 				ctx: context.Background(),
-				userData: models.NewUser{
-					Username: "afthab",
+				userData: models.UserSignin{
 					Email:    "afthab606@gmail.com",
 					Password: "12345678",
 				},
@@ -98,22 +129,27 @@ func TestService_UserSignIn(t *testing.T) {
 			wantErr: true,
 			mockResponse: func() (models.User, error) {
 				return models.User{
-					Username:     "afthab",
-					Email:        "afthab606@gmail.com",
-					PasswordHash: "$2a$10$uS/GmX48bxvhGPS.IrujaefuktoqGuKz3HBeOOMH6MGrnDT1H4TEy",
+					Username:        "afthab",
+					Email:           "afthab606@gmail.com",
+					PasswordHash:    "$2a$10$uS/GmX48bxvhGPS.IrujaefuktoqGuKz3HBeOOMH6MGrnDT1H4TEy",
+					Role:            models.RoleCandidate,
+					EmailVerifiedAt: &verifiedAt,
 					Model: gorm.Model{
 						ID: 1,
 					},
 				}, nil
 			},
-			claims: jwt.RegisteredClaims{
-				Issuer:  "job portal project",
-				Subject: "1",
-				Audience: jwt.ClaimStrings{
-					"users",
+			claims: auth.Claims{
+				RegisteredClaims: jwt.RegisteredClaims{
+					Issuer:  "job portal project",
+					Subject: "1",
+					Audience: jwt.ClaimStrings{
+						"users",
+					},
+					ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+					IssuedAt:  jwt.NewNumericDate(time.Now()),
 				},
-				ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
-				IssuedAt:  jwt.NewNumericDate(time.Now()),
+				Role: models.RoleCandidate,
 			},
 			mockAuthResponse: func() (string, error) {
 				return "", errors.New("test error from mock function")
@@ -124,11 +160,22 @@ func TestService_UserSignIn(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			mc := gomock.NewController(t)
 			mockRepo := repository.NewMockUserRepo(mc)
-			mockAuth := auth.NewMockAuthentication(mc)
+			mockAuth := mockauth.NewMockAuthentication(mc)
 
 			mockRepo.EXPECT().CheckEmail(tt.args.ctx, tt.args.userData.Email).Return(tt.mockResponse()).AnyTimes()
+			mockRepo.EXPECT().CreateRefreshToken(tt.args.ctx, gomock.Any()).Return(models.RefreshToken{}, nil).AnyTimes()
+			// the stored bcrypt hash is replaced by an argon2id one on a successful sign in
+			mockRepo.EXPECT().UpdatePasswordHash(tt.args.ctx, uint64(1), gomock.Any()).Return(nil).AnyTimes()
 
-			mockAuth.EXPECT().GenerateAuthToken(tt.claims).Return(tt.mockAuthResponse()).AnyTimes()
+			// the timestamps and the jti depend on when the token is signed so they are left out of the comparison
+			mockAuth.EXPECT().GenerateAuthToken(gomock.Cond(func(x any) bool {
+				got, ok := x.(auth.Claims)
+				if !ok {
+					return false
+				}
+				got.ExpiresAt, got.IssuedAt, got.ID = tt.claims.ExpiresAt, tt.claims.IssuedAt, tt.claims.ID
+				return reflect.DeepEqual(got, tt.claims)
+			})).Return(tt.mockAuthResponse()).AnyTimes()
 
 			svc, err := NewService(mockRepo, mockAuth)
 			if err != nil {
@@ -141,8 +188,104 @@ func TestService_UserSignIn(t *testing.T) {
 				return
 			}
 
-			if got != tt.want {
-				t.Errorf("Service.UserSignIn() = %v, want %v", got, tt.want)
+			if got.AccessToken != tt.want {
+				t.Errorf("Service.UserSignIn() = %v, want %v", got.AccessToken, tt.want)
+			}
+			if !tt.wantErr && got.RefreshToken == "" {
+				t.Errorf("Service.UserSignIn() did not return a refresh token")
+			}
+		})
+	}
+}
+
+func TestService_UserSignIn_lockout(t *testing.T) {
+	ctx := context.Background()
+	mc := gomock.NewController(t)
+	mockRepo := repository.NewMockUserRepo(mc)
+	mockRepo.EXPECT().CheckEmail(ctx, "nobody@example.com").Return(models.User{}, apperr.NotFound("user not found")).Times(2)
+	mockRepo.EXPECT().CheckEmail(ctx, "Afthab606@gmail.com").Return(models.User{
+		Email:        "afthab606@gmail.com",
+		PasswordHash: "$2a$10$uS/GmX48bxvhGPS.IrujaefuktoqGuKz3HBeOOMH6MGrnDT1H4TEy",
+	}, nil).Times(2)
+
+	lockout := ratelimit.NewMemoryLockout(ratelimit.LockoutPolicy{Threshold: 2, Base: time.Minute, Max: time.Hour})
+	svc, err := NewService(mockRepo, nil, WithLockout(lockout))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// an unknown email and a wrong password get the same answer
+	_, unknown := svc.UserSignIn(ctx, models.UserSignin{Email: "nobody@example.com", Password: "12345678"})
+	_, wrong := svc.UserSignIn(ctx, models.UserSignin{Email: "Afthab606@gmail.com", Password: "wrong password"})
+	if unknown == nil || wrong == nil || unknown.Error() != wrong.Error() || apperr.KindOf(wrong) != apperr.KindUnauthorized {
+		t.Errorf("Service.UserSignIn() errors = %v and %v, want the same unauthorized error", unknown, wrong)
+	}
+
+	// the second failure locks the account whatever case the email is typed in, even for the right password
+	_, err = svc.UserSignIn(ctx, models.UserSignin{Email: "Afthab606@gmail.com", Password: "wrong password"})
+	if apperr.KindOf(err) != apperr.KindUnauthorized {
+		t.Errorf("Service.UserSignIn() error = %v, want unauthorized", err)
+	}
+	_, err = svc.UserSignIn(ctx, models.UserSignin{Email: " afthab606@GMAIL.com", Password: "12345678"})
+	if apperr.KindOf(err) != apperr.KindTooManyRequests || apperr.RetryAfterOf(err) <= 0 {
+		t.Errorf("Service.UserSignIn() error = %v, want too many requests with a retry after", err)
+	}
+
+	// unknown emails lock the same way
+	_, err = svc.UserSignIn(ctx, models.UserSignin{Email: "nobody@example.com", Password: "12345678"})
+	if apperr.KindOf(err) != apperr.KindUnauthorized {
+		t.Errorf("Service.UserSignIn() error = %v, want unauthorized", err)
+	}
+	_, err = svc.UserSignIn(ctx, models.UserSignin{Email: "nobody@example.com", Password: "12345678"})
+	if apperr.KindOf(err) != apperr.KindTooManyRequests {
+		t.Errorf("Service.UserSignIn() error = %v, want too many requests", err)
+	}
+}
+
+func TestService_UserSignIn_rehash(t *testing.T) {
+	ctx := context.Background()
+	verifiedAt := time.Now()
+	current := password.Argon2id{Time: 1, Memory: 1024, Threads: 1}
+	upToDate, err := current.Hash("12345678")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for name, tt := range map[string]struct {
+		hash       string
+		wantRehash bool
+	}{
+		"old bcrypt hash upgraded":   {hash: "$2a$10$uS/GmX48bxvhGPS.IrujaefuktoqGuKz3HBeOOMH6MGrnDT1H4TEy", wantRehash: true},
+		"current hash left in place": {hash: upToDate},
+	} {
+		t.Run(name, func(t *testing.T) {
+			mc := gomock.NewController(t)
+			mockRepo := repository.NewMockUserRepo(mc)
+			mockAuth := mockauth.NewMockAuthentication(mc)
+			mockRepo.EXPECT().CheckEmail(ctx, "afthab606@gmail.com").Return(models.User{
+				Model:           gorm.Model{ID: 1},
+				Email:           "afthab606@gmail.com",
+				PasswordHash:    tt.hash,
+				EmailVerifiedAt: &verifiedAt,
+			}, nil)
+			mockRepo.EXPECT().CreateRefreshToken(ctx, gomock.Any()).Return(models.RefreshToken{}, nil)
+			mockAuth.EXPECT().GenerateAuthToken(gomock.Any()).Return("jwt test string", nil)
+			if tt.wantRehash {
+				mockRepo.EXPECT().UpdatePasswordHash(ctx, uint64(1), gomock.Any()).DoAndReturn(func(ctx context.Context, uid uint64, hash string) error {
+					if !current.Owns(hash) || current.Outdated(hash) || current.Verify("12345678", hash) != nil {
+						t.Errorf("UpdatePasswordHash() got %q, want a current argon2id hash of the password", hash)
+					}
+					return nil
+				})
+			}
+
+			svc, err := NewService(mockRepo, mockAuth, WithPasswords(password.DefaultPolicy, password.NewHasher(current)))
+			if err != nil {
+				t.Fatal(err)
+			}
+			_, err = svc.UserSignIn(ctx, models.UserSignin{Email: "afthab606@gmail.com", Password: "12345678"})
+			if err != nil {
+				t.Errorf("Service.UserSignIn() error = %v", err)
 			}
 		})
 	}
@@ -157,7 +300,7 @@ func TestService_UserSignup(t *testing.T) {
 	tests := []struct {
 		name         string
 		args         args
-		want         models.User
+		want         models.UserResponse
 		wantErr      bool
 		mockResponse func() (models.User, error)
 	}{
@@ -168,15 +311,31 @@ func TestService_UserSignup(t *testing.T) {
 				userData: models.NewUser{
 					Username: "afthab",
 					Email:    "afthab606@gmail.com",
-					Password: "12345678",
+					Password: "purple tractor rain",
 				},
 			},
-			want:    models.User{}, // Change the expected result to an empty User since an error is expected.
-			wantErr: true,          // Set wantErr to true since an error is expected.
+			want:    models.UserResponse{}, // Change the expected result to an empty User since an error is expected.
+			wantErr: true,                  // Set wantErr to true since an error is expected.
 			mockResponse: func() (models.User, error) {
 				return models.User{}, errors.New("error while hashing the password")
 			},
 		},
+		{
+			name: "password breaks the policy",
+			args: args{
+				ctx: context.Background(),
+				userData: models.NewUser{
+					Username: "afthab",
+					Email:    "afthab606@gmail.com",
+					Password: "password1",
+				},
+			},
+			want:    models.UserResponse{},
+			wantErr: true,
+			mockResponse: func() (models.User, error) {
+				return models.User{}, nil
+			},
+		},
 		{
 			name: "success from the database",
 			args: args{
@@ -184,13 +343,12 @@ func TestService_UserSignup(t *testing.T) {
 				userData: models.NewUser{
 					Username: "afthab",
 					Email:    "afthab606@gmail.com",
-					Password: "12345678",
+					Password: "purple tractor rain",
 				},
 			},
-			want: models.User{
-				Username:     "afthab",
-				Email:        "afthab606@gmail.com",
-				PasswordHash: "hashed password",
+			want: models.UserResponse{
+				Username: "afthab",
+				Email:    "afthab606@gmail.com",
 			}, // Change the expected result to an empty User since an error is expected.
 			wantErr: false, // Set wantErr to true since an error is expected.
 			mockResponse: func() (models.User, error) {
@@ -206,9 +364,16 @@ func TestService_UserSignup(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			mc := gomock.NewController(t)
 			mockRespo := repository.NewMockUserRepo(mc)
-			mockRespo.EXPECT().CreateUser(tt.args.ctx, gomock.Any()).Return(tt.mockResponse()).AnyTimes()
+			mockRespo.EXPECT().CreateUser(tt.args.ctx, gomock.Any()).DoAndReturn(func(ctx context.Context, u models.User) (models.User, error) {
+				// signup is open to anyone so it never hands out the recruiter role
+				if u.Role != models.RoleCandidate {
+					t.Errorf("UserSignup() created a user with role %q, want %q", u.Role, models.RoleCandidate)
+				}
+				return tt.mockResponse()
+			}).AnyTimes()
+			mockRespo.EXPECT().CreateUserToken(tt.args.ctx, gomock.Any()).Return(models.UserToken{}, nil).AnyTimes()
 
-			svc, err := NewService(mockRespo, &auth.MockAuthentication{})
+			svc, err := NewService(mockRespo, &mockauth.MockAuthentication{})
 			if err != nil {
 				t.Errorf("error in initializing the repo layer")
 				return
@@ -225,3 +390,90 @@ func TestService_UserSignup(t *testing.T) {
 		})
 	}
 }
+
+func TestService_CreateUser(t *testing.T) {
+	userData := models.NewUser{
+		Username: "root",
+		Email:    "root@jobportal.com",
+		Password: "purple tractor rain",
+	}
+	tests := []struct {
+		name      string
+		role      string
+		want      models.UserResponse
+		wantKind  apperr.Kind
+		wantErr   bool
+		setupMock func(m *repository.MockUserRepo)
+	}{
+		{
+			name:     "unknown role",
+			role:     "superuser",
+			wantErr:  true,
+			wantKind: apperr.KindValidation,
+		},
+		{
+			name: "admin created",
+			role: models.RoleAdmin,
+			want: models.UserResponse{
+				Username:      "root",
+				Email:         "root@jobportal.com",
+				Role:          models.RoleAdmin,
+				EmailVerified: true,
+			},
+			setupMock: func(m *repository.MockUserRepo) {
+				m.EXPECT().CreateUser(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, u models.User) (models.User, error) {
+					if u.PasswordHash == userData.Password {
+						t.Errorf("password was stored without hashing")
+					}
+					return u, nil
+				})
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mc := gomock.NewController(t)
+			mockRespo := repository.NewMockUserRepo(mc)
+			if tt.setupMock != nil {
+				tt.setupMock(mockRespo)
+			}
+
+			svc, err := NewService(mockRespo, &mockauth.MockAuthentication{})
+			if err != nil {
+				t.Errorf("error in initializing the repo layer")
+				return
+			}
+
+			got, err := svc.CreateUser(context.Background(), userData, tt.role)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Service.CreateUser() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if tt.wantErr && apperr.KindOf(err) != tt.wantKind {
+				t.Errorf("Service.CreateUser() kind = %v, want %v", apperr.KindOf(err), tt.wantKind)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Service.CreateUser() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestService_UserSignup_bcryptLimit(t *testing.T) {
+	mc := gomock.NewController(t)
+	mockRepo := repository.NewMockUserRepo(mc)
+	svc, err := NewService(mockRepo, nil, WithPasswords(password.DefaultPolicy, password.NewHasher(password.Bcrypt{Cost: 4})))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// within the length in characters of the policy but past the 72 bytes bcrypt hashes
+	_, err = svc.UserSignup(context.Background(), models.NewUser{
+		Username: "afthab",
+		Email:    "afthab606@gmail.com",
+		Password: strings.Repeat("purple tractor rain ", 5),
+	})
+	if apperr.KindOf(err) != apperr.KindValidation {
+		t.Errorf("Service.UserSignup() error = %v, want a validation error", err)
+	}
+}
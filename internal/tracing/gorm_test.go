@@ -0,0 +1,58 @@
+package tracing
+
+import (
+	"context"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+type widget struct {
+	ID   uint
+	Name string
+}
+
+func TestGormPlugin(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+
+	// a dry run builds the statements and runs the callbacks without a database
+	db, err := gorm.Open(postgres.New(postgres.Config{DSN: "host=localhost"}), &gorm.Config{DryRun: true, DisableAutomaticPing: true, SkipDefaultTransaction: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = db.Use(GormPlugin{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, parent := Tracer().Start(context.Background(), "UserService.ViewJobById")
+	db.WithContext(ctx).Where("name = ?", "secret").Find(&[]widget{})
+	parent.End()
+
+	spans := recorder.Ended()
+	if len(spans) != 2 {
+		t.Fatalf("GormPlugin recorded %d spans, want the query and its parent", len(spans))
+	}
+	query := spans[0]
+	if query.Name() != "db.query widgets" {
+		t.Errorf("GormPlugin span name = %v, want db.query widgets", query.Name())
+	}
+	if query.Parent().SpanID() != parent.SpanContext().SpanID() {
+		t.Errorf("GormPlugin span is not a child of the service span")
+	}
+	statement := ""
+	for _, attr := range query.Attributes() {
+		if attr.Key == "db.statement" {
+			statement = attr.Value.AsString()
+		}
+	}
+	// the values are left out, they can be passwords and emails
+	if statement != `SELECT * FROM "widgets" WHERE name = $1` {
+		t.Errorf("GormPlugin statement = %v", statement)
+	}
+}